@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"pagrin/internal/config"
 	"pagrin/internal/ethereum"
 	"pagrin/internal/handler"
+	"pagrin/internal/metrics"
 	"pagrin/internal/repository"
 	"pagrin/internal/service"
 	"pagrin/internal/stream"
@@ -36,31 +38,11 @@ func main() {
 		cfg.Logging.Format,
 	)
 
-	// Initialize Ethereum client with provider pool (if configured) or single provider
-	var ethereumClient *ethereum.Client
-	if cfg.Ethereum.RPCConfig != "" {
-		// Load providers from YAML config (preferred - supports failover)
-		providers, err := config.LoadProvidersFromYAML(cfg.Ethereum.RPCConfig, cfg.Ethereum.RPCURL)
-		if err != nil {
-			log.Error("Failed to load providers from config: %v", err)
-			os.Exit(1)
-		}
-
-		pool := ethereum.NewProviderPool(providers)
-		ethereumClient = ethereum.NewClientFromPool(pool)
-		log.Info("Initialized provider pool with %d providers", len(providers))
-	} else {
-		// Fallback to single provider (legacy mode)
-		ethereumClient, err = ethereum.NewClient(cfg.Ethereum.RPCURL)
-		if err != nil {
-			log.Error("Failed to create Ethereum client: %v", err)
-			os.Exit(1)
-		}
-		log.Info("Using single RPC provider (legacy mode)")
+	chainConfigs, err := config.LoadChains(cfg)
+	if err != nil {
+		log.Error("Failed to load chain config: %v", err)
+		os.Exit(1)
 	}
-	defer ethereumClient.Close()
-
-	fetcher := ethereum.NewFetcher(ethereumClient)
 
 	// Initialize Redis cache (optional, gracefully degrades if unavailable)
 	var redisCache cache.Cache
@@ -78,7 +60,7 @@ func main() {
 		}
 	}
 
-	repo, err := repository.NewMongoRepository(cfg.MongoDB.URI, cfg.MongoDB.Database, redisCache)
+	repo, err := repository.NewMongoRepository(cfg.MongoDB.URI, cfg.MongoDB.Database)
 	if err != nil {
 		log.Error("Failed to create repository: %v", err)
 		os.Exit(1)
@@ -90,8 +72,12 @@ func main() {
 			log.Error("Failed to close repository: %v", err)
 		}
 	}()
-
-	transferService := service.NewTransferService(repo, log)
+	if len(cfg.Admin.APIKeys) == 0 {
+		log.Warn("ADMIN_API_KEYS not set - admin endpoints, including backfills and dead letters, are disabled and return 403")
+	}
+	if !repo.SupportsTransactions() {
+		log.Warn("MongoDB is standalone - batches and checkpoints are committed without transactions")
+	}
 
 	// Initialize streaming if enabled
 	// Keep concrete type for handler, use interface for service
@@ -104,24 +90,54 @@ func main() {
 	}
 	// When disabled, streamPublisher is nil interface, so nil check works correctly
 
-	ingestionService := service.NewIngestionService(
-		ethereumClient,
-		fetcher,
-		repo,
-		log,
-		cfg.Ingestion.PollInterval,
-		cfg.Ingestion.StartBlock,
-		cfg.Ingestion.BlockBatchSize,
-		cfg.Ingestion.ResetStartBlock,
-		cfg.Ingestion.AdaptiveBatch,
-		cfg.Ingestion.BatchMinSize,
-		cfg.Ingestion.BatchMaxSize,
-		cfg.Ingestion.BatchSuccessStreak,
-		cfg.Ingestion.BatchFailureBackoff,
-		streamPublisher,
-	)
+	// Every chain gets its own client, checkpoint and services; all share MongoDB, Redis and the stream
+	chainList := make([]*service.Chain, 0, len(chainConfigs))
+	for _, chainCfg := range chainConfigs {
+		ethereumClient, err := newEthereumClient(chainCfg, cfg.Ethereum.RPCURL)
+		if err != nil {
+			log.Error("Failed to create Ethereum client for chain %s: %v", chainCfg.Name, err)
+			os.Exit(1)
+		}
+		defer ethereumClient.Close()
+
+		chain, err := newChain(chainCfg, ethereumClient, repo, redisCache, streamPublisher, cfg, log.WithPrefix(chainCfg.Name))
+		if err != nil {
+			log.Error("Failed to initialize chain %s: %v", chainCfg.Name, err)
+			os.Exit(1)
+		}
+		chainList = append(chainList, chain)
+	}
+
+	chains, err := service.NewChains(chainList)
+	if err != nil {
+		log.Error("Failed to register chains: %v", err)
+		os.Exit(1)
+	}
+
+	var leaseStore service.LeaseStore
+	switch cfg.Leader.Election {
+	case "mongo":
+		leaseStore = repo
+	case "redis":
+		if redisCache == nil {
+			log.Error("LEADER_ELECTION=redis but Redis is unavailable")
+			os.Exit(1)
+		}
+		leaseStore = redisCache
+	}
+	elector := service.NewLeaderElector(leaseStore, cfg.Leader.Election, cfg.Leader.Instance, cfg.Leader.LeaseTTL, log)
+
+	transferService := service.NewTransferService(repo, chains, log)
 
 	transferHandler := handler.NewTransferHandler(transferService)
+	tokenHandler := handler.NewTokenHandler(chains)
+	nftHandler := handler.NewNFTHandler(chains)
+	approvalHandler := handler.NewApprovalHandler(chains)
+	backfillHandler := handler.NewBackfillHandler(chains)
+	deadLetterHandler := handler.NewDeadLetterHandler(chains)
+	controlHandler := handler.NewControlHandler(chains)
+	coverageHandler := handler.NewCoverageHandler(chains)
+	statusHandler := handler.NewStatusHandler(chains, streamInstance, elector)
 
 	// Create stream handler if streaming is enabled
 	var streamHandler *handler.StreamHandler
@@ -129,7 +145,7 @@ func main() {
 		streamHandler = handler.NewStreamHandler(streamInstance)
 	}
 
-	router := setupRouter(transferHandler, streamHandler, cfg)
+	router := setupRouter(transferHandler, tokenHandler, nftHandler, approvalHandler, backfillHandler, deadLetterHandler, controlHandler, coverageHandler, statusHandler, streamHandler, elector, cfg)
 
 	server := &http.Server{
 		Addr:    ":" + cfg.Server.Port,
@@ -139,9 +155,21 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Data indexed before multi-chain support belongs to the first configured chain
+	migrated, err := repo.ForChain(chains.Default().ID, nil).AssignLegacyChainID(ctx)
+	if err != nil {
+		log.Error("Failed to assign existing data to chain %d: %v", chains.Default().ID, err)
+		os.Exit(1)
+	}
+	if migrated > 0 {
+		log.Info("Assigned %d existing documents to chain %d", migrated, chains.Default().ID)
+	}
+
+	// With leader election only the lease holder prepares and runs the chains; every replica serves the API
 	go func() {
-		if err := ingestionService.Start(ctx); err != nil {
-			log.Error("Ingestion service error: %v", err)
+		if err := elector.Run(ctx, func(ctx context.Context) error { return runChains(ctx, chains, log) }); err != nil {
+			log.Error("%v", err)
+			os.Exit(1)
 		}
 	}()
 
@@ -170,13 +198,36 @@ func main() {
 	log.Info("Server exited")
 }
 
-func setupRouter(transferHandler *handler.TransferHandler, streamHandler *handler.StreamHandler, cfg *config.Config) *gin.Engine {
+func setupRouter(transferHandler *handler.TransferHandler, tokenHandler *handler.TokenHandler, nftHandler *handler.NFTHandler, approvalHandler *handler.ApprovalHandler, backfillHandler *handler.BackfillHandler, deadLetterHandler *handler.DeadLetterHandler, controlHandler *handler.ControlHandler, coverageHandler *handler.CoverageHandler, statusHandler *handler.StatusHandler, streamHandler *handler.StreamHandler, elector *service.LeaderElector, cfg *config.Config) *gin.Engine {
 	router := gin.Default()
 
 	api := router.Group("/api/v1")
 	{
 		api.GET("/transfers", transferHandler.GetTransfers)
 		api.GET("/aggregates", transferHandler.GetAggregates)
+		api.GET("/coverage", coverageHandler.GetCoverage)
+		api.GET("/status", statusHandler.GetStatus)
+		api.GET("/tokens/:address", tokenHandler.GetMetadata)
+		api.GET("/tokens/:address/supply", tokenHandler.GetSupply)
+		api.GET("/nfts/:token/:token_id", nftHandler.GetOwner)
+		api.GET("/accounts/:address/nfts", nftHandler.ListByOwner)
+		api.GET("/accounts/:address/approvals", approvalHandler.ListOpenApprovals)
+		api.GET("/approvals/flagged", approvalHandler.ListFlaggedApprovals)
+	}
+
+	admin := api.Group("/admin", handler.AdminAuth(cfg.Admin.APIKeys))
+	{
+		admin.POST("/backfills", backfillHandler.CreateBackfill)
+		admin.GET("/backfills", backfillHandler.ListBackfills)
+		admin.GET("/backfills/:id", backfillHandler.GetBackfill)
+		admin.GET("/dead-letters", deadLetterHandler.ListDeadLetters)
+		admin.POST("/dead-letters/reprocess", deadLetterHandler.ReprocessDeadLetters)
+		admin.GET("/ingestion", controlHandler.GetIngestion)
+		admin.POST("/ingestion/pause", controlHandler.PauseIngestion)
+		admin.POST("/ingestion/resume", controlHandler.ResumeIngestion)
+		admin.POST("/ingestion/rewind", controlHandler.RewindIngestion)
+		admin.POST("/ingestion/batch-size", controlHandler.SetBatchSize)
+		admin.GET("/audit", controlHandler.ListAudit)
 	}
 
 	// Streaming endpoints (if enabled)
@@ -190,8 +241,244 @@ func setupRouter(transferHandler *handler.TransferHandler, streamHandler *handle
 
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
+		c.JSON(http.StatusOK, gin.H{"status": "healthy", "leader": elector.Status()})
 	})
 
 	return router
 }
+
+// newEthereumClient creates a chain's client from its provider pool, or from rpcURL in legacy mode
+func newEthereumClient(chainCfg *config.Chain, rpcURL string) (*ethereum.Client, error) {
+	if len(chainCfg.Providers) == 0 {
+		return ethereum.NewClient(rpcURL)
+	}
+	return ethereum.NewClientFromPool(ethereum.NewProviderPool(chainCfg.Providers)), nil
+}
+
+// newChain detects the chain ID and builds the chain's services on its scoped repository and cache
+func newChain(
+	chainCfg *config.Chain,
+	ethereumClient *ethereum.Client,
+	repo *repository.MongoRepository,
+	redisCache cache.Cache,
+	streamPublisher service.StreamPublisher,
+	cfg *config.Config,
+	log *logger.Logger,
+) (*service.Chain, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	chainID, err := ethereumClient.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if chainCfg.ChainID != 0 && chainCfg.ChainID != chainID {
+		return nil, fmt.Errorf("configured chain_id %d but providers report %d", chainCfg.ChainID, chainID)
+	}
+	if pool := ethereumClient.GetPool(); pool != nil {
+		pool.SetChainID(chainID)
+		log.Info("Initialized provider pool with %d providers for chain %d", len(chainCfg.Providers), chainID)
+	} else {
+		log.Info("Using single RPC provider (legacy mode) for chain %d", chainID)
+	}
+
+	// Scope the cache before handing it out so keys never collide across chains
+	var chainCache cache.Cache
+	var blockCache repository.BlockCache
+	var metadataCache service.TokenMetadataCache
+	var headerStore ethereum.HeaderStore
+	if redisCache != nil {
+		chainCache = redisCache.ForChain(chainID)
+		blockCache = chainCache
+		metadataCache = chainCache
+		headerStore = chainCache
+	}
+	chainRepo := repo.ForChain(chainID, blockCache)
+
+	allowTokens, denyTokens, err := config.LoadTokenRulesFromYAML(chainCfg.TokenConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token filter config: %w", err)
+	}
+	var tokenFilter *ethereum.TokenFilter
+	if len(allowTokens) > 0 || len(denyTokens) > 0 {
+		tokenFilter = ethereum.NewTokenFilter(allowTokens, denyTokens)
+		log.Info("Token filter enabled: %d allowed, %d denied", len(allowTokens), len(denyTokens))
+	}
+
+	ingestion := chainCfg.Ingestion
+	aimd := ethereum.AIMDConfig{
+		TargetDuration: ingestion.BatchTargetDuration,
+		TargetLogs:     ingestion.BatchTargetLogs,
+		Increase:       ingestion.BatchIncrease,
+		Decrease:       ingestion.BatchDecrease,
+	}
+	if pool := ethereumClient.GetPool(); pool != nil && ingestion.AdaptiveBatch && ingestion.BatchStrategy == service.BatchStrategyAIMD {
+		pool.SetAIMD(aimd)
+		log.Info("AIMD batch sizing enabled: target %s and %d logs per batch and request", aimd.TargetDuration, aimd.TargetLogs)
+	}
+	headerCache := ethereum.NewBlockHeaderCache(ingestion.HeaderCacheSize, ingestion.HeaderCacheTTL, headerStore, metrics.ChainLabel(chainID))
+	fetcher := ethereum.NewFetcher(ethereumClient, headerCache, tokenFilter, ingestion.NativeTransfers, ingestion.InternalTransfers)
+	if ingestion.NativeTransfers {
+		log.Info("Native ETH transfer indexing enabled")
+	}
+	if ingestion.InternalTransfers {
+		log.Info("Internal ETH transfer indexing enabled (requires trace API support)")
+	}
+
+	tokenMetadataService := service.NewTokenMetadataService(ethereumClient, chainRepo, metadataCache, log, ingestion.MetadataCacheSize)
+	nftService := service.NewNFTService(chainRepo, log)
+	supplyService := service.NewSupplyService(chainRepo, tokenMetadataService, log)
+	eventStore := service.NewEventStore(chainRepo, chainRepo, chainRepo, chainRepo, chainRepo, tokenMetadataService, log, cfg.Approvals.RiskySpenders)
+	approvalService := service.NewApprovalService(chainRepo, eventStore, tokenMetadataService, log)
+
+	ingestionService := service.NewIngestionService(
+		ethereumClient,
+		fetcher,
+		chainRepo,
+		chainRepo,
+		eventStore,
+		log,
+		ingestion.PollInterval,
+		ingestion.StartBlock,
+		ingestion.BlockBatchSize,
+		ingestion.ResetStartBlock,
+		ingestion.AdaptiveBatch,
+		ingestion.BatchMinSize,
+		ingestion.BatchMaxSize,
+		ingestion.BatchSuccessStreak,
+		ingestion.BatchFailureBackoff,
+		ingestion.BatchStrategy,
+		aimd,
+		ingestion.ReorgMaxDepth,
+		ingestion.ConfirmationDepth,
+		ethereum.BlockTag(ingestion.IndexingMode),
+		ingestion.PipelineDepth,
+		streamPublisher,
+	)
+
+	backfillService := service.NewBackfillService(
+		fetcher,
+		chainRepo,
+		eventStore,
+		chainRepo,
+		log,
+		cfg.Backfill.Workers,
+		cfg.Backfill.MaxWorkers,
+		cfg.Backfill.ChunkSize,
+		cfg.Backfill.MaxChunkSize,
+		cfg.Backfill.BatchSize,
+		cfg.Backfill.MaxAttempts,
+		ingestionService.StatusFor,
+	)
+
+	deadLetterService := service.NewDeadLetterService(
+		chainRepo,
+		fetcher,
+		eventStore,
+		log,
+		chainID,
+		ingestionService.StatusFor,
+	)
+
+	controlService := service.NewControlService(chainRepo, chainRepo, ingestionService, log)
+
+	coverageService := service.NewCoverageService(
+		chainRepo,
+		chainRepo,
+		backfillService,
+		log,
+		ingestion.StartBlock,
+		ingestion.CoverageRepair,
+		ingestion.BatchMaxSize,
+	)
+
+	tokenFilterService := service.NewTokenFilterService(
+		chainRepo,
+		chainRepo,
+		backfillService,
+		log,
+		allowTokens,
+		denyTokens,
+	)
+
+	return &service.Chain{
+		ID:          chainID,
+		Name:        chainCfg.Name,
+		Ingestion:   ingestionService,
+		Backfill:    backfillService,
+		Coverage:    coverageService,
+		TokenFilter: tokenFilterService,
+		Metadata:    tokenMetadataService,
+		Supply:      supplyService,
+		NFT:         nftService,
+		Approval:    approvalService,
+		DeadLetters: deadLetterService,
+		Control:     controlService,
+	}, nil
+}
+
+// prepareChain brings a chain's stored data up to date; must run before its pipelines start
+func prepareChain(ctx context.Context, chain *service.Chain) error {
+	if err := chain.Coverage.SeedLegacyCoverage(ctx); err != nil {
+		return fmt.Errorf("failed to seed coverage: %w", err)
+	}
+
+	if err := chain.TokenFilter.Sync(ctx); err != nil {
+		return fmt.Errorf("failed to sync token filter: %w", err)
+	}
+
+	if err := chain.Supply.ClassifyLegacyTransfers(ctx); err != nil {
+		return fmt.Errorf("failed to classify existing transfers: %w", err)
+	}
+
+	return nil
+}
+
+// runChains prepares every chain, then runs their pipelines until ctx is cancelled and they stop
+func runChains(ctx context.Context, chains *service.Chains, log *logger.Logger) error {
+	for _, chain := range chains.All() {
+		if err := prepareChain(ctx, chain); err != nil {
+			return fmt.Errorf("failed to prepare chain %s: %w", chain.Name, err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, chain := range chains.All() {
+		wg.Add(1)
+		go func(chain *service.Chain) {
+			defer wg.Done()
+			runChain(ctx, chain, log.WithPrefix(chain.Name))
+		}(chain)
+	}
+	wg.Wait()
+	return nil
+}
+
+// runChain runs a chain's ingestion, backfill and coverage repair until ctx is cancelled and they stop
+func runChain(ctx context.Context, chain *service.Chain, log *logger.Logger) {
+	var wg sync.WaitGroup
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		if err := chain.Ingestion.Start(ctx); err != nil {
+			log.Error("Ingestion service error: %v", err)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		if err := chain.Backfill.Start(ctx); err != nil {
+			log.Error("Backfill service error: %v", err)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		if err := chain.Coverage.Start(ctx); err != nil {
+			log.Error("Coverage service error: %v", err)
+		}
+	}()
+
+	wg.Wait()
+}
@@ -2,10 +2,12 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"time"
 
+	"pagrin/internal/models"
 	"pagrin/pkg/logger"
 
 	"github.com/redis/go-redis/v9"
@@ -18,6 +20,14 @@ type Cache interface {
 	SetLastProcessedBlock(ctx context.Context, blockNumber uint64) error
 	IsTxProcessed(ctx context.Context, txHash string) (bool, error)
 	MarkTxProcessed(ctx context.Context, txHash string) error
+	GetTokenMetadata(ctx context.Context, token string) (*models.TokenMetadata, error)
+	SetTokenMetadata(ctx context.Context, metadata *models.TokenMetadata) error
+	GetBlockHeaders(ctx context.Context, numbers []uint64) (map[uint64]*models.BlockHeader, error)
+	SetBlockHeaders(ctx context.Context, headers []*models.BlockHeader, ttl time.Duration) error
+	DeleteBlockHeaders(ctx context.Context, fromBlock, toBlock uint64) error
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+	ForChain(chainID uint64) Cache
 	Close() error
 }
 
@@ -27,6 +37,7 @@ type RedisCache struct {
 	client  *redis.Client
 	logger  *logger.Logger
 	enabled bool
+	prefix  string // Key namespace, "ethereum:<chain_id>:" for chain-scoped caches
 }
 
 // NewRedisCache creates a new Redis cache instance
@@ -59,30 +70,48 @@ func NewRedisCache(uri string, enabled bool, log *logger.Logger) (*RedisCache, e
 		client:  client,
 		logger:  log,
 		enabled: true,
+		prefix:  keyPrefix,
 	}, nil
 }
 
+// ForChain returns a cache sharing this connection whose keys are namespaced by chain
+func (r *RedisCache) ForChain(chainID uint64) Cache {
+	scoped := *r
+	scoped.prefix = fmt.Sprintf("%s%d:", keyPrefix, chainID)
+	return &scoped
+}
+
 const (
-	// Key prefixes for Redis keys
-	keyLastBlock = "ethereum:last_block"
-	keyTxPrefix  = "ethereum:tx:"
+	// Key prefixes for Redis keys, appended to the cache's namespace
+	keyPrefix    = "ethereum:"
+	keyLastBlock = "last_block"
+	keyTxPrefix  = "tx:"
+	keyTokenMeta = "token:"
+	keyHeader    = "header:"
+	keyLease     = "lease:"
 
 	// TTL for transaction hash cache (24 hours)
 	// Prevents reprocessing transactions in case of chain reorganizations
 	txCacheTTL = 24 * time.Hour
+
+	// TTL for token metadata; MongoDB keeps the durable copy
+	tokenMetadataTTL = 24 * time.Hour
+
+	// Max keys per DEL when invalidating a range of block headers
+	headerDeleteBatch = 1000
 )
 
 // GetLastProcessedBlock retrieves the last processed block number from Redis
-// Returns 0 if key doesn't exist (first run)
+// Returns ErrCacheMiss if the key doesn't exist so callers fall back to MongoDB
 func (r *RedisCache) GetLastProcessedBlock(ctx context.Context) (uint64, error) {
 	if !r.enabled {
 		return 0, ErrCacheDisabled
 	}
 
-	val, err := r.client.Get(ctx, keyLastBlock).Result()
+	val, err := r.client.Get(ctx, r.prefix+keyLastBlock).Result()
 	if err == redis.Nil {
-		// Key doesn't exist - first run or cache was cleared
-		return 0, nil
+		// Key doesn't exist - first run, cache was cleared or a new chain namespace
+		return 0, ErrCacheMiss
 	}
 	if err != nil {
 		return 0, fmt.Errorf("failed to get last block from Redis: %w", err)
@@ -104,7 +133,7 @@ func (r *RedisCache) SetLastProcessedBlock(ctx context.Context, blockNumber uint
 	}
 
 	val := strconv.FormatUint(blockNumber, 10)
-	if err := r.client.Set(ctx, keyLastBlock, val, 0).Err(); err != nil {
+	if err := r.client.Set(ctx, r.prefix+keyLastBlock, val, 0).Err(); err != nil {
 		return fmt.Errorf("failed to set last block in Redis: %w", err)
 	}
 
@@ -118,7 +147,7 @@ func (r *RedisCache) IsTxProcessed(ctx context.Context, txHash string) (bool, er
 		return false, ErrCacheDisabled
 	}
 
-	key := keyTxPrefix + txHash
+	key := r.prefix + keyTxPrefix + txHash
 	exists, err := r.client.Exists(ctx, key).Result()
 	if err != nil {
 		return false, fmt.Errorf("failed to check tx in Redis: %w", err)
@@ -134,7 +163,7 @@ func (r *RedisCache) MarkTxProcessed(ctx context.Context, txHash string) error {
 		return ErrCacheDisabled
 	}
 
-	key := keyTxPrefix + txHash
+	key := r.prefix + keyTxPrefix + txHash
 	if err := r.client.Set(ctx, key, "1", txCacheTTL).Err(); err != nil {
 		return fmt.Errorf("failed to mark tx in Redis: %w", err)
 	}
@@ -142,6 +171,187 @@ func (r *RedisCache) MarkTxProcessed(ctx context.Context, txHash string) error {
 	return nil
 }
 
+// GetTokenMetadata retrieves cached metadata for a lowercase token address
+// Returns nil without error if the token is not cached
+func (r *RedisCache) GetTokenMetadata(ctx context.Context, token string) (*models.TokenMetadata, error) {
+	if !r.enabled {
+		return nil, ErrCacheDisabled
+	}
+
+	val, err := r.client.Get(ctx, r.prefix+keyTokenMeta+token).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token metadata from Redis: %w", err)
+	}
+
+	var metadata models.TokenMetadata
+	if err := json.Unmarshal(val, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid token metadata in cache: %w", err)
+	}
+
+	return &metadata, nil
+}
+
+// SetTokenMetadata caches token metadata with a TTL
+func (r *RedisCache) SetTokenMetadata(ctx context.Context, metadata *models.TokenMetadata) error {
+	if !r.enabled {
+		return ErrCacheDisabled
+	}
+
+	val, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode token metadata: %w", err)
+	}
+
+	if err := r.client.Set(ctx, r.prefix+keyTokenMeta+metadata.Address, val, tokenMetadataTTL).Err(); err != nil {
+		return fmt.Errorf("failed to set token metadata in Redis: %w", err)
+	}
+
+	return nil
+}
+
+// GetBlockHeaders retrieves the cached headers among numbers with a single MGET
+// Headers that are not cached are left out of the result
+func (r *RedisCache) GetBlockHeaders(ctx context.Context, numbers []uint64) (map[uint64]*models.BlockHeader, error) {
+	if !r.enabled {
+		return nil, ErrCacheDisabled
+	}
+
+	headers := make(map[uint64]*models.BlockHeader, len(numbers))
+	if len(numbers) == 0 {
+		return headers, nil
+	}
+
+	keys := make([]string, len(numbers))
+	for i, number := range numbers {
+		keys[i] = r.headerKey(number)
+	}
+
+	vals, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block headers from Redis: %w", err)
+	}
+
+	for _, val := range vals {
+		raw, ok := val.(string)
+		if !ok {
+			continue
+		}
+		var header models.BlockHeader
+		if err := json.Unmarshal([]byte(raw), &header); err != nil {
+			return nil, fmt.Errorf("invalid block header in cache: %w", err)
+		}
+		headers[header.Number] = &header
+	}
+
+	return headers, nil
+}
+
+// SetBlockHeaders caches block headers in one pipeline
+// A ttl of 0 keeps them until deleted, used for headers past finality
+func (r *RedisCache) SetBlockHeaders(ctx context.Context, headers []*models.BlockHeader, ttl time.Duration) error {
+	if !r.enabled {
+		return ErrCacheDisabled
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+
+	pipe := r.client.Pipeline()
+	for _, header := range headers {
+		val, err := json.Marshal(header)
+		if err != nil {
+			return fmt.Errorf("failed to encode block header: %w", err)
+		}
+		pipe.Set(ctx, r.headerKey(header.Number), val, ttl)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set block headers in Redis: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBlockHeaders drops cached headers of blocks fromBlock..toBlock after a reorg
+func (r *RedisCache) DeleteBlockHeaders(ctx context.Context, fromBlock, toBlock uint64) error {
+	if !r.enabled {
+		return ErrCacheDisabled
+	}
+
+	for start := fromBlock; start <= toBlock; start += headerDeleteBatch {
+		end := min(start+headerDeleteBatch-1, toBlock)
+		keys := make([]string, 0, end-start+1)
+		for number := start; number <= end; number++ {
+			keys = append(keys, r.headerKey(number))
+		}
+		if err := r.client.Del(ctx, keys...).Err(); err != nil {
+			return fmt.Errorf("failed to delete block headers from Redis: %w", err)
+		}
+		if end == toBlock {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (r *RedisCache) headerKey(blockNumber uint64) string {
+	return r.prefix + keyHeader + strconv.FormatUint(blockNumber, 10)
+}
+
+// acquireLeaseScript takes a free lease or extends one already held by ARGV[1]
+var acquireLeaseScript = redis.NewScript(`
+local holder = redis.call('GET', KEYS[1])
+if holder == false then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+if holder == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseLeaseScript deletes a lease only while ARGV[1] still holds it
+var releaseLeaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// AcquireLease takes the named lease for holder, or extends it if holder already has it
+// Returns false while another holder's lease has not expired; Redis expires the key after ttl
+func (r *RedisCache) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	if !r.enabled {
+		return false, ErrCacheDisabled
+	}
+
+	acquired, err := acquireLeaseScript.Run(ctx, r.client, []string{r.prefix + keyLease + name}, holder, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease in Redis: %w", err)
+	}
+
+	return acquired == 1, nil
+}
+
+// ReleaseLease gives up the named lease if holder still has it
+func (r *RedisCache) ReleaseLease(ctx context.Context, name, holder string) error {
+	if !r.enabled {
+		return ErrCacheDisabled
+	}
+
+	if err := releaseLeaseScript.Run(ctx, r.client, []string{r.prefix + keyLease + name}, holder).Err(); err != nil {
+		return fmt.Errorf("failed to release lease in Redis: %w", err)
+	}
+
+	return nil
+}
+
 // Close closes the Redis connection gracefully
 func (r *RedisCache) Close() error {
 	if !r.enabled || r.client == nil {
@@ -153,3 +363,6 @@ func (r *RedisCache) Close() error {
 
 // ErrCacheDisabled is returned when cache operations are attempted but Redis is disabled
 var ErrCacheDisabled = fmt.Errorf("cache disabled")
+
+// ErrCacheMiss is returned when a looked-up key is not cached
+var ErrCacheMiss = fmt.Errorf("cache miss")
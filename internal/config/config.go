@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/joho/godotenv"
 )
 
@@ -17,6 +19,10 @@ type Config struct {
 	Ingestion IngestionConfig
 	Logging   LoggingConfig
 	Streaming StreamingConfig
+	Backfill  BackfillConfig
+	Approvals ApprovalsConfig
+	Leader    LeaderConfig
+	Admin     AdminConfig
 }
 
 type ServerConfig struct {
@@ -24,8 +30,9 @@ type ServerConfig struct {
 }
 
 type EthereumConfig struct {
-	RPCURL    string // Single RPC URL (legacy mode)
-	RPCConfig string // Path to provider YAML config (preferred)
+	RPCURL      string // Single RPC URL (legacy mode)
+	RPCConfig   string // Path to provider YAML config (preferred)
+	TokenConfig string // Path to token allow/deny YAML config (optional)
 }
 
 type MongoDBConfig struct {
@@ -58,6 +65,44 @@ type IngestionConfig struct {
 	BatchMaxSize        uint64
 	BatchSuccessStreak  int
 	BatchFailureBackoff int
+	BatchStrategy       string        // "streak" or "aimd"
+	BatchTargetDuration time.Duration // AIMD: fetch duration a batch or request should stay under
+	BatchTargetLogs     int           // AIMD: logs a batch or request should stay under
+	BatchIncrease       uint64        // AIMD: blocks added after a full batch or request within both targets
+	BatchDecrease       float64       // AIMD: factor applied to a batch or request over either target
+	ReorgMaxDepth       uint64
+	ConfirmationDepth   uint64
+	IndexingMode        string        // "latest", "safe" or "finalized"
+	CoverageRepair      time.Duration // Interval between gap repair scans (0 disables)
+	NativeTransfers     bool          // Index plain ETH transfers from block transactions
+	InternalTransfers   bool          // Index ETH moved by contract calls, via block traces
+	HeaderCacheSize     int           // Max block headers kept in memory per chain
+	HeaderCacheTTL      time.Duration // Expiry of cached headers newer than the finalized block
+	MetadataCacheSize   int           // Max token metadata entries kept in memory per chain
+	PipelineDepth       int           // Batches queued between ingestion pipeline stages
+}
+
+type BackfillConfig struct {
+	Workers      int    // Default parallel workers per job
+	MaxWorkers   int    // Most parallel workers a job may request
+	ChunkSize    uint64 // Default blocks per persisted chunk
+	MaxChunkSize uint64 // Most blocks per chunk a job may request
+	BatchSize    uint64 // Blocks per eth_getLogs call within a chunk
+	MaxAttempts  int    // Attempts per chunk before it is marked failed
+}
+
+type ApprovalsConfig struct {
+	RiskySpenders []string // Spender addresses whose unlimited approvals are flagged
+}
+
+type LeaderConfig struct {
+	Election string        // "none", "mongo" or "redis"
+	LeaseTTL time.Duration // How long a lease outlives its last renewal
+	Instance string        // Identifies this replica as lease holder
+}
+
+type AdminConfig struct {
+	APIKeys map[string]string // Admin API key -> name recorded in the audit log
 }
 
 type StreamingConfig struct {
@@ -78,6 +123,7 @@ func Load() (*Config, error) {
 	cfg.Server.Port = getEnv("SERVER_PORT", "8080")
 	cfg.Ethereum.RPCURL = getEnv("ETH_RPC_URL", "")
 	cfg.Ethereum.RPCConfig = getEnv("RPC_CONFIG", "")
+	cfg.Ethereum.TokenConfig = getEnv("TOKEN_FILTER_CONFIG", "")
 	cfg.MongoDB.URI = getEnv("MONGODB_URI", "mongodb://localhost:27017")
 	cfg.MongoDB.Database = getEnv("MONGODB_DB", "ethereum")
 
@@ -187,6 +233,202 @@ func Load() (*Config, error) {
 	}
 	cfg.Ingestion.BatchFailureBackoff = batchFailureBackoff
 
+	// streak doubles after BATCH_SUCCESS_STREAK successes; aimd steers toward target durations and log counts
+	cfg.Ingestion.BatchStrategy = getEnv("BATCH_STRATEGY", "streak")
+	switch cfg.Ingestion.BatchStrategy {
+	case "streak", "aimd":
+	default:
+		return nil, fmt.Errorf("invalid BATCH_STRATEGY: %s (expected streak or aimd)", cfg.Ingestion.BatchStrategy)
+	}
+
+	batchTargetDuration, err := strconv.Atoi(getEnv("BATCH_TARGET_DURATION_MS", "2000"))
+	if err != nil || batchTargetDuration <= 0 {
+		return nil, fmt.Errorf("invalid BATCH_TARGET_DURATION_MS: must be a positive integer")
+	}
+	cfg.Ingestion.BatchTargetDuration = time.Duration(batchTargetDuration) * time.Millisecond
+
+	batchTargetLogs, err := strconv.Atoi(getEnv("BATCH_TARGET_LOGS", "5000"))
+	if err != nil || batchTargetLogs <= 0 {
+		return nil, fmt.Errorf("invalid BATCH_TARGET_LOGS: must be a positive integer")
+	}
+	cfg.Ingestion.BatchTargetLogs = batchTargetLogs
+
+	batchIncrease, err := strconv.ParseUint(getEnv("BATCH_AIMD_INCREASE", "5"), 10, 64)
+	if err != nil || batchIncrease == 0 {
+		return nil, fmt.Errorf("invalid BATCH_AIMD_INCREASE: must be a positive integer")
+	}
+	cfg.Ingestion.BatchIncrease = batchIncrease
+
+	batchDecrease, err := strconv.ParseFloat(getEnv("BATCH_AIMD_DECREASE", "0.5"), 64)
+	if err != nil || batchDecrease <= 0 || batchDecrease >= 1 {
+		return nil, fmt.Errorf("invalid BATCH_AIMD_DECREASE: must be between 0 and 1")
+	}
+	cfg.Ingestion.BatchDecrease = batchDecrease
+
+	// Maximum number of blocks to walk back when searching for a common ancestor after a reorg
+	reorgMaxDepth, err := strconv.ParseUint(getEnv("REORG_MAX_DEPTH", "64"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REORG_MAX_DEPTH: %w", err)
+	}
+	cfg.Ingestion.ReorgMaxDepth = reorgMaxDepth
+
+	// Finality configuration: index only blocks this far behind the chosen head
+	confirmationDepth, err := strconv.ParseUint(getEnv("CONFIRMATION_DEPTH", "0"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONFIRMATION_DEPTH: %w", err)
+	}
+	cfg.Ingestion.ConfirmationDepth = confirmationDepth
+
+	cfg.Ingestion.IndexingMode = getEnv("INDEXING_MODE", "latest")
+	switch cfg.Ingestion.IndexingMode {
+	case "latest", "safe", "finalized":
+	default:
+		return nil, fmt.Errorf("invalid INDEXING_MODE: %s (expected latest, safe or finalized)", cfg.Ingestion.IndexingMode)
+	}
+
+	coverageRepair, err := strconv.Atoi(getEnv("COVERAGE_REPAIR_INTERVAL", "300"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid COVERAGE_REPAIR_INTERVAL: %w", err)
+	}
+	cfg.Ingestion.CoverageRepair = time.Duration(coverageRepair) * time.Second
+
+	// Native ETH transfers need every block and its receipts, so they are opt-in
+	nativeTransfers := getEnv("INDEX_NATIVE_TRANSFERS", "false")
+	cfg.Ingestion.NativeTransfers = nativeTransfers == "true" || nativeTransfers == "1"
+
+	// Internal transfers need a provider with debug_traceBlockByNumber or trace_block
+	internalTransfers := getEnv("INDEX_INTERNAL_TRANSFERS", "false")
+	cfg.Ingestion.InternalTransfers = internalTransfers == "true" || internalTransfers == "1"
+
+	// Batches queued between the fetch, parse and store stages of live ingestion
+	pipelineDepth, err := strconv.Atoi(getEnv("PIPELINE_DEPTH", "2"))
+	if err != nil || pipelineDepth <= 0 {
+		return nil, fmt.Errorf("invalid PIPELINE_DEPTH: must be a positive integer")
+	}
+	cfg.Ingestion.PipelineDepth = pipelineDepth
+
+	// Block header cache; headers past finality never expire
+	headerCacheSize, err := strconv.Atoi(getEnv("HEADER_CACHE_SIZE", "10000"))
+	if err != nil || headerCacheSize <= 0 {
+		return nil, fmt.Errorf("invalid HEADER_CACHE_SIZE: must be a positive integer")
+	}
+	cfg.Ingestion.HeaderCacheSize = headerCacheSize
+
+	headerCacheTTL, err := strconv.Atoi(getEnv("HEADER_CACHE_TTL", "300"))
+	if err != nil || headerCacheTTL <= 0 {
+		return nil, fmt.Errorf("invalid HEADER_CACHE_TTL: must be a positive integer")
+	}
+	cfg.Ingestion.HeaderCacheTTL = time.Duration(headerCacheTTL) * time.Second
+
+	metadataCacheSize, err := strconv.Atoi(getEnv("TOKEN_METADATA_CACHE_SIZE", "10000"))
+	if err != nil || metadataCacheSize <= 0 {
+		return nil, fmt.Errorf("invalid TOKEN_METADATA_CACHE_SIZE: must be a positive integer")
+	}
+	cfg.Ingestion.MetadataCacheSize = metadataCacheSize
+
+	// Backfill configuration
+	backfillWorkers, err := strconv.Atoi(getEnv("BACKFILL_WORKERS", "4"))
+	if err != nil || backfillWorkers <= 0 {
+		return nil, fmt.Errorf("invalid BACKFILL_WORKERS: must be a positive integer")
+	}
+	cfg.Backfill.Workers = backfillWorkers
+
+	backfillMaxWorkers, err := strconv.Atoi(getEnv("BACKFILL_MAX_WORKERS", "16"))
+	if err != nil || backfillMaxWorkers <= 0 {
+		return nil, fmt.Errorf("invalid BACKFILL_MAX_WORKERS: must be a positive integer")
+	}
+	if backfillMaxWorkers < backfillWorkers {
+		return nil, fmt.Errorf("invalid BACKFILL_MAX_WORKERS: must not be below BACKFILL_WORKERS")
+	}
+	cfg.Backfill.MaxWorkers = backfillMaxWorkers
+
+	backfillChunkSize, err := strconv.ParseUint(getEnv("BACKFILL_CHUNK_SIZE", "1000"), 10, 64)
+	if err != nil || backfillChunkSize == 0 {
+		return nil, fmt.Errorf("invalid BACKFILL_CHUNK_SIZE: must be a positive integer")
+	}
+	cfg.Backfill.ChunkSize = backfillChunkSize
+
+	backfillMaxChunkSize, err := strconv.ParseUint(getEnv("BACKFILL_MAX_CHUNK_SIZE", "100000"), 10, 64)
+	if err != nil || backfillMaxChunkSize == 0 {
+		return nil, fmt.Errorf("invalid BACKFILL_MAX_CHUNK_SIZE: must be a positive integer")
+	}
+	if backfillMaxChunkSize < backfillChunkSize {
+		return nil, fmt.Errorf("invalid BACKFILL_MAX_CHUNK_SIZE: must not be below BACKFILL_CHUNK_SIZE")
+	}
+	cfg.Backfill.MaxChunkSize = backfillMaxChunkSize
+
+	backfillBatchSize, err := strconv.ParseUint(getEnv("BACKFILL_BATCH_SIZE", strconv.FormatUint(blockBatchSize, 10)), 10, 64)
+	if err != nil || backfillBatchSize == 0 {
+		return nil, fmt.Errorf("invalid BACKFILL_BATCH_SIZE: must be a positive integer")
+	}
+	cfg.Backfill.BatchSize = backfillBatchSize
+
+	backfillMaxAttempts, err := strconv.Atoi(getEnv("BACKFILL_MAX_ATTEMPTS", "3"))
+	if err != nil || backfillMaxAttempts <= 0 {
+		return nil, fmt.Errorf("invalid BACKFILL_MAX_ATTEMPTS: must be a positive integer")
+	}
+	cfg.Backfill.MaxAttempts = backfillMaxAttempts
+
+	// Approval monitoring: comma-separated spender addresses flagged when granted unlimited approvals
+	if riskySpenders := getEnv("RISKY_SPENDERS", ""); riskySpenders != "" {
+		for _, spender := range strings.Split(riskySpenders, ",") {
+			spender = strings.TrimSpace(spender)
+			if spender == "" {
+				continue
+			}
+			if !common.IsHexAddress(spender) {
+				return nil, fmt.Errorf("invalid RISKY_SPENDERS entry: %s", spender)
+			}
+			cfg.Approvals.RiskySpenders = append(cfg.Approvals.RiskySpenders, spender)
+		}
+	}
+
+	// Leader election: with several replicas only the lease holder runs ingestion
+	cfg.Leader.Election = getEnv("LEADER_ELECTION", "none")
+	switch cfg.Leader.Election {
+	case "none", "mongo":
+	case "redis":
+		if !cfg.Redis.Enabled {
+			return nil, fmt.Errorf("LEADER_ELECTION=redis requires USE_REDIS")
+		}
+	default:
+		return nil, fmt.Errorf("invalid LEADER_ELECTION: %s (expected none, mongo or redis)", cfg.Leader.Election)
+	}
+
+	leaseTTL, err := strconv.Atoi(getEnv("LEADER_LEASE_TTL", "15"))
+	if err != nil || leaseTTL < 3 {
+		return nil, fmt.Errorf("invalid LEADER_LEASE_TTL: must be at least 3 seconds")
+	}
+	cfg.Leader.LeaseTTL = time.Duration(leaseTTL) * time.Second
+
+	cfg.Leader.Instance = getEnv("LEADER_INSTANCE_ID", "")
+	if cfg.Leader.Instance == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "unknown"
+		}
+		cfg.Leader.Instance = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	// Admin API keys: comma-separated name:key pairs; without any the admin endpoints are disabled
+	cfg.Admin.APIKeys = make(map[string]string)
+	if adminKeys := getEnv("ADMIN_API_KEYS", ""); adminKeys != "" {
+		for _, entry := range strings.Split(adminKeys, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			name, key, ok := strings.Cut(entry, ":")
+			if !ok || name == "" || key == "" {
+				return nil, fmt.Errorf("invalid ADMIN_API_KEYS entry: expected name:key")
+			}
+			if _, exists := cfg.Admin.APIKeys[key]; exists {
+				return nil, fmt.Errorf("invalid ADMIN_API_KEYS: duplicate key for %s", name)
+			}
+			cfg.Admin.APIKeys[key] = name
+		}
+	}
+
 	// Either RPC_CONFIG (YAML) or ETH_RPC_URL (single provider) must be provided
 	if cfg.Ethereum.RPCConfig == "" && cfg.Ethereum.RPCURL == "" {
 		return nil, fmt.Errorf("either RPC_CONFIG or ETH_RPC_URL must be provided")
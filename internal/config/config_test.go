@@ -0,0 +1,42 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadAdminAPIKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "unset disables admin endpoints", keys: "", want: map[string]string{}},
+		{name: "named keys", keys: "ops:s3cret, ci:other,", want: map[string]string{"s3cret": "ops", "other": "ci"}},
+		{name: "key containing a colon", keys: "ops:a:b", want: map[string]string{"a:b": "ops"}},
+		{name: "missing name", keys: ":s3cret", wantErr: true},
+		{name: "missing key", keys: "ops:", wantErr: true},
+		{name: "no separator", keys: "s3cret", wantErr: true},
+		{name: "duplicate key", keys: "ops:s3cret,ci:s3cret", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ETH_RPC_URL", "http://localhost:8545")
+			t.Setenv("RPC_CONFIG", "")
+			t.Setenv("ADMIN_API_KEYS", tt.keys)
+
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(cfg.Admin.APIKeys, tt.want) {
+				t.Errorf("Load() admin keys = %v, want %v", cfg.Admin.APIKeys, tt.want)
+			}
+		})
+	}
+}
@@ -17,11 +17,33 @@ type ProviderConfig struct {
 	Weight   int           `yaml:"weight"`
 	MaxRange uint64        `yaml:"maxRange"`
 	Timeout  time.Duration `yaml:"timeout"`
+
+	HeaderBatchSize int `yaml:"headerBatchSize"` // Headers per JSON-RPC batch request
+}
+
+// ChainConfig describes one indexed chain with its own providers and ingestion settings
+// Zero values inherit the environment settings
+type ChainConfig struct {
+	Name              string           `yaml:"name"`
+	ChainID           uint64           `yaml:"chain_id"` // 0 = detect via eth_chainId
+	StartBlock        *uint64          `yaml:"start_block"`
+	PollInterval      time.Duration    `yaml:"poll_interval"`
+	BlockBatchSize    uint64           `yaml:"block_batch_size"`
+	AdaptiveBatch     *bool            `yaml:"adaptive_batch"`
+	BatchMinSize      uint64           `yaml:"batch_min_size"`
+	BatchMaxSize      uint64           `yaml:"batch_max_size"`
+	BatchStrategy     string           `yaml:"batch_strategy"`
+	ConfirmationDepth *uint64          `yaml:"confirmation_depth"`
+	IndexingMode      string           `yaml:"indexing_mode"`
+	TokenConfig       string           `yaml:"token_config"` // Path to this chain's token allow/deny YAML
+	Providers         []ProviderConfig `yaml:"providers"`
 }
 
 // ProvidersConfig holds the complete provider configuration
+// Either a single chain's providers or a list of chains may be given, not both
 type ProvidersConfig struct {
 	Providers      []ProviderConfig   `yaml:"providers"`
+	Chains         []ChainConfig      `yaml:"chains"`
 	CircuitBreaker CircuitBreakerYAML `yaml:"circuit_breaker"`
 }
 
@@ -33,9 +55,32 @@ type CircuitBreakerYAML struct {
 	HalfOpenMaxCalls int           `yaml:"half_open_max_calls"`
 }
 
-// LoadProvidersFromYAML loads provider configuration from a YAML file
-// Falls back to single provider from env if file doesn't exist
-func LoadProvidersFromYAML(filePath string, fallbackURL string) ([]*ethereum.Provider, error) {
+// Chain is one indexed chain resolved from the configuration
+type Chain struct {
+	Name        string
+	ChainID     uint64               // Configured chain ID (0 = detect via eth_chainId)
+	Providers   []*ethereum.Provider // Empty in legacy mode (single ETH_RPC_URL client)
+	TokenConfig string               // Path to token allow/deny YAML (optional)
+	Ingestion   IngestionConfig      // Environment settings with the chain's overrides applied
+}
+
+// LoadChains resolves the chains to index
+// Without RPC_CONFIG a single legacy chain uses ETH_RPC_URL directly
+func LoadChains(cfg *Config) ([]*Chain, error) {
+	if cfg.Ethereum.RPCConfig == "" {
+		return []*Chain{{
+			Name:        "default",
+			TokenConfig: cfg.Ethereum.TokenConfig,
+			Ingestion:   cfg.Ingestion,
+		}}, nil
+	}
+	return LoadChainsFromYAML(cfg.Ethereum.RPCConfig, cfg.Ethereum.RPCURL, cfg.Ingestion, cfg.Ethereum.TokenConfig)
+}
+
+// LoadChainsFromYAML loads chain and provider configuration from a YAML file
+// A top-level providers list (or a missing file with a fallback URL) describes a single chain
+// using the environment settings
+func LoadChainsFromYAML(filePath, fallbackURL string, defaults IngestionConfig, tokenConfig string) ([]*Chain, error) {
 	// Try to load from YAML file
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -51,6 +96,7 @@ func LoadProvidersFromYAML(filePath string, fallbackURL string) ([]*ethereum.Pro
 			fallbackURL,
 			10,
 			10, // Default max range for free tier
+			ethereum.DefaultHeaderBatchSize,
 			30*time.Second,
 			cbConfig,
 		)
@@ -58,7 +104,12 @@ func LoadProvidersFromYAML(filePath string, fallbackURL string) ([]*ethereum.Pro
 			return nil, fmt.Errorf("failed to create fallback provider: %w", err)
 		}
 
-		return []*ethereum.Provider{provider}, nil
+		return []*Chain{{
+			Name:        "default",
+			Providers:   []*ethereum.Provider{provider},
+			TokenConfig: tokenConfig,
+			Ingestion:   defaults,
+		}}, nil
 	}
 
 	var config ProvidersConfig
@@ -66,10 +117,6 @@ func LoadProvidersFromYAML(filePath string, fallbackURL string) ([]*ethereum.Pro
 		return nil, fmt.Errorf("failed to parse provider config: %w", err)
 	}
 
-	if len(config.Providers) == 0 {
-		return nil, fmt.Errorf("no providers configured in YAML file")
-	}
-
 	// Convert YAML config to circuit breaker config
 	cbConfig := ethereum.CircuitBreakerConfig{
 		FailureThreshold: config.CircuitBreaker.FailureThreshold,
@@ -83,9 +130,132 @@ func LoadProvidersFromYAML(filePath string, fallbackURL string) ([]*ethereum.Pro
 		cbConfig = ethereum.DefaultCircuitBreakerConfig()
 	}
 
-	// Create providers
-	providers := make([]*ethereum.Provider, 0, len(config.Providers))
-	for _, pConfig := range config.Providers {
+	if len(config.Chains) > 0 && len(config.Providers) > 0 {
+		return nil, fmt.Errorf("provider config must list either providers or chains, not both")
+	}
+
+	if len(config.Chains) == 0 {
+		if len(config.Providers) == 0 {
+			return nil, fmt.Errorf("no providers configured in YAML file")
+		}
+		providers, err := buildProviders(config.Providers, cbConfig)
+		if err != nil {
+			return nil, err
+		}
+		return []*Chain{{
+			Name:        "default",
+			Providers:   providers,
+			TokenConfig: tokenConfig,
+			Ingestion:   defaults,
+		}}, nil
+	}
+
+	chains := make([]*Chain, 0, len(config.Chains))
+	names := make(map[string]bool, len(config.Chains))
+	ids := make(map[uint64]bool, len(config.Chains))
+	for _, cConfig := range config.Chains {
+		if cConfig.Name == "" {
+			return nil, fmt.Errorf("chain without a name in provider config")
+		}
+		if names[cConfig.Name] {
+			return nil, fmt.Errorf("duplicate chain name %q in provider config", cConfig.Name)
+		}
+		names[cConfig.Name] = true
+		if cConfig.ChainID != 0 {
+			if ids[cConfig.ChainID] {
+				return nil, fmt.Errorf("duplicate chain_id %d in provider config", cConfig.ChainID)
+			}
+			ids[cConfig.ChainID] = true
+		}
+
+		if len(cConfig.Providers) == 0 {
+			return nil, fmt.Errorf("no providers configured for chain %s", cConfig.Name)
+		}
+		providers, err := buildProviders(cConfig.Providers, cbConfig)
+		if err != nil {
+			return nil, fmt.Errorf("chain %s: %w", cConfig.Name, err)
+		}
+
+		ingestion, err := cConfig.ingestion(defaults)
+		if err != nil {
+			return nil, fmt.Errorf("chain %s: %w", cConfig.Name, err)
+		}
+
+		chains = append(chains, &Chain{
+			Name:        cConfig.Name,
+			ChainID:     cConfig.ChainID,
+			Providers:   providers,
+			TokenConfig: cConfig.TokenConfig,
+			Ingestion:   ingestion,
+		})
+	}
+
+	return chains, nil
+}
+
+// ingestion applies the chain's overrides to the environment settings
+func (c ChainConfig) ingestion(defaults IngestionConfig) (IngestionConfig, error) {
+	cfg := defaults
+	if c.StartBlock != nil {
+		cfg.StartBlock = *c.StartBlock
+	}
+	if c.PollInterval > 0 {
+		cfg.PollInterval = c.PollInterval
+	}
+	if c.BlockBatchSize > 0 {
+		cfg.BlockBatchSize = min(c.BlockBatchSize, 100)
+	}
+	if c.AdaptiveBatch != nil {
+		cfg.AdaptiveBatch = *c.AdaptiveBatch
+	}
+	if c.BatchStrategy != "" {
+		switch c.BatchStrategy {
+		case "streak", "aimd":
+		default:
+			return cfg, fmt.Errorf("invalid batch_strategy: %s (expected streak or aimd)", c.BatchStrategy)
+		}
+		cfg.BatchStrategy = c.BatchStrategy
+	}
+	if c.ConfirmationDepth != nil {
+		cfg.ConfirmationDepth = *c.ConfirmationDepth
+	}
+	if c.IndexingMode != "" {
+		switch c.IndexingMode {
+		case "latest", "safe", "finalized":
+		default:
+			return cfg, fmt.Errorf("invalid indexing_mode: %s (expected latest, safe or finalized)", c.IndexingMode)
+		}
+		cfg.IndexingMode = c.IndexingMode
+	}
+
+	// If adaptive batch is disabled, use fixed batch size
+	if !cfg.AdaptiveBatch {
+		cfg.BatchMinSize = cfg.BlockBatchSize
+		cfg.BatchMaxSize = cfg.BlockBatchSize
+		return cfg, nil
+	}
+
+	// Fixed environment bounds are only inherited when the environment batching was adaptive too
+	if !defaults.AdaptiveBatch {
+		cfg.BatchMinSize = 1
+		cfg.BatchMaxSize = 100
+	}
+	if c.BatchMinSize > 0 {
+		cfg.BatchMinSize = c.BatchMinSize
+	}
+	if c.BatchMaxSize > 0 {
+		cfg.BatchMaxSize = c.BatchMaxSize
+	}
+	if cfg.BatchMinSize > cfg.BatchMaxSize {
+		return cfg, fmt.Errorf("batch_min_size %d exceeds batch_max_size %d", cfg.BatchMinSize, cfg.BatchMaxSize)
+	}
+	return cfg, nil
+}
+
+// buildProviders creates providers from YAML entries, skipping entries without a URL
+func buildProviders(configs []ProviderConfig, cbConfig ethereum.CircuitBreakerConfig) ([]*ethereum.Provider, error) {
+	providers := make([]*ethereum.Provider, 0, len(configs))
+	for _, pConfig := range configs {
 		if pConfig.URL == "" {
 			continue // Skip invalid entries
 		}
@@ -100,12 +270,16 @@ func LoadProvidersFromYAML(filePath string, fallbackURL string) ([]*ethereum.Pro
 		if pConfig.Timeout == 0 {
 			pConfig.Timeout = 30 * time.Second
 		}
+		if pConfig.HeaderBatchSize == 0 {
+			pConfig.HeaderBatchSize = ethereum.DefaultHeaderBatchSize
+		}
 
 		provider, err := ethereum.NewProvider(
 			pConfig.Name,
 			pConfig.URL,
 			pConfig.Weight,
 			pConfig.MaxRange,
+			pConfig.HeaderBatchSize,
 			pConfig.Timeout,
 			cbConfig,
 		)
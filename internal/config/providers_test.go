@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadChainsFromYAML(t *testing.T) {
+	defaults := IngestionConfig{StartBlock: 100, PollInterval: 12 * time.Second, BlockBatchSize: 10, BatchMinSize: 10, BatchMaxSize: 10, IndexingMode: "latest"}
+
+	tests := []struct {
+		name       string
+		yaml       string
+		wantErr    bool
+		wantChains []string
+	}{
+		{
+			name:       "single chain providers",
+			yaml:       "providers:\n  - name: alchemy\n    url: https://eth.example\n",
+			wantChains: []string{"default"},
+		},
+		{
+			name: "chains",
+			yaml: `
+chains:
+  - name: mainnet
+    chain_id: 1
+    providers:
+      - name: alchemy
+        url: https://eth.example
+  - name: base
+    chain_id: 8453
+    start_block: 0
+    poll_interval: 2s
+    indexing_mode: finalized
+    providers:
+      - name: base
+        url: https://base.example
+        maxRange: 500
+`,
+			wantChains: []string{"mainnet", "base"},
+		},
+		{name: "providers and chains", yaml: "providers:\n  - url: https://eth.example\nchains:\n  - name: base\n    providers:\n      - url: https://base.example\n", wantErr: true},
+		{name: "no providers", yaml: "circuit_breaker:\n  failure_threshold: 3\n", wantErr: true},
+		{name: "unnamed chain", yaml: "chains:\n  - providers:\n      - url: https://eth.example\n", wantErr: true},
+		{name: "duplicate name", yaml: "chains:\n  - name: a\n    providers: [{url: https://a.example}]\n  - name: a\n    providers: [{url: https://b.example}]\n", wantErr: true},
+		{name: "duplicate chain id", yaml: "chains:\n  - name: a\n    chain_id: 1\n    providers: [{url: https://a.example}]\n  - name: b\n    chain_id: 1\n    providers: [{url: https://b.example}]\n", wantErr: true},
+		{name: "chain without providers", yaml: "chains:\n  - name: a\n", wantErr: true},
+		{name: "providers without urls", yaml: "chains:\n  - name: a\n    providers: [{name: empty}]\n", wantErr: true},
+		{name: "invalid indexing mode", yaml: "chains:\n  - name: a\n    indexing_mode: confirmed\n    providers: [{url: https://a.example}]\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "providers.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			chains, err := LoadChainsFromYAML(path, "", defaults, "tokens.yaml")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadChainsFromYAML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(chains) != len(tt.wantChains) {
+				t.Fatalf("got %d chains, want %d", len(chains), len(tt.wantChains))
+			}
+			for i, chain := range chains {
+				if chain.Name != tt.wantChains[i] {
+					t.Errorf("chain %d = %s, want %s", i, chain.Name, tt.wantChains[i])
+				}
+			}
+		})
+	}
+
+	t.Run("chain overrides", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "providers.yaml")
+		yaml := "chains:\n  - name: base\n    chain_id: 8453\n    start_block: 0\n    poll_interval: 2s\n    indexing_mode: finalized\n    providers:\n      - url: https://base.example\n        maxRange: 500\n"
+		if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		chains, err := LoadChainsFromYAML(path, "", defaults, "tokens.yaml")
+		if err != nil {
+			t.Fatalf("LoadChainsFromYAML() error = %v", err)
+		}
+		base := chains[0]
+		if base.ChainID != 8453 || base.TokenConfig != "" {
+			t.Errorf("chain = %d (tokens %q), want 8453 without the environment token config", base.ChainID, base.TokenConfig)
+		}
+		if base.Ingestion.StartBlock != 0 || base.Ingestion.PollInterval != 2*time.Second || base.Ingestion.IndexingMode != "finalized" {
+			t.Errorf("ingestion = %+v, want the chain's overrides", base.Ingestion)
+		}
+		if base.Ingestion.BlockBatchSize != defaults.BlockBatchSize {
+			t.Errorf("BlockBatchSize = %d, want the environment's %d", base.Ingestion.BlockBatchSize, defaults.BlockBatchSize)
+		}
+		if len(base.Providers) != 1 || base.Providers[0].MaxRange != 500 || base.Providers[0].Weight != 1 {
+			t.Errorf("providers = %+v, want one with maxRange 500 and default weight", base.Providers)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing.yaml")
+		chains, err := LoadChainsFromYAML(missing, "https://eth.example", defaults, "tokens.yaml")
+		if err != nil || len(chains) != 1 || chains[0].TokenConfig != "tokens.yaml" {
+			t.Errorf("LoadChainsFromYAML() = %v, %v, want the fallback chain", chains, err)
+		}
+		if _, err := LoadChainsFromYAML(missing, "", defaults, ""); err == nil {
+			t.Errorf("LoadChainsFromYAML() without a fallback URL succeeded")
+		}
+	})
+}
+
+func TestChainIngestion(t *testing.T) {
+	fixed := IngestionConfig{BlockBatchSize: 10, BatchMinSize: 10, BatchMaxSize: 10}
+	adaptive := IngestionConfig{BlockBatchSize: 10, AdaptiveBatch: true, BatchMinSize: 5, BatchMaxSize: 50}
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name     string
+		chain    ChainConfig
+		defaults IngestionConfig
+		wantErr  bool
+		wantMin  uint64
+		wantMax  uint64
+	}{
+		{name: "inherits fixed batching", defaults: fixed, wantMin: 10, wantMax: 10},
+		{name: "inherits adaptive bounds", defaults: adaptive, wantMin: 5, wantMax: 50},
+		{name: "fixed batch size capped", chain: ChainConfig{BlockBatchSize: 500}, defaults: fixed, wantMin: 100, wantMax: 100},
+		{name: "enables adaptive batching", chain: ChainConfig{AdaptiveBatch: &enabled}, defaults: fixed, wantMin: 1, wantMax: 100},
+		{name: "disables adaptive batching", chain: ChainConfig{AdaptiveBatch: &disabled}, defaults: adaptive, wantMin: 10, wantMax: 10},
+		{name: "overrides adaptive bounds", chain: ChainConfig{BatchMinSize: 2, BatchMaxSize: 20}, defaults: adaptive, wantMin: 2, wantMax: 20},
+		{name: "inverted bounds", chain: ChainConfig{BatchMinSize: 60}, defaults: adaptive, wantErr: true},
+		{name: "invalid strategy", chain: ChainConfig{BatchStrategy: "linear"}, defaults: adaptive, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tt.chain.ingestion(tt.defaults)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ingestion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.BatchMinSize != tt.wantMin || cfg.BatchMaxSize != tt.wantMax {
+				t.Errorf("batch bounds = [%d, %d], want [%d, %d]", cfg.BatchMinSize, cfg.BatchMaxSize, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"pagrin/internal/ethereum"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v3"
+)
+
+// TokenRuleConfig represents a single allow/deny entry
+type TokenRuleConfig struct {
+	Address    string `yaml:"address"`
+	StartBlock uint64 `yaml:"start_block"`
+	Name       string `yaml:"name"` // Informational only
+}
+
+// TokensConfig holds the token allow/deny lists
+type TokensConfig struct {
+	Allow []TokenRuleConfig `yaml:"allow"`
+	Deny  []TokenRuleConfig `yaml:"deny"`
+}
+
+// LoadTokenRulesFromYAML loads token allow and deny rules from a YAML file
+// An empty path disables filtering (both lists empty)
+func LoadTokenRulesFromYAML(filePath string) (allow, deny []ethereum.TokenRule, err error) {
+	if filePath == "" {
+		return nil, nil, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read token filter config: %w", err)
+	}
+
+	var config TokensConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse token filter config: %w", err)
+	}
+
+	allow, err = toTokenRules(config.Allow, "allow")
+	if err != nil {
+		return nil, nil, err
+	}
+	deny, err = toTokenRules(config.Deny, "deny")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return allow, deny, nil
+}
+
+func toTokenRules(entries []TokenRuleConfig, list string) ([]ethereum.TokenRule, error) {
+	rules := make([]ethereum.TokenRule, 0, len(entries))
+	seen := make(map[common.Address]bool, len(entries))
+	for _, entry := range entries {
+		if !common.IsHexAddress(entry.Address) {
+			return nil, fmt.Errorf("invalid %s token address: %q", list, entry.Address)
+		}
+		address := common.HexToAddress(entry.Address)
+		if seen[address] {
+			return nil, fmt.Errorf("duplicate %s token address: %s", list, entry.Address)
+		}
+		seen[address] = true
+		rules = append(rules, ethereum.TokenRule{Address: address, StartBlock: entry.StartBlock})
+	}
+	return rules, nil
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestLoadTokenRulesFromYAML(t *testing.T) {
+	tests := []struct {
+		name      string
+		yaml      string
+		wantAllow int
+		wantDeny  int
+		wantErr   bool
+	}{
+		{
+			name: "allow and deny",
+			yaml: `
+allow:
+  - address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+    start_block: 6082465
+    name: USDC
+deny:
+  - address: "0x6b175474e89094c44da98b954eedeac495271d0f"
+`,
+			wantAllow: 1,
+			wantDeny:  1,
+		},
+		{name: "empty file", yaml: "", wantAllow: 0, wantDeny: 0},
+		{name: "invalid address", yaml: "allow:\n  - address: usdc\n", wantErr: true},
+		{
+			name:    "duplicate address in different case",
+			yaml:    "deny:\n  - address: \"0x6b175474e89094c44da98b954eedeac495271d0f\"\n  - address: \"0x6B175474E89094C44Da98b954EedeAC495271d0F\"\n",
+			wantErr: true,
+		},
+		{name: "malformed yaml", yaml: "allow: [", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tokens.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			allow, deny, err := LoadTokenRulesFromYAML(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadTokenRulesFromYAML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(allow) != tt.wantAllow || len(deny) != tt.wantDeny {
+				t.Errorf("got %d allow and %d deny rules, want %d and %d", len(allow), len(deny), tt.wantAllow, tt.wantDeny)
+			}
+		})
+	}
+
+	t.Run("parsed rule", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tokens.yaml")
+		yaml := "allow:\n  - address: \"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48\"\n    start_block: 6082465\n"
+		if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		allow, _, err := LoadTokenRulesFromYAML(path)
+		if err != nil {
+			t.Fatalf("LoadTokenRulesFromYAML() error = %v", err)
+		}
+		want := common.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+		if allow[0].Address != want || allow[0].StartBlock != 6082465 {
+			t.Errorf("rule = %s from %d, want %s from 6082465", allow[0].Address.Hex(), allow[0].StartBlock, want.Hex())
+		}
+	})
+
+	t.Run("no file disables filtering", func(t *testing.T) {
+		allow, deny, err := LoadTokenRulesFromYAML("")
+		if err != nil || allow != nil || deny != nil {
+			t.Errorf("LoadTokenRulesFromYAML(\"\") = %v, %v, %v, want nil rules", allow, deny, err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, _, err := LoadTokenRulesFromYAML(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+			t.Error("LoadTokenRulesFromYAML() of a missing file succeeded")
+		}
+	})
+}
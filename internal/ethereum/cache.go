@@ -1,81 +1,203 @@
 package ethereum
 
 import (
+	"container/list"
+	"context"
 	"sync"
 	"time"
+
+	"pagrin/internal/metrics"
+	"pagrin/internal/models"
+
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
-// BlockHeaderCache provides in-memory caching of block timestamps
-// Reduces redundant RPC calls when processing multiple logs from the same block
+// HeaderStore is the optional shared tier behind the in-memory header cache
+// Implemented by the Redis cache so ingestion and API replicas share fetched headers
+type HeaderStore interface {
+	GetBlockHeaders(ctx context.Context, numbers []uint64) (map[uint64]*models.BlockHeader, error)
+	SetBlockHeaders(ctx context.Context, headers []*models.BlockHeader, ttl time.Duration) error // ttl 0 never expires
+	DeleteBlockHeaders(ctx context.Context, fromBlock, toBlock uint64) error
+}
+
+// BlockHeaderCache is a size-bounded LRU of block hashes, parent hashes and timestamps
+// Headers at or below the finalized block never expire; newer ones expire after ttl since a reorg may replace them
+// Memory misses fall through to the optional shared store; store errors count as misses
 type BlockHeaderCache struct {
-	cache map[uint64]cachedHeader
-	mu    sync.RWMutex
-	ttl   time.Duration
+	mu        sync.Mutex
+	entries   map[uint64]*list.Element
+	order     *list.List // Most recently used at the front
+	size      int
+	ttl       time.Duration
+	finalized uint64
+
+	store HeaderStore // Optional, nil without Redis
+	chain string      // chain_id metrics label
 }
 
 type cachedHeader struct {
-	timestamp time.Time
+	header    *models.BlockHeader
 	expiresAt time.Time
 }
 
-// NewBlockHeaderCache creates a new block header cache with specified TTL
-// TTL should be long enough to cover typical batch processing (5 minutes is safe)
-func NewBlockHeaderCache(ttl time.Duration) *BlockHeaderCache {
+// NewBlockHeaderCache creates a header cache holding at most size headers
+// store may be nil to keep headers in memory only
+func NewBlockHeaderCache(size int, ttl time.Duration, store HeaderStore, chain string) *BlockHeaderCache {
 	return &BlockHeaderCache{
-		cache: make(map[uint64]cachedHeader),
-		ttl:   ttl,
+		entries: make(map[uint64]*list.Element, size),
+		order:   list.New(),
+		size:    size,
+		ttl:     ttl,
+		store:   store,
+		chain:   chain,
 	}
 }
 
-// Get retrieves a cached block timestamp
-// Returns timestamp and true if found and not expired, false otherwise
-func (c *BlockHeaderCache) Get(blockNumber uint64) (time.Time, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// GetMany returns the cached headers among numbers; missing ones are left out
+func (c *BlockHeaderCache) GetMany(ctx context.Context, numbers []uint64) map[uint64]*models.BlockHeader {
+	found := make(map[uint64]*models.BlockHeader, len(numbers))
+	missing := make([]uint64, 0)
+
+	c.mu.Lock()
+	now := time.Now()
+	for _, number := range numbers {
+		if header, ok := c.getLocked(number, now); ok {
+			found[number] = header
+		} else {
+			missing = append(missing, number)
+		}
+	}
+	c.mu.Unlock()
+
+	metrics.HeaderCacheRequestsTotal.WithLabelValues(c.chain, "memory", "hit").Add(float64(len(found)))
+	metrics.HeaderCacheRequestsTotal.WithLabelValues(c.chain, "memory", "miss").Add(float64(len(missing)))
+
+	if c.store == nil || len(missing) == 0 {
+		return found
+	}
 
-	cached, exists := c.cache[blockNumber]
-	if !exists {
-		return time.Time{}, false
+	shared, err := c.store.GetBlockHeaders(ctx, missing)
+	if err != nil {
+		shared = nil
 	}
+	metrics.HeaderCacheRequestsTotal.WithLabelValues(c.chain, "redis", "hit").Add(float64(len(shared)))
+	metrics.HeaderCacheRequestsTotal.WithLabelValues(c.chain, "redis", "miss").Add(float64(len(missing) - len(shared)))
 
-	// Check if expired
-	if time.Now().After(cached.expiresAt) {
-		// Expired - remove it (async cleanup)
-		go c.Delete(blockNumber)
-		return time.Time{}, false
+	c.mu.Lock()
+	for number, header := range shared {
+		found[number] = header
+		c.putLocked(header)
 	}
+	c.mu.Unlock()
 
-	return cached.timestamp, true
+	return found
 }
 
-// Set stores a block timestamp in cache with TTL
-func (c *BlockHeaderCache) Set(blockNumber uint64, timestamp time.Time) {
+// SetMany caches headers in memory and in the shared store
+func (c *BlockHeaderCache) SetMany(ctx context.Context, headers []*models.BlockHeader) {
+	if len(headers) == 0 {
+		return
+	}
+
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	finalized := c.finalized
+	for _, header := range headers {
+		c.putLocked(header)
+	}
+	c.mu.Unlock()
+
+	if c.store == nil {
+		return
+	}
 
-	c.cache[blockNumber] = cachedHeader{
-		timestamp: timestamp,
-		expiresAt: time.Now().Add(c.ttl),
+	final := make([]*models.BlockHeader, 0, len(headers))
+	recent := make([]*models.BlockHeader, 0, len(headers))
+	for _, header := range headers {
+		if header.Number <= finalized {
+			final = append(final, header)
+		} else {
+			recent = append(recent, header)
+		}
 	}
+	// The store is a best-effort shared tier; memory already holds the headers
+	_ = c.store.SetBlockHeaders(ctx, final, 0)
+	_ = c.store.SetBlockHeaders(ctx, recent, c.ttl)
 }
 
-// Delete removes a block from cache
-func (c *BlockHeaderCache) Delete(blockNumber uint64) {
+// SetFinalized marks headers up to blockNumber as final, so they are kept until evicted
+func (c *BlockHeaderCache) SetFinalized(blockNumber uint64) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	delete(c.cache, blockNumber)
+	if blockNumber > c.finalized {
+		c.finalized = blockNumber
+	}
 }
 
-// Clear removes all entries (useful for testing or memory management)
-func (c *BlockHeaderCache) Clear() {
+// Invalidate drops the headers of blocks fromBlock..toBlock after a reorg replaced them
+func (c *BlockHeaderCache) Invalidate(ctx context.Context, fromBlock, toBlock uint64) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.cache = make(map[uint64]cachedHeader)
+	for number, elem := range c.entries {
+		if number >= fromBlock && number <= toBlock {
+			c.order.Remove(elem)
+			delete(c.entries, number)
+		}
+	}
+	c.mu.Unlock()
+
+	if c.store != nil {
+		_ = c.store.DeleteBlockHeaders(ctx, fromBlock, toBlock)
+	}
 }
 
-// Size returns the number of cached entries (for monitoring)
+// Size returns the number of headers held in memory (for monitoring)
 func (c *BlockHeaderCache) Size() int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return len(c.cache)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// getLocked returns an unexpired header and marks it recently used; caller must hold c.mu
+func (c *BlockHeaderCache) getLocked(number uint64, now time.Time) (*models.BlockHeader, bool) {
+	elem, ok := c.entries[number]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*cachedHeader)
+	if number > c.finalized && now.After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, number)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.header, true
+}
+
+// putLocked inserts or refreshes a header, evicting the least recently used beyond size; caller must hold c.mu
+func (c *BlockHeaderCache) putLocked(header *models.BlockHeader) {
+	entry := &cachedHeader{header: header, expiresAt: time.Now().Add(c.ttl)}
+	if elem, ok := c.entries[header.Number]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[header.Number] = c.order.PushFront(entry)
+	for c.size > 0 && c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedHeader).header.Number)
+		metrics.HeaderCacheEvictionsTotal.WithLabelValues(c.chain).Inc()
+	}
+}
+
+// cacheableHeader keeps the fields of a header the cache stores
+func cacheableHeader(header *types.Header) *models.BlockHeader {
+	return &models.BlockHeader{
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash().Hex(),
+		ParentHash: header.ParentHash.Hex(),
+		Timestamp:  header.Time,
+	}
 }
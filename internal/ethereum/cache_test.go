@@ -0,0 +1,161 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"pagrin/internal/models"
+)
+
+// headerStore is an in-memory shared tier recording what the cache writes to it
+type headerStore struct {
+	headers map[uint64]*models.BlockHeader
+	ttls    map[uint64]time.Duration
+	lookups int
+	deleted [][2]uint64
+	err     error
+}
+
+func newHeaderStore() *headerStore {
+	return &headerStore{headers: make(map[uint64]*models.BlockHeader), ttls: make(map[uint64]time.Duration)}
+}
+
+func (s *headerStore) GetBlockHeaders(ctx context.Context, numbers []uint64) (map[uint64]*models.BlockHeader, error) {
+	s.lookups++
+	if s.err != nil {
+		return nil, s.err
+	}
+	found := make(map[uint64]*models.BlockHeader)
+	for _, number := range numbers {
+		if header, ok := s.headers[number]; ok {
+			found[number] = header
+		}
+	}
+	return found, nil
+}
+
+func (s *headerStore) SetBlockHeaders(ctx context.Context, headers []*models.BlockHeader, ttl time.Duration) error {
+	for _, header := range headers {
+		s.headers[header.Number] = header
+		s.ttls[header.Number] = ttl
+	}
+	return nil
+}
+
+func (s *headerStore) DeleteBlockHeaders(ctx context.Context, fromBlock, toBlock uint64) error {
+	s.deleted = append(s.deleted, [2]uint64{fromBlock, toBlock})
+	return nil
+}
+
+func testHeaders(numbers ...uint64) []*models.BlockHeader {
+	headers := make([]*models.BlockHeader, len(numbers))
+	for i, number := range numbers {
+		headers[i] = &models.BlockHeader{Number: number, Timestamp: 1700000000 + number}
+	}
+	return headers
+}
+
+// cachedNumbers returns the sorted block numbers found in the cache
+func cachedNumbers(cache *BlockHeaderCache, numbers ...uint64) []uint64 {
+	found := cache.GetMany(context.Background(), numbers)
+	cached := make([]uint64, 0, len(found))
+	for number := range found {
+		cached = append(cached, number)
+	}
+	sort.Slice(cached, func(i, j int) bool { return cached[i] < cached[j] })
+	return cached
+}
+
+func TestBlockHeaderCacheEviction(t *testing.T) {
+	cache := NewBlockHeaderCache(3, time.Hour, nil, "cache-test")
+	ctx := context.Background()
+
+	cache.SetMany(ctx, testHeaders(1, 2, 3))
+	cache.GetMany(ctx, []uint64{1}) // Block 2 becomes the least recently used
+	cache.SetMany(ctx, testHeaders(4))
+
+	if got := cachedNumbers(cache, 1, 2, 3, 4); len(got) != 3 || got[0] != 1 || got[1] != 3 || got[2] != 4 {
+		t.Errorf("cached blocks = %v, want [1 3 4]", got)
+	}
+	if cache.Size() != 3 {
+		t.Errorf("Size() = %d, want 3", cache.Size())
+	}
+}
+
+func TestBlockHeaderCacheExpiry(t *testing.T) {
+	cache := NewBlockHeaderCache(10, time.Millisecond, nil, "cache-test")
+	ctx := context.Background()
+
+	cache.SetFinalized(2)
+	cache.SetFinalized(1) // Finality never moves back
+	cache.SetMany(ctx, testHeaders(1, 2, 3))
+	time.Sleep(5 * time.Millisecond)
+
+	if got := cachedNumbers(cache, 1, 2, 3); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("cached blocks = %v, want only the finalized [1 2]", got)
+	}
+	if cache.Size() != 2 {
+		t.Errorf("Size() = %d, want the expired header dropped", cache.Size())
+	}
+}
+
+func TestBlockHeaderCacheStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("misses fall through to the store", func(t *testing.T) {
+		store := newHeaderStore()
+		_ = store.SetBlockHeaders(ctx, testHeaders(5, 6), time.Hour)
+		cache := NewBlockHeaderCache(10, time.Hour, store, "cache-test")
+
+		if got := cachedNumbers(cache, 5, 6, 7); len(got) != 2 {
+			t.Errorf("cached blocks = %v, want [5 6] from the store", got)
+		}
+		// Store hits are kept in memory
+		cachedNumbers(cache, 5, 6)
+		if store.lookups != 1 {
+			t.Errorf("store looked up %d times, want 1", store.lookups)
+		}
+	})
+
+	t.Run("store errors count as misses", func(t *testing.T) {
+		store := newHeaderStore()
+		store.err = errors.New("redis unavailable")
+		cache := NewBlockHeaderCache(10, time.Hour, store, "cache-test")
+		cache.SetMany(ctx, testHeaders(1))
+
+		if got := cachedNumbers(cache, 1, 2); len(got) != 1 || got[0] != 1 {
+			t.Errorf("cached blocks = %v, want [1] from memory", got)
+		}
+	})
+
+	t.Run("finalized headers never expire in the store", func(t *testing.T) {
+		store := newHeaderStore()
+		cache := NewBlockHeaderCache(10, time.Minute, store, "cache-test")
+		cache.SetFinalized(10)
+		cache.SetMany(ctx, testHeaders(9, 10, 11))
+
+		for number, want := range map[uint64]time.Duration{9: 0, 10: 0, 11: time.Minute} {
+			if ttl := store.ttls[number]; ttl != want {
+				t.Errorf("block %d stored with ttl %s, want %s", number, ttl, want)
+			}
+		}
+	})
+
+	t.Run("invalidate drops a reorged range everywhere", func(t *testing.T) {
+		store := newHeaderStore()
+		cache := NewBlockHeaderCache(10, time.Hour, store, "cache-test")
+		cache.SetMany(ctx, testHeaders(1, 2, 3, 4))
+		cache.Invalidate(ctx, 2, 3)
+
+		store.headers = map[uint64]*models.BlockHeader{} // Only memory is checked below
+		if got := cachedNumbers(cache, 1, 2, 3, 4); len(got) != 2 || got[0] != 1 || got[1] != 4 {
+			t.Errorf("cached blocks = %v, want [1 4]", got)
+		}
+		if len(store.deleted) != 1 || store.deleted[0] != [2]uint64{2, 3} {
+			t.Errorf("store deletes = %v, want [[2 3]]", store.deleted)
+		}
+	})
+}
@@ -2,18 +2,46 @@ package ethereum
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// BlockTag identifies a named block on the chain (latest, safe or finalized)
+type BlockTag string
+
+const (
+	BlockTagLatest    BlockTag = "latest"
+	BlockTagSafe      BlockTag = "safe"
+	BlockTagFinalized BlockTag = "finalized"
+)
+
+// blockNumberArg converts a tag to the number argument understood by ethclient
+// nil means latest; safe and finalized use the negative sentinels from the rpc package
+func (t BlockTag) blockNumberArg() *big.Int {
+	switch t {
+	case BlockTagSafe:
+		return big.NewInt(int64(rpc.SafeBlockNumber))
+	case BlockTagFinalized:
+		return big.NewInt(int64(rpc.FinalizedBlockNumber))
+	default:
+		return nil
+	}
+}
+
 // Client wraps either a single ethclient or a ProviderPool
 // Provides backward compatibility while supporting multi-provider failover
 type Client struct {
 	client  *ethclient.Client // Single client (legacy mode)
 	pool    *ProviderPool     // Provider pool (new mode)
 	usePool bool              // Whether to use pool or single client
+	traces  traceSupport      // Trace API of the single client, detected on first use
+
+	websocket bool // Single client dialled over ws, so it can push new heads
 }
 
 // NewClient creates a client from a single RPC URL (legacy mode)
@@ -25,8 +53,9 @@ func NewClient(rpcURL string) (*Client, error) {
 	}
 
 	return &Client{
-		client:  client,
-		usePool: false,
+		client:    client,
+		usePool:   false,
+		websocket: isWebsocketURL(rpcURL),
 	}, nil
 }
 
@@ -61,6 +90,32 @@ func (c *Client) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
 	return header.Number.Uint64(), nil
 }
 
+// GetBlockNumberByTag retrieves the number of the block identified by tag
+// Safe and finalized tags require a post-merge node; pre-merge providers return an error
+func (c *Client) GetBlockNumberByTag(ctx context.Context, tag BlockTag) (uint64, error) {
+	if tag == BlockTagLatest || tag == "" {
+		return c.GetLatestBlockNumber(ctx)
+	}
+
+	if c.usePool && c.pool != nil {
+		header, err := c.pool.HeaderByNumber(ctx, tag.blockNumberArg())
+		if err != nil {
+			return 0, fmt.Errorf("failed to get %s block: %w", tag, err)
+		}
+		return header.Number.Uint64(), nil
+	}
+
+	if c.client == nil {
+		return 0, fmt.Errorf("no client or pool available")
+	}
+
+	header, err := c.client.HeaderByNumber(ctx, tag.blockNumberArg())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s block: %w", tag, err)
+	}
+	return header.Number.Uint64(), nil
+}
+
 // GetBlockNumber retrieves the latest block number as *big.Int
 func (c *Client) GetBlockNumber(ctx context.Context) (*big.Int, error) {
 	if c.usePool && c.pool != nil {
@@ -82,6 +137,96 @@ func (c *Client) GetBlockNumber(ctx context.Context) (*big.Int, error) {
 	return header.Number, nil
 }
 
+// GetBlockHeader retrieves the header for a given block number
+// Used for hash/parent-hash checks during reorg detection
+func (c *Client) GetBlockHeader(ctx context.Context, blockNumber uint64) (*types.Header, error) {
+	number := new(big.Int).SetUint64(blockNumber)
+
+	if c.usePool && c.pool != nil {
+		header, err := c.pool.HeaderByNumber(ctx, number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get header for block %d: %w", blockNumber, err)
+		}
+		return header, nil
+	}
+
+	if c.client == nil {
+		return nil, fmt.Errorf("no client or pool available")
+	}
+
+	header, err := c.client.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header for block %d: %w", blockNumber, err)
+	}
+	return header, nil
+}
+
+// GetBlockReceipts retrieves all receipts of a block in one eth_getBlockReceipts call
+func (c *Client) GetBlockReceipts(ctx context.Context, blockNumber uint64) (types.Receipts, error) {
+	number := new(big.Int).SetUint64(blockNumber)
+
+	if c.usePool && c.pool != nil {
+		receipts, err := c.pool.BlockReceipts(ctx, number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get receipts for block %d: %w", blockNumber, err)
+		}
+		return receipts, nil
+	}
+
+	if c.client == nil {
+		return nil, fmt.Errorf("no client or pool available")
+	}
+
+	receipts, err := c.client.BlockReceipts(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(blockNumber)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipts for block %d: %w", blockNumber, err)
+	}
+	return receipts, nil
+}
+
+// TraceBlock retrieves the value-bearing internal calls of a block
+// Uses debug_traceBlockByNumber (callTracer) or trace_block, whichever the endpoint supports
+// Returns ErrTraceUnsupported if no endpoint supports either
+func (c *Client) TraceBlock(ctx context.Context, blockNumber uint64) ([]InternalCall, error) {
+	if c.usePool && c.pool != nil {
+		calls, err := c.pool.TraceBlock(ctx, blockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to trace block %d: %w", blockNumber, err)
+		}
+		return calls, nil
+	}
+
+	if c.client == nil {
+		return nil, fmt.Errorf("no client or pool available")
+	}
+
+	calls, err := traceBlock(ctx, c.client.Client(), &c.traces, blockNumber)
+	if errors.Is(err, errMethodUnsupported) {
+		err = ErrTraceUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace block %d: %w", blockNumber, err)
+	}
+	return calls, nil
+}
+
+// ChainID retrieves the chain ID reported by the endpoint (eth_chainId)
+func (c *Client) ChainID(ctx context.Context) (uint64, error) {
+	if c.usePool && c.pool != nil {
+		return c.pool.ChainID(ctx)
+	}
+
+	if c.client == nil {
+		return 0, fmt.Errorf("no client or pool available")
+	}
+
+	chainID, err := c.client.ChainID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	return chainID.Uint64(), nil
+}
+
 // GetClient returns the underlying ethclient (legacy support)
 // Returns nil if using pool mode
 func (c *Client) GetClient() *ethclient.Client {
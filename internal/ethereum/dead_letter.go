@@ -0,0 +1,100 @@
+package ethereum
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"pagrin/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// eventNames maps indexed event signatures to their event_signature names
+var eventNames = map[common.Hash]string{
+	ERC20TransferEventSignature:         EventSignatureTransfer,
+	ERC1155TransferSingleEventSignature: EventSignatureTransferSingle,
+	ERC1155TransferBatchEventSignature:  EventSignatureTransferBatch,
+	ERC20ApprovalEventSignature:         EventSignatureApproval,
+}
+
+// ParseDeadLetter decodes a dead letter's stored log again, e.g. after a parser fix
+// The result carries a new dead letter if the log is still rejected or degraded
+// Logs of tokens the filter no longer allows decode to an empty result
+func (f *Fetcher) ParseDeadLetter(letter *models.DeadLetter) (*FetchResult, error) {
+	log, err := deadLetterLog(letter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FetchResult{}
+	if f.filter.Allows(log.Address, log.BlockNumber) {
+		f.parseLog(log, letter.Timestamp, result)
+	}
+	return result, nil
+}
+
+// addTransfers appends transfers decoded from log, recording a degraded dead letter when a value overflowed
+func (r *FetchResult) addTransfers(log types.Log, timestamp time.Time, transfers ...*models.Transfer) {
+	r.Transfers = append(r.Transfers, transfers...)
+	for _, transfer := range transfers {
+		if transfer.ValueOverflow {
+			reason := fmt.Sprintf("value %s exceeds Decimal128 precision, stored as zero", transfer.ValueString)
+			r.DeadLetters = append(r.DeadLetters, newDeadLetter(log, timestamp, models.DeadLetterDegraded, reason))
+			return
+		}
+	}
+}
+
+func newDeadLetter(log types.Log, timestamp time.Time, kind, reason string) *models.DeadLetter {
+	topics := make([]string, len(log.Topics))
+	for i, topic := range log.Topics {
+		topics[i] = topic.Hex()
+	}
+
+	event, ok := eventNames[log.Topics[0]]
+	if !ok {
+		event = log.Topics[0].Hex()
+	}
+
+	return &models.DeadLetter{
+		Kind:        kind,
+		Event:       event,
+		Reason:      reason,
+		Address:     strings.ToLower(log.Address.Hex()),
+		Topics:      topics,
+		Data:        hexutil.Encode(log.Data),
+		BlockNumber: log.BlockNumber,
+		BlockHash:   log.BlockHash.Hex(),
+		TxHash:      log.TxHash.Hex(),
+		TxIndex:     log.TxIndex,
+		LogIndex:    log.Index,
+		Timestamp:   timestamp,
+	}
+}
+
+// deadLetterLog rebuilds the raw log a dead letter was recorded from
+func deadLetterLog(letter *models.DeadLetter) (types.Log, error) {
+	data, err := hexutil.Decode(letter.Data)
+	if err != nil {
+		return types.Log{}, fmt.Errorf("invalid data in dead letter %s: %w", letter.ID.Hex(), err)
+	}
+
+	topics := make([]common.Hash, len(letter.Topics))
+	for i, topic := range letter.Topics {
+		topics[i] = common.HexToHash(topic)
+	}
+
+	return types.Log{
+		Address:     common.HexToAddress(letter.Address),
+		Topics:      topics,
+		Data:        data,
+		BlockNumber: letter.BlockNumber,
+		BlockHash:   common.HexToHash(letter.BlockHash),
+		TxHash:      common.HexToHash(letter.TxHash),
+		TxIndex:     letter.TxIndex,
+		Index:       letter.LogIndex,
+	}, nil
+}
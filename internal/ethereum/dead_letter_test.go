@@ -0,0 +1,138 @@
+package ethereum
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"pagrin/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDeadLetterRoundTrip(t *testing.T) {
+	token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	log := types.Log{
+		Address:     token,
+		Topics:      []common.Hash{ERC20TransferEventSignature, addressTopic(alice)}, // Missing the recipient topic
+		Data:        abiWords(big.NewInt(1000)),
+		BlockNumber: 19_000_000,
+		BlockHash:   common.HexToHash("0xb1"),
+		TxHash:      common.HexToHash("0x7a1"),
+		TxIndex:     4,
+		Index:       17,
+	}
+	timestamp := time.Unix(1_700_000_000, 0)
+
+	result := &FetchResult{}
+	(&Fetcher{}).parseLog(log, timestamp, result)
+	if len(result.Transfers) != 0 || len(result.DeadLetters) != 1 {
+		t.Fatalf("parseLog() = %d transfers, %d dead letters, want 0, 1", len(result.Transfers), len(result.DeadLetters))
+	}
+
+	letter := result.DeadLetters[0]
+	if letter.Kind != models.DeadLetterRejected || letter.Event != EventSignatureTransfer {
+		t.Errorf("dead letter = %s %s, want rejected Transfer", letter.Kind, letter.Event)
+	}
+	if letter.Address != "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48" {
+		t.Errorf("dead letter address = %s, want the lowercase token", letter.Address)
+	}
+	if !letter.Timestamp.Equal(timestamp) {
+		t.Errorf("dead letter timestamp = %v, want %v", letter.Timestamp, timestamp)
+	}
+
+	rebuilt, err := deadLetterLog(letter)
+	if err != nil {
+		t.Fatalf("deadLetterLog() error = %v", err)
+	}
+	if !reflect.DeepEqual(rebuilt, log) {
+		t.Errorf("deadLetterLog() = %+v, want %+v", rebuilt, log)
+	}
+}
+
+func TestDeadLetterUnknownEvent(t *testing.T) {
+	signature := common.HexToHash("0xdeadbeef")
+	letter := newDeadLetter(types.Log{Topics: []common.Hash{signature}}, time.Time{}, models.DeadLetterRejected, "unknown")
+	if letter.Event != signature.Hex() {
+		t.Errorf("newDeadLetter() event = %s, want the raw signature %s", letter.Event, signature.Hex())
+	}
+}
+
+func TestParseDeadLetter(t *testing.T) {
+	token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	huge := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(1)) // Beyond Decimal128 precision
+
+	letterFor := func(topics []common.Hash, value *big.Int) *models.DeadLetter {
+		return newDeadLetter(types.Log{
+			Address:     token,
+			Topics:      topics,
+			Data:        abiWords(value),
+			BlockNumber: 100,
+		}, time.Unix(1_700_000_000, 0), models.DeadLetterRejected, "rejected by an older parser")
+	}
+	transferTopics := []common.Hash{ERC20TransferEventSignature, addressTopic(alice), addressTopic(bob)}
+
+	tests := []struct {
+		name            string
+		letter          *models.DeadLetter
+		filter          *TokenFilter
+		wantErr         bool
+		wantTransfers   int
+		wantDeadLetters []string // Kinds of the dead letters recorded again
+	}{
+		{
+			name:          "decodes cleanly now",
+			letter:        letterFor(transferTopics, big.NewInt(1000)),
+			wantTransfers: 1,
+		},
+		{
+			name:            "still rejected",
+			letter:          letterFor(transferTopics[:2], big.NewInt(1000)),
+			wantDeadLetters: []string{models.DeadLetterRejected},
+		},
+		{
+			name:            "still degraded",
+			letter:          letterFor(transferTopics, huge),
+			wantTransfers:   1,
+			wantDeadLetters: []string{models.DeadLetterDegraded},
+		},
+		{
+			name:   "token denied since",
+			letter: letterFor(transferTopics, big.NewInt(1000)),
+			filter: NewTokenFilter(nil, []TokenRule{{Address: token}}),
+		},
+		{
+			name:    "corrupt data",
+			letter:  &models.DeadLetter{Data: "0xzz", Topics: []string{ERC20TransferEventSignature.Hex()}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Fetcher{filter: tt.filter}
+			result, err := f.ParseDeadLetter(tt.letter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDeadLetter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(result.Transfers) != tt.wantTransfers {
+				t.Errorf("ParseDeadLetter() = %d transfers, want %d", len(result.Transfers), tt.wantTransfers)
+			}
+			var kinds []string
+			for _, letter := range result.DeadLetters {
+				kinds = append(kinds, letter.Kind)
+			}
+			if !reflect.DeepEqual(kinds, tt.wantDeadLetters) {
+				t.Errorf("ParseDeadLetter() dead letters = %v, want %v", kinds, tt.wantDeadLetters)
+			}
+		})
+	}
+}
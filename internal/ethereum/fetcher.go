@@ -14,69 +14,176 @@ import (
 )
 
 type Fetcher struct {
-	client *Client
-	cache  *BlockHeaderCache // In-memory cache for block timestamps
+	client            *Client
+	cache             *BlockHeaderCache // LRU cache of block headers, optionally shared through Redis
+	filter            *TokenFilter      // Optional token allow/deny lists (nil = index everything)
+	nativeTransfers   bool              // Walk block transactions for plain ETH transfers
+	internalTransfers bool              // Trace blocks for ETH moved by contract calls
 }
 
-// NewFetcher creates a new fetcher with optional block header cache
-// Cache TTL defaults to 5 minutes (covers typical batch processing windows)
-func NewFetcher(client *Client) *Fetcher {
+// NewFetcher creates a new fetcher looking up block timestamps through the given header cache
+// filter may be nil to index every token contract
+func NewFetcher(client *Client, cache *BlockHeaderCache, filter *TokenFilter, nativeTransfers, internalTransfers bool) *Fetcher {
 	return &Fetcher{
-		client: client,
-		cache:  NewBlockHeaderCache(5 * time.Minute), // 5 minute TTL
+		client:            client,
+		cache:             cache,
+		filter:            filter,
+		nativeTransfers:   nativeTransfers,
+		internalTransfers: internalTransfers,
 	}
 }
 
-// FetchTransferLogs fetches Transfer event logs for a given block range
-func (f *Fetcher) FetchTransferLogs(ctx context.Context, fromBlock, toBlock uint64) ([]*models.Transfer, error) {
-	query := eth.FilterQuery{
-		FromBlock: new(big.Int).SetUint64(fromBlock),
-		ToBlock:   new(big.Int).SetUint64(toBlock),
-		Topics: [][]common.Hash{
-			{ERC20TransferEventSignature},
-		},
+// FetchResult holds the events decoded from a block range, grouped by model
+type FetchResult struct {
+	Transfers    []*models.Transfer    // ERC-20, ERC-1155, native and internal ETH transfers
+	NFTTransfers []*models.NFTTransfer // ERC-721 Transfer events
+	Approvals    []*models.Approval    // ERC-20 Approval events
+	DeadLetters  []*models.DeadLetter  // Logs rejected by the parsers or stored with degraded values
+}
+
+// RawEvents holds the logs and blocks of a range before they are decoded
+type RawEvents struct {
+	FromBlock uint64
+	ToBlock   uint64
+
+	logs       []types.Log
+	timestamps map[uint64]time.Time
+	blocks     map[uint64]*types.Block // Full blocks, only with native or internal transfers
+	native     bool
+	internal   bool
+}
+
+// LogCount returns the number of fetched logs, before token filtering
+func (r *RawEvents) LogCount() int {
+	return len(r.logs)
+}
+
+// Len returns the total number of decoded events
+func (r *FetchResult) Len() int {
+	return len(r.Transfers) + len(r.NFTTransfers) + len(r.Approvals)
+}
+
+// FetchEvents fetches and decodes Transfer and Approval event logs for a given block range
+// The allowlist (if any) is pushed down into the query's address filter
+// Native and internal ETH transfers are extracted from the range's blocks when enabled
+func (f *Fetcher) FetchEvents(ctx context.Context, fromBlock, toBlock uint64) (*FetchResult, error) {
+	raw, err := f.FetchRawEvents(ctx, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
 	}
+	return f.ParseEvents(ctx, raw)
+}
 
-	// Use pool if available (supports failover), otherwise use single client
-	var logs []types.Log
-	var err error
-	if pool := f.client.GetPool(); pool != nil {
-		logs, err = pool.FilterLogs(ctx, query)
-	} else {
-		logs, err = f.client.GetClient().FilterLogs(ctx, query)
+// FetchRawEvents fetches the logs and block timestamps of a range without decoding them
+// Lets ingestion fetch one batch while the previous one is decoded and stored; see ParseEvents
+func (f *Fetcher) FetchRawEvents(ctx context.Context, fromBlock, toBlock uint64) (*RawEvents, error) {
+	addresses := f.filter.Addresses(fromBlock, toBlock)
+	// Allowlist configured but no token has started yet - an empty address list would match everything
+	queryLogs := addresses == nil || len(addresses) > 0
+	return f.fetchRawEvents(ctx, fromBlock, toBlock, addresses, queryLogs, f.nativeTransfers, f.internalTransfers)
+}
+
+// FetchEventsForTokens fetches and decodes Transfer and Approval event logs of specific token contracts
+// Used by backfills for newly allowlisted tokens; allow/deny rules still apply to the results
+func (f *Fetcher) FetchEventsForTokens(ctx context.Context, fromBlock, toBlock uint64, tokens []common.Address) (*FetchResult, error) {
+	if len(tokens) == 0 {
+		return &FetchResult{}, nil
 	}
+	raw, err := f.fetchRawEvents(ctx, fromBlock, toBlock, tokens, true, false, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to filter logs: %w", err)
+		return nil, err
+	}
+	return f.ParseEvents(ctx, raw)
+}
+
+func (f *Fetcher) fetchRawEvents(ctx context.Context, fromBlock, toBlock uint64, addresses []common.Address, queryLogs, native, internal bool) (*RawEvents, error) {
+	var logs []types.Log
+	if queryLogs {
+		query := eth.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(fromBlock),
+			ToBlock:   new(big.Int).SetUint64(toBlock),
+			Addresses: addresses,
+			Topics: [][]common.Hash{
+				{ERC20TransferEventSignature, ERC1155TransferSingleEventSignature, ERC1155TransferBatchEventSignature, ERC20ApprovalEventSignature},
+			},
+		}
+
+		// Use pool if available (supports failover), otherwise use single client
+		var err error
+		if pool := f.client.GetPool(); pool != nil {
+			logs, err = pool.FilterLogs(ctx, query)
+		} else {
+			logs, err = f.client.GetClient().FilterLogs(ctx, query)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter logs: %w", err)
+		}
 	}
 
-	if len(logs) == 0 {
-		return nil, nil
+	// Native and internal transfers need every block of the range, with its transactions
+	allBlocks := native || internal
+	raw := &RawEvents{FromBlock: fromBlock, ToBlock: toBlock, logs: logs, native: native, internal: internal}
+	if len(logs) == 0 && !allBlocks {
+		return raw, nil
 	}
 
 	// Cache block timestamps to avoid fetching the same block multiple times
 	blockTimestamps := make(map[uint64]time.Time)
+	// Full blocks, kept only when native or internal transfers are extracted from them
+	fullBlocks := make(map[uint64]*types.Block)
 
 	// Fetch unique block timestamps, checking cache first
 	uniqueBlocks := make(map[uint64]bool)
 	blocksToFetch := make([]uint64, 0) // Blocks not in cache
 
+	if allBlocks {
+		// Every block's transactions are needed, so the timestamp cache cannot save calls
+		for bn := fromBlock; bn <= toBlock; bn++ {
+			uniqueBlocks[bn] = true
+			blocksToFetch = append(blocksToFetch, bn)
+		}
+	}
+
+	logBlocks := make([]uint64, 0)
 	for _, log := range logs {
 		if !uniqueBlocks[log.BlockNumber] {
 			uniqueBlocks[log.BlockNumber] = true
-			// Check cache first
-			if timestamp, found := f.cache.Get(log.BlockNumber); found {
-				blockTimestamps[log.BlockNumber] = timestamp
+			logBlocks = append(logBlocks, log.BlockNumber)
+		}
+	}
+
+	// Check the header cache before fetching headers for log timestamps
+	if !allBlocks {
+		cached := f.cache.GetMany(ctx, logBlocks)
+		for _, bn := range logBlocks {
+			if header, found := cached[bn]; found {
+				blockTimestamps[bn] = time.Unix(int64(header.Timestamp), 0)
 			} else {
-				blocksToFetch = append(blocksToFetch, log.BlockNumber)
+				blocksToFetch = append(blocksToFetch, bn)
 			}
 		}
 	}
 
-	// Only fetch blocks that aren't in cache
-	if len(blocksToFetch) > 0 {
+	// Timestamps only need headers, fetched in JSON-RPC batches
+	if len(blocksToFetch) > 0 && !allBlocks {
+		headers, err := f.client.GetBlockHeaders(ctx, blocksToFetch)
+		if err != nil {
+			return nil, err
+		}
+		fetched := make([]*models.BlockHeader, 0, len(headers))
+		for bn, header := range headers {
+			blockTimestamps[bn] = time.Unix(int64(header.Time), 0)
+			fetched = append(fetched, cacheableHeader(header))
+		}
+		f.cache.SetMany(ctx, fetched)
+	}
+
+	// Native and internal transfers need the full blocks, with their transactions
+	if len(blocksToFetch) > 0 && allBlocks {
 		// Fetch block headers in parallel (but limit concurrency)
 		type blockResult struct {
 			blockNum  uint64
+			block     *types.Block
 			timestamp time.Time
 			err       error
 		}
@@ -110,43 +217,135 @@ func (f *Fetcher) FetchTransferLogs(ctx context.Context, fromBlock, toBlock uint
 				}
 
 				timestamp := time.Unix(int64(block.Time()), 0)
-				// Store in cache for future use
-				f.cache.Set(bn, timestamp)
 
 				blockChan <- blockResult{
 					blockNum:  bn,
+					block:     block,
 					timestamp: timestamp,
 				}
 			}()
 		}
 
 		// Collect all block timestamps
+		fetched := make([]*models.BlockHeader, 0, len(blocksToFetch))
 		for i := 0; i < len(blocksToFetch); i++ {
 			result := <-blockChan
 			if result.err != nil {
 				return nil, fmt.Errorf("failed to get block %d: %w", result.blockNum, result.err)
 			}
 			blockTimestamps[result.blockNum] = result.timestamp
+			fullBlocks[result.blockNum] = result.block
+			fetched = append(fetched, cacheableHeader(result.block.Header()))
 		}
+		// Store in cache for future use, e.g. other replicas or token backfills of the same range
+		f.cache.SetMany(ctx, fetched)
 	}
 
-	// Parse all logs using cached timestamps
-	transfers := make([]*models.Transfer, 0, len(logs))
-	for _, log := range logs {
-		timestamp, ok := blockTimestamps[log.BlockNumber]
+	raw.timestamps = blockTimestamps
+	raw.blocks = fullBlocks
+	return raw, nil
+}
+
+// ParseEvents decodes the logs of a raw range and extracts its native and internal ETH transfers
+// Native and internal transfers still call the provider for receipts and traces of each block
+func (f *Fetcher) ParseEvents(ctx context.Context, raw *RawEvents) (*FetchResult, error) {
+	// Parse all logs using fetched timestamps
+	result := &FetchResult{}
+	for _, log := range raw.logs {
+		if !f.filter.Allows(log.Address, log.BlockNumber) {
+			continue
+		}
+
+		timestamp, ok := raw.timestamps[log.BlockNumber]
 		if !ok {
 			return nil, fmt.Errorf("missing timestamp for block %d", log.BlockNumber)
 		}
 
-		transfer, err := ParseTransferLog(log, timestamp)
-		if err != nil {
-			continue
+		f.parseLog(log, timestamp, result)
+	}
+
+	if raw.native {
+		for bn := raw.FromBlock; bn <= raw.ToBlock; bn++ {
+			transfers, err := f.fetchNativeTransfers(ctx, raw.blocks[bn])
+			if err != nil {
+				return nil, err
+			}
+			result.Transfers = append(result.Transfers, transfers...)
 		}
+	}
 
-		transfers = append(transfers, transfer)
+	if raw.internal {
+		for bn := raw.FromBlock; bn <= raw.ToBlock; bn++ {
+			transfers, err := f.fetchInternalTransfers(ctx, raw.blocks[bn])
+			if err != nil {
+				return nil, err
+			}
+			result.Transfers = append(result.Transfers, transfers...)
+		}
 	}
 
-	return transfers, nil
+	return result, nil
+}
+
+// parseLog decodes a log into the matching model and appends it to result
+// ERC-20 and ERC-721 Transfers share a signature and are told apart by topic count
+// Logs that fail to decode (non-standard tokens) are skipped and recorded as dead letters
+func (f *Fetcher) parseLog(log types.Log, timestamp time.Time, result *FetchResult) {
+	if len(log.Topics) == 0 {
+		return
+	}
+
+	var err error
+	switch log.Topics[0] {
+	case ERC20TransferEventSignature:
+		switch len(log.Topics) {
+		case 3:
+			var transfer *models.Transfer
+			if transfer, err = ParseTransferLog(log, timestamp); err == nil {
+				result.addTransfers(log, timestamp, transfer)
+			}
+		case 4:
+			var transfer *models.NFTTransfer
+			if transfer, err = ParseNFTTransferLog(log, timestamp); err == nil {
+				result.NFTTransfers = append(result.NFTTransfers, transfer)
+			}
+		default:
+			err = fmt.Errorf("invalid Transfer event: expected 3 or 4 topics, got %d", len(log.Topics))
+		}
+	case ERC1155TransferSingleEventSignature:
+		var transfer *models.Transfer
+		if transfer, err = ParseTransferSingleLog(log, timestamp); err == nil {
+			result.addTransfers(log, timestamp, transfer)
+		}
+	case ERC1155TransferBatchEventSignature:
+		var transfers []*models.Transfer
+		if transfers, err = ParseTransferBatchLog(log, timestamp); err == nil {
+			result.addTransfers(log, timestamp, transfers...)
+		}
+	case ERC20ApprovalEventSignature:
+		// ERC-721 Approval shares the signature but is not indexed
+		if len(log.Topics) == 4 {
+			return
+		}
+		var approval *models.Approval
+		if approval, err = ParseApprovalLog(log, timestamp); err == nil {
+			result.Approvals = append(result.Approvals, approval)
+		}
+	}
+
+	if err != nil {
+		result.DeadLetters = append(result.DeadLetters, newDeadLetter(log, timestamp, models.DeadLetterRejected, err.Error()))
+	}
+}
+
+// SetFinalizedBlock lets the header cache keep headers up to blockNumber indefinitely
+func (f *Fetcher) SetFinalizedBlock(blockNumber uint64) {
+	f.cache.SetFinalized(blockNumber)
+}
+
+// InvalidateBlocks drops cached headers of blocks fromBlock..toBlock replaced by a reorg
+func (f *Fetcher) InvalidateBlocks(ctx context.Context, fromBlock, toBlock uint64) {
+	f.cache.Invalidate(ctx, fromBlock, toBlock)
 }
 
 // GetBlockTimestamp retrieves the timestamp for a given block number
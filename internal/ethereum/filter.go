@@ -0,0 +1,78 @@
+package ethereum
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenRule applies an allow/deny entry to a token contract from StartBlock onwards
+type TokenRule struct {
+	Address    common.Address
+	StartBlock uint64
+}
+
+// TokenFilter restricts which token contracts are indexed
+// Allowlist entries are pushed down into eth_getLogs address filters;
+// denylist entries are applied to parsed transfers
+type TokenFilter struct {
+	allow map[common.Address]uint64
+	deny  map[common.Address]uint64
+}
+
+// NewTokenFilter creates a filter from allow and deny rules
+// An empty allowlist means every token is indexed unless denied
+func NewTokenFilter(allow, deny []TokenRule) *TokenFilter {
+	f := &TokenFilter{
+		allow: make(map[common.Address]uint64, len(allow)),
+		deny:  make(map[common.Address]uint64, len(deny)),
+	}
+	for _, rule := range allow {
+		f.allow[rule.Address] = rule.StartBlock
+	}
+	for _, rule := range deny {
+		f.deny[rule.Address] = rule.StartBlock
+	}
+	return f
+}
+
+// HasAllowlist reports whether indexing is restricted to an allowlist
+func (f *TokenFilter) HasAllowlist() bool {
+	return f != nil && len(f.allow) > 0
+}
+
+// Addresses returns the allowlisted tokens active anywhere in [fromBlock, toBlock]
+// Returns nil when there is no allowlist (query all contracts) and an empty slice
+// when an allowlist exists but no entry has started yet (nothing to query)
+func (f *TokenFilter) Addresses(fromBlock, toBlock uint64) []common.Address {
+	if !f.HasAllowlist() {
+		return nil
+	}
+
+	addresses := make([]common.Address, 0, len(f.allow))
+	for address, startBlock := range f.allow {
+		if startBlock <= toBlock {
+			addresses = append(addresses, address)
+		}
+	}
+	// Deterministic order keeps provider-side query caching effective
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].Cmp(addresses[j]) < 0
+	})
+	return addresses
+}
+
+// Allows reports whether a transfer of token at blockNumber should be indexed
+func (f *TokenFilter) Allows(token common.Address, blockNumber uint64) bool {
+	if f == nil {
+		return true
+	}
+	if startBlock, denied := f.deny[token]; denied && blockNumber >= startBlock {
+		return false
+	}
+	if len(f.allow) > 0 {
+		startBlock, allowed := f.allow[token]
+		return allowed && blockNumber >= startBlock
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package ethereum
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	usdc = common.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+	weth = common.HexToAddress("0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2")
+	dai  = common.HexToAddress("0x6b175474e89094c44da98b954eedeac495271d0f")
+)
+
+func TestTokenFilterAllows(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *TokenFilter
+		token  common.Address
+		block  uint64
+		want   bool
+	}{
+		{name: "nil filter", filter: nil, token: usdc, block: 1, want: true},
+		{name: "no rules", filter: NewTokenFilter(nil, nil), token: usdc, block: 1, want: true},
+		{name: "allowlisted", filter: NewTokenFilter([]TokenRule{{Address: usdc}}, nil), token: usdc, block: 1, want: true},
+		{name: "not allowlisted", filter: NewTokenFilter([]TokenRule{{Address: usdc}}, nil), token: weth, block: 1, want: false},
+		{name: "before allow start", filter: NewTokenFilter([]TokenRule{{Address: usdc, StartBlock: 100}}, nil), token: usdc, block: 99, want: false},
+		{name: "at allow start", filter: NewTokenFilter([]TokenRule{{Address: usdc, StartBlock: 100}}, nil), token: usdc, block: 100, want: true},
+		{name: "denied", filter: NewTokenFilter(nil, []TokenRule{{Address: dai}}), token: dai, block: 1, want: false},
+		{name: "before deny start", filter: NewTokenFilter(nil, []TokenRule{{Address: dai, StartBlock: 100}}), token: dai, block: 99, want: true},
+		{name: "deny overrides allow", filter: NewTokenFilter([]TokenRule{{Address: dai}}, []TokenRule{{Address: dai, StartBlock: 50}}), token: dai, block: 50, want: false},
+		{name: "other tokens unaffected by deny", filter: NewTokenFilter(nil, []TokenRule{{Address: dai}}), token: usdc, block: 1, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Allows(tt.token, tt.block); got != tt.want {
+				t.Errorf("Allows(%s, %d) = %v, want %v", tt.token.Hex(), tt.block, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenFilterAddresses(t *testing.T) {
+	filter := NewTokenFilter([]TokenRule{{Address: weth}, {Address: usdc, StartBlock: 200}, {Address: dai, StartBlock: 100}}, nil)
+
+	tests := []struct {
+		name      string
+		filter    *TokenFilter
+		fromBlock uint64
+		toBlock   uint64
+		want      []common.Address
+	}{
+		{name: "no allowlist queries every contract", filter: NewTokenFilter(nil, []TokenRule{{Address: dai}}), fromBlock: 0, toBlock: 10, want: nil},
+		{name: "sorted active entries", filter: filter, fromBlock: 150, toBlock: 250, want: []common.Address{dai, usdc, weth}},
+		{name: "entry starting inside the range", filter: filter, fromBlock: 0, toBlock: 100, want: []common.Address{dai, weth}},
+		{name: "nothing started yet", filter: NewTokenFilter([]TokenRule{{Address: usdc, StartBlock: 200}}, nil), fromBlock: 0, toBlock: 199, want: []common.Address{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Addresses(tt.fromBlock, tt.toBlock); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Addresses(%d, %d) = %v, want %v", tt.fromBlock, tt.toBlock, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultHeaderBatchSize is the number of headers requested per JSON-RPC batch when a provider sets none
+const DefaultHeaderBatchSize = 50
+
+// HeadersByNumber fetches block headers with batched eth_getBlockByNumber calls (fullTx=false)
+// Batches are sized by each provider's HeaderBatchSize; on failover only the missing headers are requested again
+func (p *ProviderPool) HeadersByNumber(ctx context.Context, numbers []uint64) (map[uint64]*types.Header, error) {
+	headers := make(map[uint64]*types.Header, len(numbers))
+	err := p.call(ctx, "BatchHeaderByNumber", func(ctx context.Context, provider *Provider) error {
+		missing := make([]uint64, 0, len(numbers)-len(headers))
+		for _, number := range numbers {
+			if _, ok := headers[number]; !ok {
+				missing = append(missing, number)
+			}
+		}
+		return batchHeaders(ctx, provider.GetClient().Client(), missing, provider.HeaderBatchSize, headers)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// GetBlockHeaders retrieves the headers of the given blocks with batched JSON-RPC requests
+func (c *Client) GetBlockHeaders(ctx context.Context, numbers []uint64) (map[uint64]*types.Header, error) {
+	if c.usePool && c.pool != nil {
+		headers, err := c.pool.HeadersByNumber(ctx, numbers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block headers: %w", err)
+		}
+		return headers, nil
+	}
+
+	if c.client == nil {
+		return nil, fmt.Errorf("no client or pool available")
+	}
+
+	headers := make(map[uint64]*types.Header, len(numbers))
+	if err := batchHeaders(ctx, c.client.Client(), numbers, DefaultHeaderBatchSize, headers); err != nil {
+		return nil, fmt.Errorf("failed to get block headers: %w", err)
+	}
+	return headers, nil
+}
+
+// batchHeaders requests headers batchSize at a time and adds them to headers
+// Headers of completed batches are kept when a later batch fails
+func batchHeaders(ctx context.Context, client *rpc.Client, numbers []uint64, batchSize int, headers map[uint64]*types.Header) error {
+	if batchSize <= 0 {
+		batchSize = DefaultHeaderBatchSize
+	}
+
+	for start := 0; start < len(numbers); start += batchSize {
+		chunk := numbers[start:min(start+batchSize, len(numbers))]
+
+		results := make([]*types.Header, len(chunk))
+		batch := make([]rpc.BatchElem, len(chunk))
+		for i, number := range chunk {
+			batch[i] = rpc.BatchElem{
+				Method: "eth_getBlockByNumber",
+				Args:   []interface{}{hexutil.EncodeUint64(number), false},
+				Result: &results[i],
+			}
+		}
+
+		if err := client.BatchCallContext(ctx, batch); err != nil {
+			return err
+		}
+
+		for i, elem := range batch {
+			if elem.Error != nil {
+				return fmt.Errorf("block %d: %w", chunk[i], elem.Error)
+			}
+			if results[i] == nil {
+				return fmt.Errorf("block %d not found", chunk[i])
+			}
+			headers[chunk[i]] = results[i]
+		}
+	}
+
+	return nil
+}
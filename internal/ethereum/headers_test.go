@@ -0,0 +1,156 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// headerService serves headers below head and records the blocks requested from it
+type headerService struct {
+	mu        sync.Mutex
+	head      uint64
+	failFrom  uint64 // Blocks from here on fail (0 = never)
+	requested []uint64
+}
+
+func (s *headerService) GetBlockByNumber(number hexutil.Uint64, fullTx bool) (*types.Header, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requested = append(s.requested, uint64(number))
+	if fullTx {
+		return nil, errors.New("full transactions requested")
+	}
+	if s.failFrom > 0 && uint64(number) >= s.failFrom {
+		return nil, errors.New("upstream unavailable")
+	}
+	if uint64(number) > s.head {
+		return nil, nil
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(uint64(number)), Time: 1700000000 + uint64(number), Difficulty: new(big.Int)}, nil
+}
+
+// newHeaderServer starts an HTTP JSON-RPC server for service and returns its URL and a count of HTTP requests
+func newHeaderServer(t *testing.T, service *headerService) (string, func() int) {
+	t.Helper()
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", service); err != nil {
+		t.Fatalf("RegisterName() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	requests := 0
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+	return httpServer.URL, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func blockRange(from, to uint64) []uint64 {
+	numbers := make([]uint64, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
+
+func TestBatchHeaders(t *testing.T) {
+	tests := []struct {
+		name         string
+		numbers      []uint64
+		batchSize    int
+		wantErr      bool
+		wantRequests int
+		wantHeaders  int
+	}{
+		{name: "batched", numbers: blockRange(1, 7), batchSize: 3, wantRequests: 3, wantHeaders: 7},
+		{name: "default batch size", numbers: blockRange(1, 60), batchSize: 0, wantRequests: 2, wantHeaders: 60},
+		{name: "missing block keeps headers fetched before it", numbers: blockRange(95, 104), batchSize: 5, wantErr: true, wantRequests: 2, wantHeaders: 6},
+		{name: "nothing to fetch", numbers: nil, batchSize: 5, wantRequests: 0, wantHeaders: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, requests := newHeaderServer(t, &headerService{head: 100})
+			client, err := rpc.Dial(url)
+			if err != nil {
+				t.Fatalf("Dial() error = %v", err)
+			}
+			defer client.Close()
+
+			headers := make(map[uint64]*types.Header)
+			err = batchHeaders(context.Background(), client, tt.numbers, tt.batchSize, headers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("batchHeaders() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := requests(); got != tt.wantRequests {
+				t.Errorf("%d HTTP requests, want %d", got, tt.wantRequests)
+			}
+			if len(headers) != tt.wantHeaders {
+				t.Errorf("%d headers, want %d", len(headers), tt.wantHeaders)
+			}
+			for number, header := range headers {
+				if header.Number.Uint64() != number || header.Time != 1700000000+number {
+					t.Errorf("header %d = block %d at %d", number, header.Number, header.Time)
+				}
+			}
+		})
+	}
+}
+
+func TestPoolHeadersByNumber(t *testing.T) {
+	// The preferred provider fails at block 15, so the fallback is asked only for blocks it did not return
+	primary := &headerService{head: 100, failFrom: 15}
+	fallback := &headerService{head: 100}
+	primaryURL, _ := newHeaderServer(t, primary)
+	fallbackURL, _ := newHeaderServer(t, fallback)
+
+	newProvider := func(name, url string, weight, batchSize int) *Provider {
+		provider, err := NewProvider(name, url, weight, 10, batchSize, 5*time.Second, DefaultCircuitBreakerConfig())
+		if err != nil {
+			t.Fatalf("NewProvider() error = %v", err)
+		}
+		t.Cleanup(provider.Close)
+		return provider
+	}
+	pool := NewProviderPool([]*Provider{newProvider("primary", primaryURL, 20, 5), newProvider("fallback", fallbackURL, 10, 50)})
+
+	headers, err := pool.HeadersByNumber(context.Background(), blockRange(1, 30))
+	if err != nil {
+		t.Fatalf("HeadersByNumber() error = %v", err)
+	}
+	if len(headers) != 30 {
+		t.Errorf("%d headers, want 30", len(headers))
+	}
+
+	sort.Slice(fallback.requested, func(i, j int) bool { return fallback.requested[i] < fallback.requested[j] })
+	if len(fallback.requested) != 16 || fallback.requested[0] != 15 {
+		t.Errorf("fallback asked for %v, want blocks 15-30", fallback.requested)
+	}
+	for _, number := range primary.requested {
+		if number > 15 {
+			t.Errorf("primary asked for block %d after failing at block 15", number)
+		}
+	}
+}
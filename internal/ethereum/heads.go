@@ -0,0 +1,123 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pagrin/internal/metrics"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrNoWebsocketProvider is returned when no healthy provider can push new heads
+var ErrNoWebsocketProvider = errors.New("no healthy websocket provider")
+
+// HeadSubscription is an eth_subscribe newHeads subscription held on a single provider
+type HeadSubscription struct {
+	ethereum.Subscription
+	Provider string    // Name of the provider pushing heads ("default" in legacy mode)
+	provider *Provider // nil in legacy mode
+}
+
+// Fail ends a subscription that broke or stalled
+// The provider's circuit breaker records the failure, so the next subscribe prefers another provider
+func (s *HeadSubscription) Fail(err error) {
+	s.Unsubscribe()
+	if s.provider != nil {
+		s.provider.RecordFailure(err)
+	}
+}
+
+// isWebsocketURL reports whether an RPC URL is dialled over websocket, which subscriptions require
+func isWebsocketURL(url string) bool {
+	url = strings.ToLower(url)
+	return strings.HasPrefix(url, "ws://") || strings.HasPrefix(url, "wss://")
+}
+
+// HasWebsocketProvider reports whether any provider can push new heads, healthy or not
+func (p *ProviderPool) HasWebsocketProvider() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, provider := range p.providers {
+		if isWebsocketURL(provider.URL) {
+			return true
+		}
+	}
+	return false
+}
+
+// SubscribeNewHead subscribes to new heads on the highest-weight healthy websocket provider
+// Fails over to the next websocket provider when a subscribe call fails
+func (p *ProviderPool) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (*HeadSubscription, error) {
+	p.mu.RLock()
+	candidates := make([]*Provider, 0, len(p.providers))
+	for _, provider := range p.providers {
+		if isWebsocketURL(provider.URL) && provider.IsHealthy() {
+			candidates = append(candidates, provider)
+		}
+	}
+	p.mu.RUnlock()
+
+	if len(candidates) == 0 {
+		return nil, ErrNoWebsocketProvider
+	}
+
+	var lastErr error
+	for _, provider := range candidates {
+		// The timeout only bounds the subscribe call; the subscription outlives it
+		providerCtx, cancel := context.WithTimeout(ctx, provider.Timeout)
+		start := time.Now()
+		sub, err := provider.client.SubscribeNewHead(providerCtx, ch)
+		duration := time.Since(start)
+		cancel()
+
+		metrics.RPCRequestDuration.WithLabelValues(provider.chain, provider.Name, "SubscribeNewHead").Observe(duration.Seconds())
+		provider.observeLatency(duration)
+		metrics.RPCRequestsTotal.WithLabelValues(provider.chain, provider.Name, "SubscribeNewHead").Inc()
+
+		if err == nil {
+			provider.RecordSuccess()
+			return &HeadSubscription{Subscription: sub, Provider: provider.Name, provider: provider}, nil
+		}
+
+		provider.RecordFailure(err)
+		lastErr = fmt.Errorf("provider %s failed: %w", provider.Name, err)
+
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("context cancelled: %w", ctx.Err())
+		}
+	}
+
+	return nil, fmt.Errorf("all websocket providers failed, last error: %w", lastErr)
+}
+
+// SupportsHeadSubscription reports whether new heads can be pushed rather than polled
+func (c *Client) SupportsHeadSubscription() bool {
+	if c.usePool && c.pool != nil {
+		return c.pool.HasWebsocketProvider()
+	}
+	return c.client != nil && c.websocket
+}
+
+// SubscribeNewHead streams new chain heads into ch until the subscription fails or is unsubscribed
+// Returns ErrNoWebsocketProvider when heads can only be polled
+func (c *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (*HeadSubscription, error) {
+	if c.usePool && c.pool != nil {
+		return c.pool.SubscribeNewHead(ctx, ch)
+	}
+
+	if c.client == nil || !c.websocket {
+		return nil, ErrNoWebsocketProvider
+	}
+
+	sub, err := c.client.SubscribeNewHead(ctx, ch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to new heads: %w", err)
+	}
+	return &HeadSubscription{Subscription: sub, Provider: "default"}, nil
+}
@@ -0,0 +1,128 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// headService pushes a fixed list of heads to every newHeads subscriber
+type headService struct {
+	heads []*types.Header
+}
+
+func (s *headService) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		for _, head := range s.heads {
+			_ = notifier.Notify(sub.ID, head)
+		}
+	}()
+	return sub, nil
+}
+
+// newHeadServer starts a websocket JSON-RPC server pushing heads and returns its ws:// URL
+func newHeadServer(t *testing.T, heads ...*types.Header) string {
+	t.Helper()
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", &headService{heads: heads}); err != nil {
+		t.Fatalf("RegisterName() error = %v", err)
+	}
+	httpServer := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+	return "ws" + strings.TrimPrefix(httpServer.URL, "http")
+}
+
+func TestIsWebsocketURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{url: "ws://localhost:8546", want: true},
+		{url: "WSS://mainnet.example/v3/key", want: true},
+		{url: "https://mainnet.example/v3/key", want: false},
+		{url: "http://localhost:8545", want: false},
+		{url: "/tmp/geth.ipc", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isWebsocketURL(tt.url); got != tt.want {
+			t.Errorf("isWebsocketURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestPoolSubscribeNewHead(t *testing.T) {
+	head := &types.Header{Number: big.NewInt(100), Difficulty: new(big.Int)}
+	newProvider := func(name, url string, weight int) *Provider {
+		provider, err := NewProvider(name, url, weight, 10, DefaultHeaderBatchSize, 5*time.Second, DefaultCircuitBreakerConfig())
+		if err != nil {
+			t.Fatalf("NewProvider() error = %v", err)
+		}
+		t.Cleanup(provider.Close)
+		return provider
+	}
+
+	t.Run("http only", func(t *testing.T) {
+		pool := NewProviderPool([]*Provider{newProvider("http", "http://127.0.0.1:1", 10)})
+		if pool.HasWebsocketProvider() {
+			t.Errorf("HasWebsocketProvider() = true for an http-only pool")
+		}
+		if _, err := pool.SubscribeNewHead(context.Background(), make(chan *types.Header)); !errors.Is(err, ErrNoWebsocketProvider) {
+			t.Errorf("SubscribeNewHead() error = %v, want ErrNoWebsocketProvider", err)
+		}
+	})
+
+	t.Run("skips unhealthy websocket providers", func(t *testing.T) {
+		broken := newProvider("broken", newHeadServer(t), 20)
+		healthy := newProvider("healthy", newHeadServer(t, head), 10)
+		for i := 0; i < DefaultCircuitBreakerConfig().FailureThreshold; i++ {
+			broken.RecordFailure(errors.New("connection reset"))
+		}
+		pool := NewProviderPool([]*Provider{newProvider("http", "http://127.0.0.1:1", 30), broken, healthy})
+
+		headers := make(chan *types.Header, 1)
+		sub, err := pool.SubscribeNewHead(context.Background(), headers)
+		if err != nil {
+			t.Fatalf("SubscribeNewHead() error = %v", err)
+		}
+		defer sub.Unsubscribe()
+		if sub.Provider != "healthy" {
+			t.Errorf("subscribed on %s, want healthy", sub.Provider)
+		}
+
+		select {
+		case got := <-headers:
+			if got.Number.Uint64() != 100 {
+				t.Errorf("head = %d, want 100", got.Number)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no head pushed")
+		}
+
+		// A failed subscription counts against its provider
+		for i := 0; i < DefaultCircuitBreakerConfig().FailureThreshold; i++ {
+			sub.Fail(errors.New("stalled"))
+		}
+		if healthy.IsHealthy() {
+			t.Errorf("provider still healthy after its subscription failed repeatedly")
+		}
+		if _, err := pool.SubscribeNewHead(context.Background(), headers); !errors.Is(err, ErrNoWebsocketProvider) {
+			t.Errorf("SubscribeNewHead() error = %v, want ErrNoWebsocketProvider once every websocket provider is unhealthy", err)
+		}
+	})
+}
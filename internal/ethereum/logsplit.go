@@ -0,0 +1,169 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"pagrin/internal/metrics"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// logLimitMessages are error fragments providers use when an eth_getLogs range returns too many logs
+// or takes too long on their side
+var logLimitMessages = []string{
+	"query returned more than", // geth, Infura
+	"response size exceeded",   // Alchemy
+	"too many results",
+	"range too large",
+	"range is too large",
+	"exceed maximum block range", // Erigon
+	"query timeout exceeded",     // Provider-side timeout on a busy range
+}
+
+// suggestedRangePattern matches the range some providers suggest, e.g. "Try with this block range [0x10, 0x2f]"
+var suggestedRangePattern = regexp.MustCompile(`\[(0x[0-9a-fA-F]+),\s*(0x[0-9a-fA-F]+)\]`)
+
+// logLimitError reports that a provider rejected an eth_getLogs range for its result size, or timed out on it
+type logLimitError struct {
+	provider    *Provider
+	suggestedTo uint64 // Last block of the provider's suggested range, 0 if none was given
+	err         error
+}
+
+func (e *logLimitError) Error() string {
+	return fmt.Sprintf("provider %s rejected log range: %v", e.provider.Name, e.err)
+}
+
+func (e *logLimitError) Unwrap() error {
+	return e.err
+}
+
+// asLogLimitError returns a *logLimitError if err is a result-size rejection, otherwise nil
+func asLogLimitError(provider *Provider, err error) *logLimitError {
+	msg := strings.ToLower(err.Error())
+	limited := false
+	for _, fragment := range logLimitMessages {
+		if strings.Contains(msg, fragment) {
+			limited = true
+			break
+		}
+	}
+	if !limited {
+		return nil
+	}
+
+	limitErr := &logLimitError{provider: provider, err: err}
+	if match := suggestedRangePattern.FindStringSubmatch(err.Error()); match != nil {
+		if to, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(match[2]), "0x"), 16, 64); err == nil {
+			limitErr.suggestedTo = to
+		}
+	}
+	return limitErr
+}
+
+// AIMDConfig tunes additive-increase/multiplicative-decrease sizing of block ranges
+// Used for live batches and for each provider's eth_getLogs range limit
+type AIMDConfig struct {
+	TargetDuration time.Duration // Duration a batch or request should stay under
+	TargetLogs     int           // Logs a batch or request should stay under
+	Increase       uint64        // Blocks added after a full range within both targets
+	Decrease       float64       // Factor applied to a range over either target, or rejected
+}
+
+// logRangeLimit returns the widest eth_getLogs range the provider is sent
+func (p *Provider) logRangeLimit() uint64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.rangeLimitLocked()
+}
+
+func (p *Provider) rangeLimitLocked() uint64 {
+	if p.rangeLimit > 0 && p.rangeLimit < p.MaxRange {
+		return p.rangeLimit
+	}
+	return p.MaxRange
+}
+
+// learnLogRange adjusts the provider's range limit after an eth_getLogs request over blocks
+// A request that reached the limit within both targets raises it by cfg.Increase, up to MaxRange;
+// one over either target, rejected for its size or timed out lowers it to cfg.Decrease times its range
+// Other errors say nothing about the range and leave the limit as is
+func (p *Provider) learnLogRange(cfg AIMDConfig, blocks uint64, logs int, duration time.Duration, err error) {
+	over := duration > cfg.TargetDuration || logs > cfg.TargetLogs
+	if err != nil {
+		if asLogLimitError(p, err) == nil && !errors.Is(err, context.DeadlineExceeded) {
+			return
+		}
+		over = true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	limit := p.rangeLimitLocked()
+	switch {
+	case over:
+		limit = max(min(limit, uint64(float64(blocks)*cfg.Decrease)), 1)
+	case blocks >= limit:
+		limit = min(limit+cfg.Increase, p.MaxRange)
+	default:
+		return
+	}
+	p.rangeLimit = limit
+	metrics.ProviderLogRangeLimit.WithLabelValues(p.chain, p.Name).Set(float64(limit))
+}
+
+// FilterLogs executes eth_getLogs with automatic failover across providers
+// A range rejected for returning too many logs, or that timed out, is split and the halves merged in block order,
+// so one busy block only narrows the calls around it; the provider's suggested range is used
+// as the split point when the error includes one
+// With learned range limits, a range wider than every provider's limit is split to fit the widest
+func (p *ProviderPool) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	logs, err := p.filterLogs(ctx, query)
+	limitErr, ok := err.(*logLimitError)
+	if !ok {
+		return logs, err
+	}
+
+	fromBlock, toBlock := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+	if fromBlock >= toBlock {
+		return nil, fmt.Errorf("block %d alone exceeds the provider log limit: %w", fromBlock, limitErr)
+	}
+
+	splitAt := logSplitPoint(fromBlock, toBlock, limitErr.suggestedTo)
+
+	metrics.RPCLogRangeSplitsTotal.WithLabelValues(limitErr.provider.chain, limitErr.provider.Name).Inc()
+
+	first := query
+	first.ToBlock = new(big.Int).SetUint64(splitAt)
+	firstLogs, err := p.FilterLogs(ctx, first)
+	if err != nil {
+		return nil, err
+	}
+
+	second := query
+	second.FromBlock = new(big.Int).SetUint64(splitAt + 1)
+	secondLogs, err := p.FilterLogs(ctx, second)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(firstLogs, secondLogs...), nil
+}
+
+// logSplitPoint returns the last block of the first half of [fromBlock, toBlock], which must span two blocks or more
+// The provider's suggested end block is used when it leaves both halves non-empty, otherwise the range is halved
+func logSplitPoint(fromBlock, toBlock, suggestedTo uint64) uint64 {
+	if suggestedTo >= fromBlock && suggestedTo < toBlock {
+		return suggestedTo
+	}
+	return fromBlock + (toBlock-fromBlock)/2
+}
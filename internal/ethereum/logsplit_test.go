@@ -0,0 +1,147 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAsLogLimitError(t *testing.T) {
+	provider := &Provider{Name: "test"}
+
+	tests := []struct {
+		name        string
+		err         error
+		limited     bool
+		suggestedTo uint64
+	}{
+		{
+			name:    "geth result limit",
+			err:     errors.New("query returned more than 10000 results"),
+			limited: true,
+		},
+		{
+			name:        "infura suggested range",
+			err:         errors.New("query returned more than 10000 results. Try with this block range [0x1E8480, 0x1E84FF]."),
+			limited:     true,
+			suggestedTo: 0x1e84ff,
+		},
+		{
+			name:        "alchemy suggested range",
+			err:         errors.New("Log response size exceeded. Based on your parameters, this block range should work: [0x10, 0x2f]"),
+			limited:     true,
+			suggestedTo: 0x2f,
+		},
+		{
+			name:    "provider timeout",
+			err:     errors.New("query timeout exceeded"),
+			limited: true,
+		},
+		{
+			name:    "wrapped erigon range limit",
+			err:     fmt.Errorf("provider failed: %w", errors.New("exceed maximum block range: 1000")),
+			limited: true,
+		},
+		{
+			name:    "unparseable suggestion",
+			err:     errors.New("too many results, try [0x10, 0xffffffffffffffffff]"),
+			limited: true,
+		},
+		{
+			name: "transport error",
+			err:  errors.New("dial tcp: connection refused"),
+		},
+		{
+			name: "execution error",
+			err:  errors.New("execution reverted"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limitErr := asLogLimitError(provider, tt.err)
+			if (limitErr != nil) != tt.limited {
+				t.Fatalf("asLogLimitError() limited = %v, want %v", limitErr != nil, tt.limited)
+			}
+			if limitErr == nil {
+				return
+			}
+			if limitErr.suggestedTo != tt.suggestedTo {
+				t.Errorf("suggestedTo = %d, want %d", limitErr.suggestedTo, tt.suggestedTo)
+			}
+			if !errors.Is(limitErr, tt.err) {
+				t.Errorf("limit error does not wrap %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestLogSplitPoint(t *testing.T) {
+	tests := []struct {
+		name        string
+		fromBlock   uint64
+		toBlock     uint64
+		suggestedTo uint64
+		want        uint64
+	}{
+		{name: "halved without suggestion", fromBlock: 100, toBlock: 199, want: 149},
+		{name: "two blocks", fromBlock: 10, toBlock: 11, want: 10},
+		{name: "suggestion inside range", fromBlock: 100, toBlock: 199, suggestedTo: 120, want: 120},
+		{name: "suggestion at first block", fromBlock: 100, toBlock: 199, suggestedTo: 100, want: 100},
+		{name: "suggestion at last block is halved", fromBlock: 100, toBlock: 199, suggestedTo: 199, want: 149},
+		{name: "suggestion past range is halved", fromBlock: 100, toBlock: 199, suggestedTo: 500, want: 149},
+		{name: "suggestion before range is halved", fromBlock: 100, toBlock: 199, suggestedTo: 50, want: 149},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logSplitPoint(tt.fromBlock, tt.toBlock, tt.suggestedTo); got != tt.want {
+				t.Errorf("logSplitPoint(%d, %d, %d) = %d, want %d", tt.fromBlock, tt.toBlock, tt.suggestedTo, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLearnLogRange(t *testing.T) {
+	cfg := AIMDConfig{
+		TargetDuration: 2 * time.Second,
+		TargetLogs:     5000,
+		Increase:       5,
+		Decrease:       0.5,
+	}
+
+	tests := []struct {
+		name     string
+		limit    uint64 // Learned limit before the request, 0 for none
+		blocks   uint64
+		logs     int
+		duration time.Duration
+		err      error
+		want     uint64
+	}{
+		{name: "full range within targets grows", limit: 100, blocks: 100, logs: 10, duration: time.Second, want: 105},
+		{name: "growth capped at max range", limit: 998, blocks: 998, duration: time.Second, want: 1000},
+		{name: "unlearned limit at max range stays", blocks: 1000, duration: time.Second, want: 1000},
+		{name: "partial range within targets keeps limit", limit: 100, blocks: 50, duration: time.Second, want: 100},
+		{name: "slow request shrinks", limit: 100, blocks: 100, duration: 3 * time.Second, want: 50},
+		{name: "too many logs shrinks", limit: 100, blocks: 100, logs: 6000, duration: time.Second, want: 50},
+		{name: "shrinks from the request range", limit: 100, blocks: 60, duration: 3 * time.Second, want: 30},
+		{name: "never grows on shrink", limit: 40, blocks: 100, duration: 3 * time.Second, want: 40},
+		{name: "result limit rejection shrinks", limit: 100, blocks: 100, err: errors.New("query returned more than 10000 results"), want: 50},
+		{name: "timeout shrinks", limit: 100, blocks: 100, err: fmt.Errorf("eth_getLogs: %w", context.DeadlineExceeded), want: 50},
+		{name: "unrelated error keeps limit", limit: 100, blocks: 100, duration: 3 * time.Second, err: errors.New("connection refused"), want: 100},
+		{name: "floor of one block", limit: 1, blocks: 1, duration: 3 * time.Second, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &Provider{Name: "test", MaxRange: 1000, rangeLimit: tt.limit}
+			provider.learnLogRange(cfg, tt.blocks, tt.logs, tt.duration, tt.err)
+			if got := provider.logRangeLimit(); got != tt.want {
+				t.Errorf("logRangeLimit() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"pagrin/internal/models"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultTokenDecimals is assumed for tokens that do not implement decimals()
+const DefaultTokenDecimals uint8 = 18
+
+// ERC-20 metadata function selectors
+var (
+	selectorName     = common.FromHex("0x06fdde03") // name()
+	selectorSymbol   = common.FromHex("0x95d89b41") // symbol()
+	selectorDecimals = common.FromHex("0x313ce567") // decimals()
+)
+
+// NativeTokenMetadata describes the pseudo-token native and internal ETH transfers are recorded under
+func NativeTokenMetadata() *models.TokenMetadata {
+	return &models.TokenMetadata{
+		Address:     NativeTokenAddress,
+		Name:        "Ether",
+		Symbol:      "ETH",
+		Decimals:    18,
+		HasDecimals: true,
+		UpdatedAt:   time.Now(),
+	}
+}
+
+// GetTokenMetadata reads name(), symbol() and decimals() of a token contract at the latest block
+// Both string and legacy bytes32 return types are decoded; functions that revert or return nothing are left empty
+// Returns an error only when the RPC calls themselves fail, so callers can retry instead of caching empty metadata
+func (c *Client) GetTokenMetadata(ctx context.Context, token common.Address) (*models.TokenMetadata, error) {
+	metadata := &models.TokenMetadata{
+		Address:   strings.ToLower(token.Hex()),
+		Decimals:  DefaultTokenDecimals,
+		UpdatedAt: time.Now(),
+	}
+
+	decimals, err := c.callToken(ctx, token, selectorDecimals)
+	if err != nil {
+		return nil, err
+	}
+	if value, ok := decodeDecimals(decimals); ok {
+		metadata.Decimals = value
+		metadata.HasDecimals = true
+	}
+
+	symbol, err := c.callToken(ctx, token, selectorSymbol)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Symbol = decodeStringOrBytes32(symbol)
+
+	name, err := c.callToken(ctx, token, selectorName)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Name = decodeStringOrBytes32(name)
+
+	return metadata, nil
+}
+
+// callToken calls a no-argument view function, returning nil output if the call reverts
+func (c *Client) callToken(ctx context.Context, token common.Address, selector []byte) ([]byte, error) {
+	msg := eth.CallMsg{To: &token, Data: selector}
+
+	output, err := c.CallContract(ctx, msg)
+	if isExecutionError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s on %s: %w", common.Bytes2Hex(selector), token.Hex(), err)
+	}
+	return output, nil
+}
+
+// CallContract executes an eth_call against the latest block
+func (c *Client) CallContract(ctx context.Context, msg eth.CallMsg) ([]byte, error) {
+	if c.usePool && c.pool != nil {
+		return c.pool.CallContract(ctx, msg)
+	}
+
+	if c.client == nil {
+		return nil, fmt.Errorf("no client or pool available")
+	}
+
+	return c.client.CallContract(ctx, msg, nil)
+}
+
+// isExecutionError reports whether an eth_call failed inside the EVM (revert, invalid opcode)
+// rather than in transport; such failures are deterministic and must not trip failover
+func isExecutionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == 3 {
+		return true // execution reverted with data
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "execution reverted") ||
+		strings.Contains(msg, "invalid opcode") ||
+		strings.Contains(msg, "out of gas")
+}
+
+// decodeDecimals decodes a uint8 decimals() return value
+func decodeDecimals(output []byte) (uint8, bool) {
+	if len(output) < 32 {
+		return 0, false
+	}
+	value := new(big.Int).SetBytes(output[:32])
+	if !value.IsUint64() || value.Uint64() > 255 {
+		return 0, false
+	}
+	return uint8(value.Uint64()), true
+}
+
+// decodeStringOrBytes32 decodes an ABI string return value, falling back to a
+// NUL-padded bytes32 as returned by legacy tokens such as MKR and SAI
+func decodeStringOrBytes32(output []byte) string {
+	if len(output) >= 64 {
+		offset := new(big.Int).SetBytes(output[:32])
+		// Bounds are compared by subtraction so hostile offsets and lengths cannot overflow
+		if offset.IsUint64() && offset.Uint64() <= uint64(len(output)-32) {
+			start := offset.Uint64()
+			length := new(big.Int).SetBytes(output[start : start+32])
+			if length.IsUint64() && length.Uint64() <= uint64(len(output))-start-32 {
+				return sanitizeTokenString(output[start+32 : start+32+length.Uint64()])
+			}
+		}
+	}
+	if len(output) == 32 {
+		return sanitizeTokenString(bytes.TrimRight(output, "\x00"))
+	}
+	return ""
+}
+
+// sanitizeTokenString drops invalid UTF-8, NUL bytes and surrounding whitespace from contract-supplied text
+func sanitizeTokenString(raw []byte) string {
+	s := strings.ToValidUTF8(string(raw), "")
+	s = strings.ReplaceAll(s, "\x00", "")
+	return strings.TrimSpace(s)
+}
+
+// ScaleValue converts a raw token amount to a human-readable value using the token's decimals
+func ScaleValue(value *big.Int, decimals uint8) float64 {
+	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	valueFloat := new(big.Float).SetInt(value)
+	result, _ := new(big.Float).Quo(valueFloat, divisor).Float64()
+	return result
+}
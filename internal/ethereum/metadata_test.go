@@ -0,0 +1,259 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// abiPadded right-pads raw bytes to a multiple of 32
+func abiPadded(raw []byte) []byte {
+	return append(raw, make([]byte, (32-len(raw)%32)%32)...)
+}
+
+func TestDecodeStringOrBytes32(t *testing.T) {
+	n := big.NewInt
+	maxUint64 := new(big.Int).SetUint64(math.MaxUint64)
+	huge := new(big.Int).Lsh(big.NewInt(1), 64) // Does not fit in uint64
+
+	tests := []struct {
+		name   string
+		output []byte
+		want   string
+	}{
+		{
+			name:   "abi string",
+			output: append(abiWords(n(0x20), n(8)), abiPadded([]byte("USD Coin"))...),
+			want:   "USD Coin",
+		},
+		{
+			name:   "empty abi string",
+			output: abiWords(n(0x20), n(0)),
+			want:   "",
+		},
+		{
+			name:   "bytes32",
+			output: abiPadded([]byte("MKR")),
+			want:   "MKR",
+		},
+		{
+			name:   "invalid utf-8, nul bytes and whitespace dropped",
+			output: append(abiWords(n(0x20), n(8)), abiPadded([]byte(" W\xffE\x00TH "))...),
+			want:   "WETH",
+		},
+		{
+			name:   "too short",
+			output: []byte("USDC"),
+			want:   "",
+		},
+		{
+			name:   "offset past the end",
+			output: abiWords(n(0x21), n(0)),
+			want:   "",
+		},
+		{
+			name:   "offset wrapping uint64",
+			output: abiWords(maxUint64, n(0), n(0)),
+			want:   "",
+		},
+		{
+			name:   "offset beyond uint64",
+			output: abiWords(huge, n(0), n(0)),
+			want:   "",
+		},
+		{
+			name:   "length past the end",
+			output: append(abiWords(n(0x20), n(33)), abiPadded([]byte("USD Coin"))...),
+			want:   "",
+		},
+		{
+			name:   "length wrapping uint64",
+			output: append(abiWords(n(0x20), new(big.Int).SetUint64(math.MaxUint64-31)), abiPadded([]byte("USD Coin"))...),
+			want:   "",
+		},
+		{
+			name:   "length beyond uint64",
+			output: append(abiWords(n(0x20), huge), abiPadded([]byte("USD Coin"))...),
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeStringOrBytes32(tt.output); got != tt.want {
+				t.Errorf("decodeStringOrBytes32() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeDecimals(t *testing.T) {
+	n := big.NewInt
+
+	tests := []struct {
+		name   string
+		output []byte
+		want   uint8
+		ok     bool
+	}{
+		{name: "eighteen", output: abiWords(n(18)), want: 18, ok: true},
+		{name: "zero", output: abiWords(n(0)), want: 0, ok: true},
+		{name: "max uint8", output: abiWords(n(255)), want: 255, ok: true},
+		{name: "above uint8", output: abiWords(n(256))},
+		{name: "beyond uint64", output: abiWords(new(big.Int).Lsh(big.NewInt(1), 64))},
+		{name: "too short", output: []byte{18}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := decodeDecimals(tt.output)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("decodeDecimals() = (%d, %v), want (%d, %v)", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSanitizeTokenString(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []byte
+		want string
+	}{
+		{name: "plain", raw: []byte("USDC"), want: "USDC"},
+		{name: "nul bytes", raw: []byte("M\x00KR\x00\x00"), want: "MKR"},
+		{name: "invalid utf-8", raw: []byte{'D', 0xff, 'A', 0xfe, 'I'}, want: "DAI"},
+		{name: "whitespace", raw: []byte("  Wrapped Ether\n"), want: "Wrapped Ether"},
+		{name: "empty", raw: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeTokenString(tt.raw); got != tt.want {
+				t.Errorf("sanitizeTokenString(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScaleValue(t *testing.T) {
+	tests := []struct {
+		value    *big.Int
+		decimals uint8
+		want     float64
+	}{
+		{value: big.NewInt(1500000), decimals: 6, want: 1.5},
+		{value: big.NewInt(0), decimals: 18, want: 0},
+		{value: new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil), decimals: 18, want: 1},
+		{value: big.NewInt(42), decimals: 0, want: 42},
+	}
+
+	for _, tt := range tests {
+		if got := ScaleValue(tt.value, tt.decimals); got != tt.want {
+			t.Errorf("ScaleValue(%s, %d) = %v, want %v", tt.value, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+// tokenFields are the resolved metadata fields compared by TestGetTokenMetadata
+type tokenFields struct {
+	symbol, name string
+	decimals     uint8
+	hasDecimals  bool
+}
+
+func TestGetTokenMetadata(t *testing.T) {
+	abiString := func(s string) []byte {
+		output := append(common.LeftPadBytes(big.NewInt(32).Bytes(), 32), common.LeftPadBytes(big.NewInt(int64(len(s))).Bytes(), 32)...)
+		return append(output, abiPadded([]byte(s))...)
+	}
+	revert := errors.New("execution reverted")
+	unavailable := errors.New("upstream connect error")
+
+	tests := []struct {
+		name      string
+		responses map[string]any // []byte output or error, by selector
+		wantErr   bool
+		want      tokenFields
+	}{
+		{
+			name: "string metadata",
+			responses: map[string]any{
+				"313ce567": common.LeftPadBytes([]byte{6}, 32),
+				"95d89b41": abiString("USDC"),
+				"06fdde03": abiString("USD Coin"),
+			},
+			want: tokenFields{symbol: "USDC", name: "USD Coin", decimals: 6, hasDecimals: true},
+		},
+		{
+			name: "bytes32 metadata",
+			responses: map[string]any{
+				"313ce567": common.LeftPadBytes([]byte{18}, 32),
+				"95d89b41": abiPadded([]byte("MKR")),
+				"06fdde03": abiPadded([]byte("Maker")),
+			},
+			want: tokenFields{symbol: "MKR", name: "Maker", decimals: 18, hasDecimals: true},
+		},
+		{
+			name: "reverting functions are left empty",
+			responses: map[string]any{
+				"313ce567": revert,
+				"95d89b41": []byte{},
+				"06fdde03": revert,
+			},
+			want: tokenFields{decimals: DefaultTokenDecimals},
+		},
+		{
+			name: "transport failure",
+			responses: map[string]any{
+				"313ce567": common.LeftPadBytes([]byte{6}, 32),
+				"95d89b41": unavailable,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestRPC(t, func(method string, params []json.RawMessage) (any, error) {
+				var call struct {
+					Input hexutil.Bytes `json:"input"`
+				}
+				if method != "eth_call" || len(params) == 0 || json.Unmarshal(params[0], &call) != nil {
+					return nil, errors.New("unexpected request")
+				}
+				switch response := tt.responses[common.Bytes2Hex(call.Input)].(type) {
+				case error:
+					return nil, response
+				case []byte:
+					return hexutil.Bytes(response), nil
+				}
+				return nil, errors.New("unexpected selector")
+			})
+
+			token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+			metadata, err := client.GetTokenMetadata(context.Background(), token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetTokenMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if metadata.Address != "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48" {
+				t.Errorf("Address = %s, want lowercase", metadata.Address)
+			}
+			if metadata.Symbol != tt.want.symbol || metadata.Name != tt.want.name {
+				t.Errorf("metadata = %q/%q, want %q/%q", metadata.Symbol, metadata.Name, tt.want.symbol, tt.want.name)
+			}
+			if metadata.Decimals != tt.want.decimals || metadata.HasDecimals != tt.want.hasDecimals {
+				t.Errorf("decimals = %d (known %v), want %d (known %v)", metadata.Decimals, metadata.HasDecimals, tt.want.decimals, tt.want.hasDecimals)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"pagrin/internal/models"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NativeTokenAddress is the pseudo-token address native ETH transfers are recorded under
+const NativeTokenAddress = "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"
+
+// EventSignatureNativeTransfer identifies transfers taken from a transaction's value
+const EventSignatureNativeTransfer = "NativeTransfer"
+
+// fetchNativeTransfers returns the value transfers of a block's successful transactions
+// Receipts are loaded with a single eth_getBlockReceipts call, and only for blocks with value-bearing transactions
+func (f *Fetcher) fetchNativeTransfers(ctx context.Context, block *types.Block) ([]*models.Transfer, error) {
+	if block == nil {
+		return nil, fmt.Errorf("missing block for native transfers")
+	}
+
+	hasValue := false
+	for _, tx := range block.Transactions() {
+		if tx.Value().Sign() > 0 {
+			hasValue = true
+			break
+		}
+	}
+	if !hasValue {
+		return nil, nil
+	}
+
+	receipts, err := f.client.GetBlockReceipts(ctx, block.NumberU64())
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) != len(block.Transactions()) {
+		return nil, fmt.Errorf("block %d has %d transactions but %d receipts", block.NumberU64(), len(block.Transactions()), len(receipts))
+	}
+
+	blockHash := block.Hash()
+	timestamp := time.Unix(int64(block.Time()), 0)
+
+	transfers := make([]*models.Transfer, 0)
+	for i, tx := range block.Transactions() {
+		if tx.Value().Sign() <= 0 {
+			continue
+		}
+
+		receipt := receipts[i]
+		if receipt.TxHash != tx.Hash() || receipt.BlockHash != blockHash {
+			// Receipts came from a different fork than the block - let the batch retry
+			return nil, fmt.Errorf("receipts for block %d do not match its transactions", block.NumberU64())
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+
+		transfer, err := parseNativeTransfer(tx, receipt, uint(i), blockHash.Hex(), block.NumberU64(), timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse native transfer %s: %w", tx.Hash().Hex(), err)
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, nil
+}
+
+// parseNativeTransfer converts a successful value-bearing transaction into a Transfer
+// Contract creations are recorded as transfers to the created contract
+func parseNativeTransfer(tx *types.Transaction, receipt *types.Receipt, txIndex uint, blockHash string, blockNumber uint64, blockTime time.Time) (*models.Transfer, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover sender: %w", err)
+	}
+
+	to := receipt.ContractAddress
+	if tx.To() != nil {
+		to = *tx.To()
+	}
+
+	value := tx.Value()
+	valueStr := value.String()
+	decimalValue, err := primitive.ParseDecimal128(valueStr)
+	if err != nil {
+		decimalValue = primitive.NewDecimal128(0, 0)
+	}
+
+	return &models.Transfer{
+		EventSignature: EventSignatureNativeTransfer,
+		Standard:       models.StandardNative,
+		Token:          NativeTokenAddress,
+		Kind:           transferKind(from, to),
+		From:           strings.ToLower(from.Hex()),
+		To:             strings.ToLower(to.Hex()),
+		Value:          decimalValue,
+		ValueString:    valueStr,
+		ValueDecimal:   parseValueDecimal(value),
+		BlockNumber:    blockNumber,
+		BlockHash:      blockHash,
+		TxHash:         tx.Hash().Hex(),
+		TxIndex:        txIndex,
+		Timestamp:      blockTime,
+		CreatedAt:      time.Now(),
+	}, nil
+}
@@ -0,0 +1,204 @@
+package ethereum
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newTestRPC starts a JSON-RPC server answering through handle and returns a client connected to it
+func newTestRPC(t *testing.T, handle func(method string, params []json.RawMessage) (any, error)) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		response := map[string]any{"jsonrpc": "2.0", "id": request.ID}
+		if result, err := handle(request.Method, request.Params); err != nil {
+			response["error"] = map[string]any{"code": -32000, "message": err.Error()}
+		} else {
+			response["result"] = result
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return client
+}
+
+// signedTx signs a legacy transaction sending value to to (nil deploys a contract)
+func signedTx(t *testing.T, key *ecdsa.PrivateKey, nonce uint64, to *common.Address, value int64) *types.Transaction {
+	t.Helper()
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	tx, err := types.SignNewTx(key, signer, &types.LegacyTx{
+		Nonce:    nonce,
+		To:       to,
+		Value:    big.NewInt(value),
+		Gas:      21000,
+		GasPrice: big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatalf("SignNewTx() error = %v", err)
+	}
+	return tx
+}
+
+func TestParseNativeTransfer(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	sender := strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex())
+	recipient := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	created := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	blockTime := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name   string
+		tx     *types.Transaction
+		wantTo string
+	}{
+		{name: "value transfer", tx: signedTx(t, key, 0, &recipient, 5), wantTo: strings.ToLower(recipient.Hex())},
+		{name: "contract creation", tx: signedTx(t, key, 1, nil, 7), wantTo: strings.ToLower(created.Hex())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, ContractAddress: created}
+			transfer, err := parseNativeTransfer(tt.tx, receipt, 3, "0xblock", 42, blockTime)
+			if err != nil {
+				t.Fatalf("parseNativeTransfer() error = %v", err)
+			}
+			if transfer.From != sender || transfer.To != tt.wantTo {
+				t.Errorf("transfer = %s -> %s, want %s -> %s", transfer.From, transfer.To, sender, tt.wantTo)
+			}
+			if transfer.Token != NativeTokenAddress || transfer.EventSignature != EventSignatureNativeTransfer {
+				t.Errorf("token = %s (%s), want native pseudo-token", transfer.Token, transfer.EventSignature)
+			}
+			if transfer.ValueString != tt.tx.Value().String() {
+				t.Errorf("ValueString = %s, want %s", transfer.ValueString, tt.tx.Value())
+			}
+			if transfer.TxIndex != 3 || transfer.BlockNumber != 42 || transfer.TxHash != tt.tx.Hash().Hex() {
+				t.Errorf("position = tx %d block %d hash %s", transfer.TxIndex, transfer.BlockNumber, transfer.TxHash)
+			}
+		})
+	}
+}
+
+func TestFetchNativeTransfers(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	recipient := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	txs := []*types.Transaction{
+		signedTx(t, key, 0, &recipient, 0),  // no value
+		signedTx(t, key, 1, &recipient, 10), // succeeds
+		signedTx(t, key, 2, &recipient, 20), // reverts
+		signedTx(t, key, 3, &recipient, 30), // succeeds
+	}
+	header := &types.Header{Number: big.NewInt(100), Time: 1700000000, Difficulty: new(big.Int)}
+	block := types.NewBlockWithHeader(header).WithBody(types.Body{Transactions: txs})
+	zeroValue := types.NewBlockWithHeader(header).WithBody(types.Body{Transactions: txs[:1]})
+
+	receiptsFor := func(blockHash common.Hash) types.Receipts {
+		receipts := make(types.Receipts, len(txs))
+		for i, tx := range txs {
+			receipts[i] = &types.Receipt{
+				Status:      types.ReceiptStatusSuccessful,
+				TxHash:      tx.Hash(),
+				BlockHash:   blockHash,
+				BlockNumber: big.NewInt(100),
+				Logs:        []*types.Log{},
+			}
+		}
+		receipts[2].Status = types.ReceiptStatusFailed
+		return receipts
+	}
+
+	tests := []struct {
+		name      string
+		block     *types.Block
+		receipts  types.Receipts
+		wantErr   bool
+		wantValue []string
+		wantIndex []uint
+	}{
+		{
+			name:      "reverted and zero value transactions are skipped",
+			block:     block,
+			receipts:  receiptsFor(block.Hash()),
+			wantValue: []string{"10", "30"},
+			wantIndex: []uint{1, 3},
+		},
+		{
+			name:  "no value transactions skip the receipts call",
+			block: zeroValue,
+		},
+		{
+			name:     "receipts from another fork",
+			block:    block,
+			receipts: receiptsFor(common.HexToHash("0xdead")),
+			wantErr:  true,
+		},
+		{
+			name:     "receipt count mismatch",
+			block:    block,
+			receipts: receiptsFor(block.Hash())[:3],
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			client := newTestRPC(t, func(method string, _ []json.RawMessage) (any, error) {
+				calls++
+				return tt.receipts, nil
+			})
+			fetcher := &Fetcher{client: client, nativeTransfers: true}
+
+			transfers, err := fetcher.fetchNativeTransfers(context.Background(), tt.block)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetchNativeTransfers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.receipts == nil && calls != 0 {
+				t.Errorf("receipts requested %d times for a block without value transfers", calls)
+			}
+			if len(transfers) != len(tt.wantValue) {
+				t.Fatalf("got %d transfers, want %d", len(transfers), len(tt.wantValue))
+			}
+			for i, transfer := range transfers {
+				if transfer.ValueString != tt.wantValue[i] || transfer.TxIndex != tt.wantIndex[i] {
+					t.Errorf("transfer %d = %s at tx %d, want %s at tx %d", i, transfer.ValueString, transfer.TxIndex, tt.wantValue[i], tt.wantIndex[i])
+				}
+				if transfer.BlockHash != tt.block.Hash().Hex() {
+					t.Errorf("transfer %d block hash = %s, want %s", i, transfer.BlockHash, tt.block.Hash().Hex())
+				}
+			}
+		})
+	}
+}
@@ -16,8 +16,34 @@ import (
 // ERC20TransferEventSignature is the keccak256 hash of Transfer(address,address,uint256)
 var ERC20TransferEventSignature = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
 
-// EventSignatureTransfer is the string identifier for ERC-20 Transfer events
-const EventSignatureTransfer = "Transfer"
+// ERC1155TransferSingleEventSignature is the keccak256 hash of TransferSingle(address,address,address,uint256,uint256)
+var ERC1155TransferSingleEventSignature = common.HexToHash("0xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62")
+
+// ERC1155TransferBatchEventSignature is the keccak256 hash of TransferBatch(address,address,address,uint256[],uint256[])
+var ERC1155TransferBatchEventSignature = common.HexToHash("0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb")
+
+// ERC20ApprovalEventSignature is the keccak256 hash of Approval(address,address,uint256)
+var ERC20ApprovalEventSignature = common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")
+
+// String identifiers stored in the event_signature field
+const (
+	EventSignatureTransfer       = "Transfer"
+	EventSignatureTransferSingle = "TransferSingle"
+	EventSignatureTransferBatch  = "TransferBatch"
+	EventSignatureApproval       = "Approval"
+)
+
+// UnlimitedApprovalThreshold is the allowance from which an approval is treated as unlimited
+// Wallets approve 2^256-1 (or slightly less after spending); no real token supply approaches 2^128 base units
+var UnlimitedApprovalThreshold = new(big.Int).Lsh(big.NewInt(1), 128)
+
+// zeroAddress is the sender of mints and the recipient of burns
+var zeroAddress = strings.ToLower(common.Address{}.Hex())
+
+// transferKind classifies a transfer as a mint, burn or plain transfer
+func transferKind(from, to common.Address) string {
+	return models.ClassifyTransfer(strings.ToLower(from.Hex()), strings.ToLower(to.Hex()))
+}
 
 // ParseTransferLog parses a raw Ethereum log into a normalized Transfer event
 // Converts wei value to Decimal128 for precise storage and efficient aggregations
@@ -42,22 +68,26 @@ func ParseTransferLog(log types.Log, blockTime time.Time) (*models.Transfer, err
 
 	// Convert big.Int to Decimal128 for MongoDB storage
 	// Decimal128 provides 34 decimal digits of precision, perfect for wei values
+	// Values beyond 34 significant digits (spam tokens) fall back to zero and are reported as dead letters
 	decimalValue, err := primitive.ParseDecimal128(valueStr)
-	if err != nil {
-		// Fallback: if parsing fails, use zero and log the error
-		// This should never happen with valid wei values, but defensive programming
+	overflow := err != nil
+	if overflow {
 		decimalValue = primitive.NewDecimal128(0, 0)
 	}
 
 	transfer := &models.Transfer{
 		EventSignature: EventSignatureTransfer,
+		Standard:       models.StandardERC20,
 		Token:          strings.ToLower(log.Address.Hex()),
+		Kind:           transferKind(from, to),
 		From:           strings.ToLower(from.Hex()),
 		To:             strings.ToLower(to.Hex()),
 		Value:          decimalValue,
 		ValueString:    valueStr, // Keep string for backward compatibility and JSON serialization
 		ValueDecimal:   parseValueDecimal(value),
+		ValueOverflow:  overflow,
 		BlockNumber:    log.BlockNumber,
+		BlockHash:      log.BlockHash.Hex(),
 		TxHash:         log.TxHash.Hex(),
 		TxIndex:        log.TxIndex,
 		LogIndex:       log.Index,
@@ -68,10 +98,207 @@ func ParseTransferLog(log types.Log, blockTime time.Time) (*models.Transfer, err
 	return transfer, nil
 }
 
-// parseValueDecimal converts wei to a decimal representation (assuming 18 decimals for ERC-20)
+// ParseNFTTransferLog parses an ERC-721 Transfer log into an NFTTransfer
+// ERC-721 shares the ERC-20 Transfer signature but indexes tokenId as a fourth topic and has no data
+func ParseNFTTransferLog(log types.Log, blockTime time.Time) (*models.NFTTransfer, error) {
+	if len(log.Topics) != 4 {
+		return nil, fmt.Errorf("invalid ERC-721 Transfer event: expected 4 topics, got %d", len(log.Topics))
+	}
+
+	if log.Topics[0] != ERC20TransferEventSignature {
+		return nil, fmt.Errorf("not a Transfer event")
+	}
+
+	if len(log.Data) != 0 {
+		return nil, fmt.Errorf("invalid ERC-721 Transfer event data: expected 0 bytes, got %d", len(log.Data))
+	}
+
+	from := common.BytesToAddress(log.Topics[1].Bytes())
+	to := common.BytesToAddress(log.Topics[2].Bytes())
+	tokenID := new(big.Int).SetBytes(log.Topics[3].Bytes())
+
+	transfer := &models.NFTTransfer{
+		Token:       strings.ToLower(log.Address.Hex()),
+		TokenID:     tokenID.String(),
+		From:        strings.ToLower(from.Hex()),
+		To:          strings.ToLower(to.Hex()),
+		BlockNumber: log.BlockNumber,
+		BlockHash:   log.BlockHash.Hex(),
+		TxHash:      log.TxHash.Hex(),
+		TxIndex:     log.TxIndex,
+		LogIndex:    log.Index,
+		Timestamp:   blockTime,
+		CreatedAt:   time.Now(),
+	}
+
+	return transfer, nil
+}
+
+// ParseTransferSingleLog parses an ERC-1155 TransferSingle log into a Transfer
+func ParseTransferSingleLog(log types.Log, blockTime time.Time) (*models.Transfer, error) {
+	if len(log.Topics) != 4 {
+		return nil, fmt.Errorf("invalid TransferSingle event: expected 4 topics, got %d", len(log.Topics))
+	}
+
+	if log.Topics[0] != ERC1155TransferSingleEventSignature {
+		return nil, fmt.Errorf("not a TransferSingle event")
+	}
+
+	if len(log.Data) != 64 {
+		return nil, fmt.Errorf("invalid TransferSingle event data: expected 64 bytes, got %d", len(log.Data))
+	}
+
+	id := new(big.Int).SetBytes(log.Data[:32])
+	value := new(big.Int).SetBytes(log.Data[32:64])
+
+	return newERC1155Transfer(log, blockTime, EventSignatureTransferSingle, id, value, 0), nil
+}
+
+// ParseTransferBatchLog parses an ERC-1155 TransferBatch log into one Transfer per id
+// Records share tx_hash and log_index and are distinguished by BatchIndex
+func ParseTransferBatchLog(log types.Log, blockTime time.Time) ([]*models.Transfer, error) {
+	if len(log.Topics) != 4 {
+		return nil, fmt.Errorf("invalid TransferBatch event: expected 4 topics, got %d", len(log.Topics))
+	}
+
+	if log.Topics[0] != ERC1155TransferBatchEventSignature {
+		return nil, fmt.Errorf("not a TransferBatch event")
+	}
+
+	if len(log.Data) < 64 {
+		return nil, fmt.Errorf("invalid TransferBatch event data: expected at least 64 bytes, got %d", len(log.Data))
+	}
+
+	ids, err := decodeUint256Array(log.Data, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TransferBatch ids: %w", err)
+	}
+	values, err := decodeUint256Array(log.Data, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TransferBatch values: %w", err)
+	}
+	if len(ids) != len(values) {
+		return nil, fmt.Errorf("invalid TransferBatch event: %d ids but %d values", len(ids), len(values))
+	}
+
+	transfers := make([]*models.Transfer, len(ids))
+	for i := range ids {
+		transfers[i] = newERC1155Transfer(log, blockTime, EventSignatureTransferBatch, ids[i], values[i], uint(i))
+	}
+
+	return transfers, nil
+}
+
+func newERC1155Transfer(log types.Log, blockTime time.Time, signature string, id, value *big.Int, batchIndex uint) *models.Transfer {
+	operator := common.BytesToAddress(log.Topics[1].Bytes())
+	from := common.BytesToAddress(log.Topics[2].Bytes())
+	to := common.BytesToAddress(log.Topics[3].Bytes())
+
+	valueStr := value.String()
+	decimalValue, err := primitive.ParseDecimal128(valueStr)
+	overflow := err != nil
+	if overflow {
+		decimalValue = primitive.NewDecimal128(0, 0)
+	}
+	// ERC-1155 amounts are plain unit counts, not 18-decimal fixed point
+	valueDecimal, _ := new(big.Float).SetInt(value).Float64()
+
+	return &models.Transfer{
+		EventSignature: signature,
+		Standard:       models.StandardERC1155,
+		Token:          strings.ToLower(log.Address.Hex()),
+		Operator:       strings.ToLower(operator.Hex()),
+		TokenID:        id.String(),
+		BatchIndex:     batchIndex,
+		Kind:           transferKind(from, to),
+		From:           strings.ToLower(from.Hex()),
+		To:             strings.ToLower(to.Hex()),
+		Value:          decimalValue,
+		ValueString:    valueStr,
+		ValueDecimal:   valueDecimal,
+		ValueOverflow:  overflow,
+		BlockNumber:    log.BlockNumber,
+		BlockHash:      log.BlockHash.Hex(),
+		TxHash:         log.TxHash.Hex(),
+		TxIndex:        log.TxIndex,
+		LogIndex:       log.Index,
+		Timestamp:      blockTime,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// decodeUint256Array decodes an ABI-encoded uint256[] whose offset is stored at data[head:head+32]
+func decodeUint256Array(data []byte, head int) ([]*big.Int, error) {
+	if head < 0 || len(data) < head+32 {
+		return nil, fmt.Errorf("array offset out of range")
+	}
+	offset := new(big.Int).SetBytes(data[head : head+32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data)-32) {
+		return nil, fmt.Errorf("array offset out of range")
+	}
+	start := int(offset.Uint64())
+
+	length := new(big.Int).SetBytes(data[start : start+32])
+	available := uint64(len(data)-start-32) / 32
+	if !length.IsUint64() || length.Uint64() > available {
+		return nil, fmt.Errorf("array length out of range")
+	}
+
+	n := int(length.Uint64())
+	values := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		pos := start + 32 + i*32
+		values[i] = new(big.Int).SetBytes(data[pos : pos+32])
+	}
+
+	return values, nil
+}
+
+// ParseApprovalLog parses an ERC-20 Approval log
+// ERC-721 Approval shares the signature but indexes tokenId as a fourth topic and is rejected
+func ParseApprovalLog(log types.Log, blockTime time.Time) (*models.Approval, error) {
+	if len(log.Topics) != 3 {
+		return nil, fmt.Errorf("invalid Approval event: expected 3 topics, got %d", len(log.Topics))
+	}
+
+	if log.Topics[0] != ERC20ApprovalEventSignature {
+		return nil, fmt.Errorf("not an Approval event")
+	}
+
+	if len(log.Data) != 32 {
+		return nil, fmt.Errorf("invalid Approval event data: expected 32 bytes, got %d", len(log.Data))
+	}
+
+	owner := common.BytesToAddress(log.Topics[1].Bytes())
+	spender := common.BytesToAddress(log.Topics[2].Bytes())
+	value := new(big.Int).SetBytes(log.Data)
+
+	approval := &models.Approval{
+		EventSignature: EventSignatureApproval,
+		Token:          strings.ToLower(log.Address.Hex()),
+		Owner:          strings.ToLower(owner.Hex()),
+		Spender:        strings.ToLower(spender.Hex()),
+		Value:          value.String(),
+		Unlimited:      value.Cmp(UnlimitedApprovalThreshold) >= 0,
+		BlockNumber:    log.BlockNumber,
+		BlockHash:      log.BlockHash.Hex(),
+		TxHash:         log.TxHash.Hex(),
+		TxIndex:        log.TxIndex,
+		LogIndex:       log.Index,
+		Timestamp:      blockTime,
+		CreatedAt:      time.Now(),
+	}
+
+	return approval, nil
+}
+
+// IsZeroAddress reports whether a lowercase hex address is the zero address
+func IsZeroAddress(address string) bool {
+	return address == zeroAddress
+}
+
+// parseValueDecimal converts wei to a decimal representation assuming 18 decimals
+// ERC-20 values are rescaled with the token's actual decimals when stored
 func parseValueDecimal(value *big.Int) float64 {
-	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
-	valueFloat := new(big.Float).SetInt(value)
-	result, _ := new(big.Float).Quo(valueFloat, divisor).Float64()
-	return result
+	return ScaleValue(value, DefaultTokenDecimals)
 }
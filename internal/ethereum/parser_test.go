@@ -0,0 +1,255 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// abiWords concatenates 32-byte big-endian words
+func abiWords(words ...*big.Int) []byte {
+	data := make([]byte, 0, 32*len(words))
+	for _, word := range words {
+		data = append(data, word.FillBytes(make([]byte, 32))...)
+	}
+	return data
+}
+
+// addressTopic left-pads an address to an indexed event topic
+func addressTopic(address common.Address) common.Hash {
+	return common.BytesToHash(address.Bytes())
+}
+
+func TestDecodeUint256Array(t *testing.T) {
+	n := big.NewInt
+	huge := new(big.Int).Lsh(big.NewInt(1), 64) // Does not fit in uint64
+
+	tests := []struct {
+		name    string
+		data    []byte
+		head    int
+		want    []int64
+		wantErr bool
+	}{
+		{
+			name: "two arrays, first",
+			// ids at 0x40: [1, 2]; values at 0xa0: [10, 20]
+			data: abiWords(n(0x40), n(0xa0), n(2), n(1), n(2), n(2), n(10), n(20)),
+			head: 0,
+			want: []int64{1, 2},
+		},
+		{
+			name: "two arrays, second",
+			data: abiWords(n(0x40), n(0xa0), n(2), n(1), n(2), n(2), n(10), n(20)),
+			head: 32,
+			want: []int64{10, 20},
+		},
+		{
+			name: "empty array in the last word",
+			data: abiWords(n(0x20), n(0)),
+			want: []int64{},
+		},
+		{
+			name:    "offset past the last word",
+			data:    abiWords(n(0x40), n(0)),
+			wantErr: true,
+		},
+		{
+			name:    "offset beyond uint64",
+			data:    abiWords(huge, n(0)),
+			wantErr: true,
+		},
+		{
+			name:    "length past the data",
+			data:    abiWords(n(0x20), n(3), n(1), n(2)),
+			wantErr: true,
+		},
+		{
+			name:    "length beyond uint64",
+			data:    abiWords(n(0x20), huge),
+			wantErr: true,
+		},
+		{
+			name:    "head past the data",
+			data:    abiWords(n(0x20), n(0)),
+			head:    64,
+			wantErr: true,
+		},
+		{
+			name:    "truncated data",
+			data:    make([]byte, 16),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeUint256Array(tt.data, tt.head)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeUint256Array() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("decodeUint256Array() returned %d values, want %d", len(got), len(tt.want))
+			}
+			for i, value := range got {
+				if value.Cmp(big.NewInt(tt.want[i])) != 0 {
+					t.Errorf("value %d = %s, want %d", i, value, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseNFTTransferLog(t *testing.T) {
+	collection := common.HexToAddress("0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D")
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	tokenID := common.BigToHash(big.NewInt(7804))
+
+	tests := []struct {
+		name      string
+		log       types.Log
+		wantErr   bool
+		wantFrom  string
+		wantTo    string
+		wantToken string
+	}{
+		{
+			name:      "transfer",
+			log:       types.Log{Address: collection, Topics: []common.Hash{ERC20TransferEventSignature, addressTopic(alice), addressTopic(bob), tokenID}},
+			wantFrom:  "0x1111111111111111111111111111111111111111",
+			wantTo:    "0x2222222222222222222222222222222222222222",
+			wantToken: "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d",
+		},
+		{
+			name:      "mint",
+			log:       types.Log{Address: collection, Topics: []common.Hash{ERC20TransferEventSignature, {}, addressTopic(bob), tokenID}},
+			wantFrom:  zeroAddress,
+			wantTo:    "0x2222222222222222222222222222222222222222",
+			wantToken: "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d",
+		},
+		{
+			name:    "erc-20 transfer",
+			log:     types.Log{Address: collection, Topics: []common.Hash{ERC20TransferEventSignature, addressTopic(alice), addressTopic(bob)}, Data: tokenID.Bytes()},
+			wantErr: true,
+		},
+		{
+			name:    "unexpected data",
+			log:     types.Log{Address: collection, Topics: []common.Hash{ERC20TransferEventSignature, addressTopic(alice), addressTopic(bob), tokenID}, Data: tokenID.Bytes()},
+			wantErr: true,
+		},
+		{
+			name:    "other event",
+			log:     types.Log{Address: collection, Topics: []common.Hash{ERC20ApprovalEventSignature, addressTopic(alice), addressTopic(bob), tokenID}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.log.BlockNumber, tt.log.Index = 100, 3
+			transfer, err := ParseNFTTransferLog(tt.log, time.Unix(1700000000, 0))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseNFTTransferLog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if transfer.TokenID != "7804" {
+				t.Errorf("TokenID = %s, want 7804", transfer.TokenID)
+			}
+			if transfer.From != tt.wantFrom || transfer.To != tt.wantTo || transfer.Token != tt.wantToken {
+				t.Errorf("transfer = %s %s -> %s, want %s %s -> %s", transfer.Token, transfer.From, transfer.To, tt.wantToken, tt.wantFrom, tt.wantTo)
+			}
+			if transfer.BlockNumber != 100 || transfer.LogIndex != 3 {
+				t.Errorf("position = %d/%d, want 100/3", transfer.BlockNumber, transfer.LogIndex)
+			}
+		})
+	}
+}
+
+func TestParseApprovalLog(t *testing.T) {
+	owner := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	spender := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	topics := []common.Hash{ERC20ApprovalEventSignature, addressTopic(owner), addressTopic(spender)}
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	threshold := new(big.Int).Lsh(big.NewInt(1), 128)
+
+	tests := []struct {
+		name          string
+		log           types.Log
+		wantErr       bool
+		wantValue     string
+		wantUnlimited bool
+	}{
+		{
+			name:      "limited",
+			log:       types.Log{Address: usdc, Topics: topics, Data: abiWords(big.NewInt(1000000))},
+			wantValue: "1000000",
+		},
+		{
+			name:      "revoked",
+			log:       types.Log{Address: usdc, Topics: topics, Data: abiWords(big.NewInt(0))},
+			wantValue: "0",
+		},
+		{
+			name:          "max uint256",
+			log:           types.Log{Address: usdc, Topics: topics, Data: abiWords(maxUint256)},
+			wantValue:     maxUint256.String(),
+			wantUnlimited: true,
+		},
+		{
+			name:          "at the unlimited threshold",
+			log:           types.Log{Address: usdc, Topics: topics, Data: abiWords(threshold)},
+			wantValue:     threshold.String(),
+			wantUnlimited: true,
+		},
+		{
+			name:      "below the unlimited threshold",
+			log:       types.Log{Address: usdc, Topics: topics, Data: abiWords(new(big.Int).Sub(threshold, big.NewInt(1)))},
+			wantValue: new(big.Int).Sub(threshold, big.NewInt(1)).String(),
+		},
+		{
+			name:    "erc-721 approval",
+			log:     types.Log{Address: usdc, Topics: append(topics, common.BigToHash(big.NewInt(1)))},
+			wantErr: true,
+		},
+		{
+			name:    "short data",
+			log:     types.Log{Address: usdc, Topics: topics, Data: []byte{1}},
+			wantErr: true,
+		},
+		{
+			name:    "transfer event",
+			log:     types.Log{Address: usdc, Topics: []common.Hash{ERC20TransferEventSignature, addressTopic(owner), addressTopic(spender)}, Data: abiWords(big.NewInt(1))},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			approval, err := ParseApprovalLog(tt.log, time.Unix(1700000000, 0))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseApprovalLog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if approval.Value != tt.wantValue || approval.Unlimited != tt.wantUnlimited {
+				t.Errorf("approval = %s (unlimited %v), want %s (unlimited %v)", approval.Value, approval.Unlimited, tt.wantValue, tt.wantUnlimited)
+			}
+			if approval.Owner != "0x1111111111111111111111111111111111111111" || approval.Spender != "0x2222222222222222222222222222222222222222" {
+				t.Errorf("owner/spender = %s/%s", approval.Owner, approval.Spender)
+			}
+			if approval.Token != "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48" {
+				t.Errorf("token = %s, want lowercase USDC", approval.Token)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package ethereum
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"sort"
@@ -9,9 +10,11 @@ import (
 	"time"
 
 	"pagrin/internal/metrics"
+	"pagrin/internal/models"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 // ProviderPool manages multiple Ethereum RPC providers with automatic failover
@@ -19,7 +22,8 @@ import (
 type ProviderPool struct {
 	providers []*Provider
 	mu        sync.RWMutex
-	current   int // Current provider index for round-robin
+	current   int         // Current provider index for round-robin
+	aimd      *AIMDConfig // Learns each provider's eth_getLogs range limit when set
 }
 
 // NewProviderPool creates a new provider pool from a list of providers
@@ -43,7 +47,11 @@ func NewProviderPool(providers []*Provider) *ProviderPool {
 func (p *ProviderPool) GetHealthyProviders() []*Provider {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
+	return p.healthyLocked()
+}
 
+// healthyLocked returns the healthy providers; the caller must hold p.mu
+func (p *ProviderPool) healthyLocked() []*Provider {
 	healthy := make([]*Provider, 0, len(p.providers))
 	for _, provider := range p.providers {
 		if provider.IsHealthy() {
@@ -59,7 +67,7 @@ func (p *ProviderPool) SelectProvider() (*Provider, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	healthy := p.GetHealthyProviders()
+	healthy := p.healthyLocked()
 	if len(healthy) > 0 {
 		// Use round-robin among healthy providers
 		selected := healthy[p.current%len(healthy)]
@@ -75,13 +83,16 @@ func (p *ProviderPool) SelectProvider() (*Provider, error) {
 	return nil, fmt.Errorf("no providers available")
 }
 
-// FilterLogs executes eth_getLogs with automatic failover across providers
+// filterLogs executes eth_getLogs with automatic failover across providers
 // Tries each healthy provider in order until one succeeds
-func (p *ProviderPool) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+// A provider rejecting the range for its result size returns a *logLimitError at once
+func (p *ProviderPool) filterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
 	// Calculate block range to determine which providers can handle this request
 	blockRange := query.ToBlock.Uint64() - query.FromBlock.Uint64() + 1
 
 	var lastErr error
+	var widest *Provider // Provider with the widest range limit below the request
+	var widestLimit uint64
 	attemptedProviders := make(map[string]bool)
 
 	// Try up to all providers (with retry logic)
@@ -93,10 +104,13 @@ func (p *ProviderPool) FilterLogs(ctx context.Context, query ethereum.FilterQuer
 		}
 
 		// Check if provider supports this block range
-		if blockRange > provider.MaxRange {
+		if limit := provider.logRangeLimit(); blockRange > limit {
 			// Skip this provider, try next
 			attemptedProviders[provider.Name] = true
-			lastErr = fmt.Errorf("provider %s max range (%d) exceeded by request (%d)", provider.Name, provider.MaxRange, blockRange)
+			lastErr = fmt.Errorf("provider %s max range (%d) exceeded by request (%d)", provider.Name, limit, blockRange)
+			if limit > widestLimit {
+				widest, widestLimit = provider, limit
+			}
 			continue
 		}
 
@@ -115,8 +129,12 @@ func (p *ProviderPool) FilterLogs(ctx context.Context, query ethereum.FilterQuer
 		duration := time.Since(start)
 
 		// Record metrics
-		metrics.RPCRequestDuration.WithLabelValues(provider.Name, "FilterLogs").Observe(duration.Seconds())
-		metrics.RPCRequestsTotal.WithLabelValues(provider.Name, "FilterLogs").Inc()
+		metrics.RPCRequestDuration.WithLabelValues(provider.chain, provider.Name, "FilterLogs").Observe(duration.Seconds())
+		provider.observeLatency(duration)
+		metrics.RPCRequestsTotal.WithLabelValues(provider.chain, provider.Name, "FilterLogs").Inc()
+		if p.aimd != nil {
+			provider.learnLogRange(*p.aimd, blockRange, len(logs), duration, err)
+		}
 
 		if err == nil {
 			// Success! Record which provider succeeded for observability
@@ -125,6 +143,15 @@ func (p *ProviderPool) FilterLogs(ctx context.Context, query ethereum.FilterQuer
 			return logs, nil
 		}
 
+		// Too many results is a property of the range, not of the provider's health
+		if limitErr := asLogLimitError(provider, err); limitErr != nil {
+			return nil, limitErr
+		}
+		// So is our own timeout on a multi-block range, unless the caller's context expired
+		if blockRange > 1 && errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, &logLimitError{provider: provider, err: err}
+		}
+
 		// Failure - record and try next provider
 		provider.RecordFailure(err)
 		lastErr = fmt.Errorf("provider %s failed: %w", provider.Name, err)
@@ -136,6 +163,11 @@ func (p *ProviderPool) FilterLogs(ctx context.Context, query ethereum.FilterQuer
 		}
 	}
 
+	// Learned limits shrink below the batch size; FilterLogs splits the range to fit the widest
+	if p.aimd != nil && widest != nil {
+		return nil, &logLimitError{provider: widest, suggestedTo: query.FromBlock.Uint64() + widestLimit - 1, err: lastErr}
+	}
+
 	return nil, fmt.Errorf("all providers failed, last error: %w", lastErr)
 }
 
@@ -162,8 +194,9 @@ func (p *ProviderPool) BlockByNumber(ctx context.Context, number *big.Int) (*typ
 		block, err := provider.GetClient().BlockByNumber(providerCtx, number)
 		duration := time.Since(start)
 
-		metrics.RPCRequestDuration.WithLabelValues(provider.Name, "BlockByNumber").Observe(duration.Seconds())
-		metrics.RPCRequestsTotal.WithLabelValues(provider.Name, "BlockByNumber").Inc()
+		metrics.RPCRequestDuration.WithLabelValues(provider.chain, provider.Name, "BlockByNumber").Observe(duration.Seconds())
+		provider.observeLatency(duration)
+		metrics.RPCRequestsTotal.WithLabelValues(provider.chain, provider.Name, "BlockByNumber").Inc()
 
 		if err == nil {
 			provider.RecordSuccess()
@@ -205,8 +238,9 @@ func (p *ProviderPool) HeaderByNumber(ctx context.Context, number *big.Int) (*ty
 		header, err := provider.GetClient().HeaderByNumber(providerCtx, number)
 		duration := time.Since(start)
 
-		metrics.RPCRequestDuration.WithLabelValues(provider.Name, "HeaderByNumber").Observe(duration.Seconds())
-		metrics.RPCRequestsTotal.WithLabelValues(provider.Name, "HeaderByNumber").Inc()
+		metrics.RPCRequestDuration.WithLabelValues(provider.chain, provider.Name, "HeaderByNumber").Observe(duration.Seconds())
+		provider.observeLatency(duration)
+		metrics.RPCRequestsTotal.WithLabelValues(provider.chain, provider.Name, "HeaderByNumber").Inc()
 
 		if err == nil {
 			provider.RecordSuccess()
@@ -225,6 +259,177 @@ func (p *ProviderPool) HeaderByNumber(ctx context.Context, number *big.Int) (*ty
 	return nil, fmt.Errorf("all providers failed, last error: %w", lastErr)
 }
 
+// BlockReceipts executes eth_getBlockReceipts with automatic failover
+func (p *ProviderPool) BlockReceipts(ctx context.Context, number *big.Int) (types.Receipts, error) {
+	var receipts types.Receipts
+	err := p.call(ctx, "BlockReceipts", func(ctx context.Context, provider *Provider) error {
+		var err error
+		receipts, err = provider.GetClient().BlockReceipts(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(number.Int64())))
+		return err
+	})
+	return receipts, err
+}
+
+// CallContract executes eth_call against the latest block with automatic failover
+// EVM execution errors (reverts) are deterministic, so they are returned without trying other providers
+func (p *ProviderPool) CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	var output []byte
+	var execErr error
+	err := p.call(ctx, "CallContract", func(ctx context.Context, provider *Provider) error {
+		var err error
+		output, err = provider.GetClient().CallContract(ctx, msg, nil)
+		if isExecutionError(err) {
+			execErr = err
+			return nil
+		}
+		return err
+	})
+	if execErr != nil {
+		return nil, execErr
+	}
+	return output, err
+}
+
+// TraceBlock returns the value-bearing internal calls of a block with automatic failover
+// Providers without debug_traceBlockByNumber or trace_block are skipped without tripping their circuit breaker
+func (p *ProviderPool) TraceBlock(ctx context.Context, number uint64) ([]InternalCall, error) {
+	supportsTrace := func(provider *Provider) bool {
+		return provider.traces.get() != traceAPINone
+	}
+
+	var calls []InternalCall
+	err := p.callWhere(ctx, "TraceBlock", supportsTrace, func(ctx context.Context, provider *Provider) error {
+		var err error
+		calls, err = traceBlock(ctx, provider.GetClient().Client(), &provider.traces, number)
+		return err
+	})
+	if errors.Is(err, errMethodUnsupported) {
+		return nil, ErrTraceUnsupported
+	}
+	return calls, err
+}
+
+// call runs fn against healthy providers with automatic failover, recording metrics under method
+// Follows the same selection and retry rules as the typed methods above
+func (p *ProviderPool) call(ctx context.Context, method string, fn func(ctx context.Context, provider *Provider) error) error {
+	return p.callWhere(ctx, method, nil, fn)
+}
+
+// callWhere is call restricted to providers accepted by accept (nil accepts all)
+// fn returning errMethodUnsupported moves on to the next provider without recording a failure
+func (p *ProviderPool) callWhere(ctx context.Context, method string, accept func(provider *Provider) bool, fn func(ctx context.Context, provider *Provider) error) error {
+	var lastErr error
+	attemptedProviders := make(map[string]bool)
+
+	maxAttempts := len(p.providers) * 2
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		provider, err := p.SelectProvider()
+		if err != nil {
+			return fmt.Errorf("no healthy providers available: %w", err)
+		}
+
+		if attemptedProviders[provider.Name] && attempt < len(p.providers) {
+			continue
+		}
+
+		if accept != nil && !accept(provider) {
+			attemptedProviders[provider.Name] = true
+			lastErr = fmt.Errorf("provider %s: %w", provider.Name, errMethodUnsupported)
+			continue
+		}
+
+		providerCtx, cancel := context.WithTimeout(ctx, provider.Timeout)
+		start := time.Now()
+		err = fn(providerCtx, provider)
+		duration := time.Since(start)
+		cancel()
+
+		metrics.RPCRequestDuration.WithLabelValues(provider.chain, provider.Name, method).Observe(duration.Seconds())
+		provider.observeLatency(duration)
+		metrics.RPCRequestsTotal.WithLabelValues(provider.chain, provider.Name, method).Inc()
+
+		if err == nil {
+			provider.RecordSuccess()
+			return nil
+		}
+
+		if errors.Is(err, errMethodUnsupported) {
+			// Missing API, not an unhealthy provider
+			attemptedProviders[provider.Name] = true
+			lastErr = fmt.Errorf("provider %s: %w", provider.Name, err)
+			continue
+		}
+
+		provider.RecordFailure(err)
+		lastErr = fmt.Errorf("provider %s failed: %w", provider.Name, err)
+		attemptedProviders[provider.Name] = true
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("context cancelled: %w", ctx.Err())
+		}
+	}
+
+	return fmt.Errorf("all providers failed, last error: %w", lastErr)
+}
+
+// ChainID returns the chain ID reported by the first provider that answers eth_chainId
+// Queried directly rather than through call, since it runs once at startup before metrics are labelled
+func (p *ProviderPool) ChainID(ctx context.Context) (uint64, error) {
+	var lastErr error
+	for _, provider := range p.providers {
+		providerCtx, cancel := context.WithTimeout(ctx, provider.Timeout)
+		chainID, err := provider.client.ChainID(providerCtx)
+		cancel()
+		if err == nil {
+			return chainID.Uint64(), nil
+		}
+		lastErr = fmt.Errorf("provider %s failed: %w", provider.Name, err)
+	}
+	if lastErr == nil {
+		return 0, fmt.Errorf("no providers available")
+	}
+	return 0, fmt.Errorf("all providers failed, last error: %w", lastErr)
+}
+
+// SetChainID labels the pool's provider metrics with chainID
+// Must be called before the pool is used concurrently
+func (p *ProviderPool) SetChainID(chainID uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, provider := range p.providers {
+		provider.chain = metrics.ChainLabel(chainID)
+		metrics.ProviderState.WithLabelValues(provider.chain, provider.Name).Set(float64(StateHealthy))
+	}
+}
+
+// SetAIMD makes providers learn their eth_getLogs range limit from request durations and log counts
+// Must be called before the pool is used concurrently
+func (p *ProviderPool) SetAIMD(cfg AIMDConfig) {
+	p.aimd = &cfg
+}
+
+// LogRangeLimit returns the widest eth_getLogs range a healthy provider is sent, 0 if none is healthy
+func (p *ProviderPool) LogRangeLimit() uint64 {
+	var widest uint64
+	for _, provider := range p.GetHealthyProviders() {
+		widest = max(widest, provider.logRangeLimit())
+	}
+	return widest
+}
+
+// Status returns the state and latency of every provider in the pool
+func (p *ProviderPool) Status() []models.ProviderStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	statuses := make([]models.ProviderStatus, 0, len(p.providers))
+	for _, provider := range p.providers {
+		statuses = append(statuses, provider.Status())
+	}
+	return statuses
+}
+
 // Close closes all provider connections
 func (p *ProviderPool) Close() {
 	p.mu.Lock()
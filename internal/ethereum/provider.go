@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"pagrin/internal/metrics"
+	"pagrin/internal/models"
 
 	"github.com/ethereum/go-ethereum/ethclient"
 )
@@ -19,6 +20,21 @@ const (
 	StateHalfOpen // Testing if provider recovered
 )
 
+// latencyWeight is the weight of the newest request in a provider's moving average latency
+const latencyWeight = 0.2
+
+// String returns the state as reported on /api/v1/status
+func (s ProviderState) String() string {
+	switch s {
+	case StateUnhealthy:
+		return models.ProviderStateUnhealthy
+	case StateHalfOpen:
+		return models.ProviderStateHalfOpen
+	default:
+		return models.ProviderStateHealthy
+	}
+}
+
 // Provider represents a single Ethereum RPC endpoint with health tracking
 type Provider struct {
 	Name     string
@@ -27,7 +43,11 @@ type Provider struct {
 	MaxRange uint64 // Maximum block range for eth_getLogs
 	Timeout  time.Duration
 
+	HeaderBatchSize int // Headers per JSON-RPC batch request
+
 	client *ethclient.Client
+	traces traceSupport // Trace API detected on first use
+	chain  string       // chain_id metrics label, set once the pool's chain is known
 
 	// Circuit breaker state
 	mu              sync.RWMutex
@@ -36,6 +56,8 @@ type Provider struct {
 	successCount    int
 	lastFailureTime time.Time
 	lastSuccessTime time.Time
+	latency         time.Duration // Moving average of request durations
+	rangeLimit      uint64        // Learned eth_getLogs range, 0 until the pool learns limits
 
 	// Circuit breaker config
 	failureThreshold int
@@ -46,7 +68,7 @@ type Provider struct {
 }
 
 // NewProvider creates a new provider instance
-func NewProvider(name, url string, weight int, maxRange uint64, timeout time.Duration, cbConfig CircuitBreakerConfig) (*Provider, error) {
+func NewProvider(name, url string, weight int, maxRange uint64, headerBatchSize int, timeout time.Duration, cbConfig CircuitBreakerConfig) (*Provider, error) {
 	client, err := ethclient.Dial(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to provider %s: %w", name, err)
@@ -58,6 +80,7 @@ func NewProvider(name, url string, weight int, maxRange uint64, timeout time.Dur
 		Weight:           weight,
 		MaxRange:         maxRange,
 		Timeout:          timeout,
+		HeaderBatchSize:  headerBatchSize,
 		client:           client,
 		state:            StateHealthy,
 		failureThreshold: cbConfig.FailureThreshold,
@@ -83,7 +106,7 @@ func (p *Provider) IsHealthy() bool {
 			p.mu.RUnlock()
 			p.mu.Lock()
 			if p.state == StateUnhealthy && time.Since(p.lastFailureTime) > p.timeout {
-				p.state = StateHalfOpen
+				p.setState(StateHalfOpen)
 				p.halfOpenCalls = 0
 			}
 			p.mu.Unlock()
@@ -107,19 +130,19 @@ func (p *Provider) RecordSuccess() {
 	p.failureCount = 0
 
 	// Update metrics
-	metrics.RPCRequestsTotal.WithLabelValues(p.Name, "success").Inc()
+	metrics.RPCRequestsTotal.WithLabelValues(p.chain, p.Name, "success").Inc()
 
 	// State transitions
 	if p.state == StateHalfOpen {
 		p.halfOpenCalls++
 		if p.successCount >= p.successThreshold {
-			p.state = StateHealthy
+			p.setState(StateHealthy)
 			p.halfOpenCalls = 0
 			p.successCount = 0
 		}
 	} else if p.state == StateUnhealthy {
 		// Shouldn't happen, but handle gracefully
-		p.state = StateHalfOpen
+		p.setState(StateHalfOpen)
 	}
 }
 
@@ -139,16 +162,57 @@ func (p *Provider) RecordFailure(err error) {
 	}
 
 	// Update metrics
-	metrics.RPCErrorsTotal.WithLabelValues(p.Name, errorCode).Inc()
+	metrics.RPCErrorsTotal.WithLabelValues(p.chain, p.Name, errorCode).Inc()
 
 	// State transitions
 	if p.state == StateHalfOpen {
 		// Any failure in half-open immediately goes to unhealthy
-		p.state = StateUnhealthy
+		p.setState(StateUnhealthy)
 		p.halfOpenCalls = 0
 	} else if p.failureCount >= p.failureThreshold {
-		p.state = StateUnhealthy
+		p.setState(StateUnhealthy)
+	}
+}
+
+// setState moves the circuit breaker to state; callers hold mu
+func (p *Provider) setState(state ProviderState) {
+	p.state = state
+	metrics.ProviderState.WithLabelValues(p.chain, p.Name).Set(float64(state))
+}
+
+// observeLatency folds a request's duration into the provider's moving average latency
+func (p *Provider) observeLatency(duration time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.latency == 0 {
+		p.latency = duration
+	} else {
+		p.latency = time.Duration(latencyWeight*float64(duration) + (1-latencyWeight)*float64(p.latency))
+	}
+	metrics.ProviderLatency.WithLabelValues(p.chain, p.Name).Set(p.latency.Seconds())
+}
+
+// Status returns the provider's circuit breaker state and latency
+func (p *Provider) Status() models.ProviderStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status := models.ProviderStatus{
+		Name:          p.Name,
+		State:         p.state.String(),
+		LatencyMs:     float64(p.latency.Microseconds()) / 1000,
+		LogRangeLimit: p.rangeLimitLocked(),
+	}
+	if !p.lastSuccessTime.IsZero() {
+		lastSuccess := p.lastSuccessTime
+		status.LastSuccessAt = &lastSuccess
+	}
+	if !p.lastFailureTime.IsZero() {
+		lastFailure := p.lastFailureTime
+		status.LastFailureAt = &lastFailure
 	}
+	return status
 }
 
 // GetClient returns the ethclient for this provider
@@ -0,0 +1,100 @@
+package ethereum
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pagrin/internal/metrics"
+	"pagrin/internal/models"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestProviderStateString(t *testing.T) {
+	tests := []struct {
+		state ProviderState
+		want  string
+	}{
+		{StateHealthy, models.ProviderStateHealthy},
+		{StateUnhealthy, models.ProviderStateUnhealthy},
+		{StateHalfOpen, models.ProviderStateHalfOpen},
+		{ProviderState(7), models.ProviderStateHealthy},
+	}
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.want {
+			t.Errorf("ProviderState(%d).String() = %s, want %s", tt.state, got, tt.want)
+		}
+	}
+}
+
+func TestObserveLatency(t *testing.T) {
+	p := &Provider{Name: "latency-test", chain: "1"}
+
+	// The first request sets the average, later ones move it by latencyWeight
+	p.observeLatency(100 * time.Millisecond)
+	if got := p.Status().LatencyMs; got != 100 {
+		t.Errorf("LatencyMs = %v, want 100", got)
+	}
+	p.observeLatency(200 * time.Millisecond)
+	if got := p.Status().LatencyMs; got != 120 {
+		t.Errorf("LatencyMs = %v, want 120", got)
+	}
+
+	var metric dto.Metric
+	if err := metrics.ProviderLatency.WithLabelValues("1", "latency-test").Write(&metric); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := metric.GetGauge().GetValue(); got != 0.12 {
+		t.Errorf("provider latency gauge = %v, want 0.12", got)
+	}
+}
+
+func TestProviderStatus(t *testing.T) {
+	p := &Provider{Name: "status-test", chain: "1", state: StateHealthy, failureThreshold: 2, successThreshold: 1}
+	stateGauge := func() ProviderState {
+		var metric dto.Metric
+		if err := metrics.ProviderState.WithLabelValues("1", "status-test").Write(&metric); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		return ProviderState(metric.GetGauge().GetValue())
+	}
+
+	status := p.Status()
+	if status.State != models.ProviderStateHealthy || status.LastSuccessAt != nil || status.LastFailureAt != nil {
+		t.Errorf("Status() = %+v, want healthy without requests", status)
+	}
+
+	// Below the failure threshold the provider stays healthy
+	p.RecordFailure(errors.New("timeout"))
+	if status := p.Status(); status.State != models.ProviderStateHealthy || status.LastFailureAt == nil {
+		t.Errorf("Status() = %+v, want healthy with a failure time", status)
+	}
+
+	p.RecordFailure(errors.New("timeout"))
+	if state := p.Status().State; state != models.ProviderStateUnhealthy {
+		t.Errorf("Status().State = %s, want unhealthy", state)
+	}
+	if got := stateGauge(); got != StateUnhealthy {
+		t.Errorf("provider state gauge = %s, want unhealthy", got)
+	}
+
+	// With a zero timeout the next check probes the provider half-open
+	if !p.IsHealthy() {
+		t.Errorf("IsHealthy() = false, want a half-open probe")
+	}
+	if state := p.Status().State; state != models.ProviderStateHalfOpen {
+		t.Errorf("Status().State = %s, want half_open", state)
+	}
+	if got := stateGauge(); got != StateHalfOpen {
+		t.Errorf("provider state gauge = %s, want half_open", got)
+	}
+
+	p.RecordSuccess()
+	if status := p.Status(); status.State != models.ProviderStateHealthy || status.LastSuccessAt == nil {
+		t.Errorf("Status() = %+v, want healthy with a success time", status)
+	}
+	if got := stateGauge(); got != StateHealthy {
+		t.Errorf("provider state gauge = %s, want healthy", got)
+	}
+}
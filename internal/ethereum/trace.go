@@ -0,0 +1,356 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pagrin/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrTraceUnsupported is returned when no available endpoint supports a block trace API
+var ErrTraceUnsupported = errors.New("no provider supports debug_traceBlockByNumber or trace_block")
+
+// errMethodUnsupported marks an RPC failure caused by a missing API rather than an unhealthy provider
+var errMethodUnsupported = errors.New("method not supported")
+
+// traceAPI is the block trace API an endpoint has been found to support
+type traceAPI int
+
+const (
+	traceAPIUnknown traceAPI = iota
+	traceAPIDebug            // debug_traceBlockByNumber with callTracer (geth, erigon, reth)
+	traceAPIParity           // trace_block (erigon, nethermind, reth)
+	traceAPINone
+)
+
+// traceSupport caches which trace API an endpoint supports, detected on first use
+type traceSupport struct {
+	mu  sync.RWMutex
+	api traceAPI
+}
+
+func (t *traceSupport) get() traceAPI {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.api
+}
+
+func (t *traceSupport) set(api traceAPI) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.api = api
+}
+
+// InternalCall is a value-bearing call made by a contract during a transaction
+type InternalCall struct {
+	TxHash       string // Empty if the tracer did not report it
+	TxIndex      uint
+	TraceAddress string // Dot-separated path of the call in the transaction's call tree, e.g. "0.2"
+	Type         string // CALL, CREATE, CREATE2 or SELFDESTRUCT
+	From         common.Address
+	To           common.Address
+	Value        *big.Int
+}
+
+// traceBlock returns the successful value-bearing internal calls of a block
+// Tries debug_traceBlockByNumber first, then trace_block, and remembers what the endpoint supports
+// Returns errMethodUnsupported if the endpoint supports neither
+func traceBlock(ctx context.Context, client *rpc.Client, support *traceSupport, blockNumber uint64) ([]InternalCall, error) {
+	api := support.get()
+
+	if api == traceAPIUnknown || api == traceAPIDebug {
+		calls, err := debugTraceBlock(ctx, client, blockNumber)
+		if !isMethodNotFound(err) {
+			if err == nil {
+				support.set(traceAPIDebug)
+			}
+			return calls, err
+		}
+		if api == traceAPIDebug {
+			// Endpoint stopped supporting it (e.g. behind a load balancer) - detect again next time
+			support.set(traceAPIUnknown)
+			return nil, fmt.Errorf("%w: %v", errMethodUnsupported, err)
+		}
+	}
+
+	if api == traceAPIUnknown || api == traceAPIParity {
+		calls, err := parityTraceBlock(ctx, client, blockNumber)
+		if !isMethodNotFound(err) {
+			if err == nil {
+				support.set(traceAPIParity)
+			}
+			return calls, err
+		}
+		if api == traceAPIParity {
+			support.set(traceAPIUnknown)
+			return nil, fmt.Errorf("%w: %v", errMethodUnsupported, err)
+		}
+	}
+
+	support.set(traceAPINone)
+	return nil, errMethodUnsupported
+}
+
+// callFrame is a node of the callTracer output
+type callFrame struct {
+	Type  string       `json:"type"`
+	From  string       `json:"from"`
+	To    string       `json:"to"`
+	Value *hexutil.Big `json:"value"`
+	Error string       `json:"error"`
+	Calls []callFrame  `json:"calls"`
+}
+
+type debugTxTrace struct {
+	TxHash string    `json:"txHash"`
+	Result callFrame `json:"result"`
+	Error  string    `json:"error"`
+}
+
+func debugTraceBlock(ctx context.Context, client *rpc.Client, blockNumber uint64) ([]InternalCall, error) {
+	var traces []debugTxTrace
+	tracer := map[string]interface{}{"tracer": "callTracer"}
+	if err := client.CallContext(ctx, &traces, "debug_traceBlockByNumber", hexutil.EncodeUint64(blockNumber), tracer); err != nil {
+		return nil, err
+	}
+
+	calls := make([]InternalCall, 0)
+	for i, trace := range traces {
+		if trace.Error != "" {
+			return nil, fmt.Errorf("failed to trace transaction %d of block %d: %s", i, blockNumber, trace.Error)
+		}
+		// The top-level frame is the transaction itself; only its subcalls are internal
+		if trace.Result.Error != "" {
+			continue
+		}
+		for j, child := range trace.Result.Calls {
+			calls = collectFrames(calls, child, trace.TxHash, uint(i), strconv.Itoa(j))
+		}
+	}
+
+	return calls, nil
+}
+
+// collectFrames walks a call frame depth-first, skipping reverted subtrees
+func collectFrames(calls []InternalCall, frame callFrame, txHash string, txIndex uint, path string) []InternalCall {
+	if frame.Error != "" {
+		return calls
+	}
+
+	callType := strings.ToUpper(frame.Type)
+	transfersValue := callType == "CALL" || callType == "CREATE" || callType == "CREATE2" || callType == "SELFDESTRUCT"
+	if transfersValue && frame.Value != nil && frame.Value.ToInt().Sign() > 0 {
+		calls = append(calls, InternalCall{
+			TxHash:       txHash,
+			TxIndex:      txIndex,
+			TraceAddress: path,
+			Type:         callType,
+			From:         common.HexToAddress(frame.From),
+			To:           common.HexToAddress(frame.To),
+			Value:        frame.Value.ToInt(),
+		})
+	}
+
+	for i, child := range frame.Calls {
+		calls = collectFrames(calls, child, txHash, txIndex, path+"."+strconv.Itoa(i))
+	}
+	return calls
+}
+
+// parityTrace is an entry of the flat trace_block output
+type parityTrace struct {
+	Type   string `json:"type"` // call, create, suicide or reward
+	Action struct {
+		CallType      string       `json:"callType"`
+		From          string       `json:"from"`
+		To            string       `json:"to"`
+		Value         *hexutil.Big `json:"value"`
+		Address       string       `json:"address"`       // suicide: destroyed contract
+		RefundAddress string       `json:"refundAddress"` // suicide: beneficiary
+		Balance       *hexutil.Big `json:"balance"`       // suicide: amount sent
+	} `json:"action"`
+	Result *struct {
+		Address string `json:"address"` // create: new contract
+	} `json:"result"`
+	Error               string `json:"error"`
+	TraceAddress        []int  `json:"traceAddress"`
+	TransactionHash     string `json:"transactionHash"`
+	TransactionPosition *uint  `json:"transactionPosition"`
+}
+
+func parityTraceBlock(ctx context.Context, client *rpc.Client, blockNumber uint64) ([]InternalCall, error) {
+	var traces []parityTrace
+	if err := client.CallContext(ctx, &traces, "trace_block", hexutil.EncodeUint64(blockNumber)); err != nil {
+		return nil, err
+	}
+
+	// A failed frame reverts its whole subtree; traces are listed parent-first
+	reverted := make(map[string]bool)
+	calls := make([]InternalCall, 0)
+	for _, trace := range traces {
+		if trace.TransactionPosition == nil {
+			continue // Block and uncle rewards
+		}
+
+		path := joinTraceAddress(trace.TraceAddress)
+		key := trace.TransactionHash + ":" + path
+		if trace.Error != "" || reverted[trace.TransactionHash+":"+parentTraceAddress(trace.TraceAddress)] {
+			reverted[key] = true
+			continue
+		}
+		if len(trace.TraceAddress) == 0 {
+			continue // Top-level transaction, not an internal call
+		}
+
+		call := InternalCall{
+			TxHash:       trace.TransactionHash,
+			TxIndex:      *trace.TransactionPosition,
+			TraceAddress: path,
+		}
+		switch trace.Type {
+		case "call":
+			if trace.Action.CallType != "call" || trace.Action.Value == nil {
+				continue
+			}
+			call.Type = "CALL"
+			call.From = common.HexToAddress(trace.Action.From)
+			call.To = common.HexToAddress(trace.Action.To)
+			call.Value = trace.Action.Value.ToInt()
+		case "create":
+			if trace.Action.Value == nil || trace.Result == nil {
+				continue
+			}
+			call.Type = "CREATE"
+			call.From = common.HexToAddress(trace.Action.From)
+			call.To = common.HexToAddress(trace.Result.Address)
+			call.Value = trace.Action.Value.ToInt()
+		case "suicide":
+			if trace.Action.Balance == nil {
+				continue
+			}
+			call.Type = "SELFDESTRUCT"
+			call.From = common.HexToAddress(trace.Action.Address)
+			call.To = common.HexToAddress(trace.Action.RefundAddress)
+			call.Value = trace.Action.Balance.ToInt()
+		default:
+			continue
+		}
+
+		if call.Value.Sign() > 0 {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls, nil
+}
+
+func joinTraceAddress(address []int) string {
+	parts := make([]string, len(address))
+	for i, index := range address {
+		parts[i] = strconv.Itoa(index)
+	}
+	return strings.Join(parts, ".")
+}
+
+func parentTraceAddress(address []int) string {
+	if len(address) == 0 {
+		return "-" // The top-level frame has no parent
+	}
+	return joinTraceAddress(address[:len(address)-1])
+}
+
+// isMethodNotFound reports whether an RPC error means the method is not available on the endpoint
+func isMethodNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+		return true
+	}
+	// Some providers use other codes; "not available" alone is avoided as pruned nodes use it for missing state
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "method not found") ||
+		strings.Contains(msg, "does not exist") ||
+		strings.Contains(msg, "not supported") ||
+		strings.Contains(msg, "unsupported method") ||
+		strings.Contains(msg, "not enabled")
+}
+
+// EventSignatureInternalTransfer identifies ETH moved by a contract call inside a transaction
+const EventSignatureInternalTransfer = "InternalTransfer"
+
+// fetchInternalTransfers traces a block and returns its value-bearing internal calls as transfers
+// Each transfer is linked to its parent transaction and numbered within it by BatchIndex
+func (f *Fetcher) fetchInternalTransfers(ctx context.Context, block *types.Block) ([]*models.Transfer, error) {
+	if block == nil {
+		return nil, fmt.Errorf("missing block for internal transfers")
+	}
+	txs := block.Transactions()
+	if len(txs) == 0 {
+		return nil, nil
+	}
+
+	calls, err := f.client.TraceBlock(ctx, block.NumberU64())
+	if err != nil {
+		return nil, err
+	}
+
+	blockHash := block.Hash().Hex()
+	timestamp := time.Unix(int64(block.Time()), 0)
+
+	transfers := make([]*models.Transfer, 0, len(calls))
+	callsPerTx := make(map[uint]uint)
+	for _, call := range calls {
+		if call.TxIndex >= uint(len(txs)) {
+			return nil, fmt.Errorf("trace of block %d references transaction %d of %d", block.NumberU64(), call.TxIndex, len(txs))
+		}
+		txHash := txs[call.TxIndex].Hash().Hex()
+		if call.TxHash != "" && !strings.EqualFold(call.TxHash, txHash) {
+			// Trace came from a different fork than the block - let the batch retry
+			return nil, fmt.Errorf("trace for block %d does not match its transactions", block.NumberU64())
+		}
+
+		valueStr := call.Value.String()
+		decimalValue, err := primitive.ParseDecimal128(valueStr)
+		if err != nil {
+			decimalValue = primitive.NewDecimal128(0, 0)
+		}
+
+		transfers = append(transfers, &models.Transfer{
+			EventSignature: EventSignatureInternalTransfer,
+			Standard:       models.StandardNative,
+			Token:          NativeTokenAddress,
+			BatchIndex:     callsPerTx[call.TxIndex],
+			CallType:       call.Type,
+			TraceAddress:   call.TraceAddress,
+			Kind:           transferKind(call.From, call.To),
+			From:           strings.ToLower(call.From.Hex()),
+			To:             strings.ToLower(call.To.Hex()),
+			Value:          decimalValue,
+			ValueString:    valueStr,
+			ValueDecimal:   parseValueDecimal(call.Value),
+			BlockNumber:    block.NumberU64(),
+			BlockHash:      blockHash,
+			TxHash:         txHash,
+			TxIndex:        call.TxIndex,
+			Timestamp:      timestamp,
+			CreatedAt:      time.Now(),
+		})
+		callsPerTx[call.TxIndex]++
+	}
+
+	return transfers, nil
+}
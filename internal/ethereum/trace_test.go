@@ -0,0 +1,201 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// debugTraces is a debug_traceBlockByNumber result with a reverted transaction, a reverted subtree and non-value calls
+const debugTraces = `[
+	{"txHash": "0xaa", "result": {"type": "CALL", "from": "0x01", "to": "0x02", "value": "0x5", "calls": [
+		{"type": "CALL", "from": "0x02", "to": "0x03", "value": "0x1", "calls": [
+			{"type": "CALL", "from": "0x03", "to": "0x04", "value": "0x2"}
+		]},
+		{"type": "DELEGATECALL", "from": "0x02", "to": "0x05", "value": "0x9"},
+		{"type": "STATICCALL", "from": "0x02", "to": "0x06"},
+		{"type": "CALL", "from": "0x02", "to": "0x07", "value": "0x3", "error": "execution reverted", "calls": [
+			{"type": "CALL", "from": "0x07", "to": "0x08", "value": "0x4"}
+		]},
+		{"type": "CALL", "from": "0x02", "to": "0x09", "value": "0x0"},
+		{"type": "create2", "from": "0x02", "to": "0x0a", "value": "0x6"}
+	]}},
+	{"txHash": "0xbb", "result": {"type": "CALL", "from": "0x01", "to": "0x02", "error": "out of gas", "calls": [
+		{"type": "CALL", "from": "0x02", "to": "0x03", "value": "0x7"}
+	]}}
+]`
+
+// parityTraces is the trace_block equivalent, plus a block reward and a selfdestruct
+const parityTraces = `[
+	{"type": "call", "action": {"callType": "call", "from": "0x01", "to": "0x02", "value": "0x5"}, "traceAddress": [], "transactionHash": "0xaa", "transactionPosition": 0},
+	{"type": "call", "action": {"callType": "call", "from": "0x02", "to": "0x03", "value": "0x1"}, "traceAddress": [0], "transactionHash": "0xaa", "transactionPosition": 0},
+	{"type": "call", "action": {"callType": "call", "from": "0x03", "to": "0x04", "value": "0x2"}, "traceAddress": [0, 0], "transactionHash": "0xaa", "transactionPosition": 0},
+	{"type": "call", "action": {"callType": "delegatecall", "from": "0x02", "to": "0x05", "value": "0x9"}, "traceAddress": [1], "transactionHash": "0xaa", "transactionPosition": 0},
+	{"type": "call", "action": {"callType": "call", "from": "0x02", "to": "0x07", "value": "0x3"}, "error": "Reverted", "traceAddress": [2], "transactionHash": "0xaa", "transactionPosition": 0},
+	{"type": "call", "action": {"callType": "call", "from": "0x07", "to": "0x08", "value": "0x4"}, "traceAddress": [2, 0], "transactionHash": "0xaa", "transactionPosition": 0},
+	{"type": "create", "action": {"from": "0x02", "value": "0x6"}, "result": {"address": "0x0a"}, "traceAddress": [3], "transactionHash": "0xaa", "transactionPosition": 0},
+	{"type": "suicide", "action": {"address": "0x0a", "refundAddress": "0x0b", "balance": "0x8"}, "traceAddress": [4], "transactionHash": "0xaa", "transactionPosition": 0},
+	{"type": "reward", "action": {"author": "0x0c", "value": "0x10", "rewardType": "block"}, "traceAddress": []}
+]`
+
+// wantCall identifies an expected call by the last byte of its addresses
+type wantCall struct {
+	path     string
+	callType string
+	from     byte
+	to       byte
+	value    int64
+}
+
+func checkCalls(t *testing.T, calls []InternalCall, want []wantCall) {
+	t.Helper()
+	if len(calls) != len(want) {
+		t.Fatalf("got %d calls %+v, want %d", len(calls), calls, len(want))
+	}
+	for i, call := range calls {
+		w := want[i]
+		if call.TraceAddress != w.path || call.Type != w.callType || call.From[19] != w.from || call.To[19] != w.to ||
+			call.Value.Int64() != w.value || call.TxIndex != 0 {
+			t.Errorf("call %d = %s %s %x->%x %s (tx %d), want %s %s %x->%x %d (tx 0)", i,
+				call.TraceAddress, call.Type, call.From[19], call.To[19], call.Value, call.TxIndex,
+				w.path, w.callType, w.from, w.to, w.value)
+		}
+	}
+}
+
+func TestDebugTraceBlock(t *testing.T) {
+	client := newTestRPC(t, func(method string, _ []json.RawMessage) (any, error) {
+		return json.RawMessage(debugTraces), nil
+	})
+
+	calls, err := debugTraceBlock(context.Background(), client.GetClient().Client(), 100)
+	if err != nil {
+		t.Fatalf("debugTraceBlock() error = %v", err)
+	}
+	checkCalls(t, calls, []wantCall{
+		{path: "0", callType: "CALL", from: 0x02, to: 0x03, value: 1},
+		{path: "0.0", callType: "CALL", from: 0x03, to: 0x04, value: 2},
+		{path: "5", callType: "CREATE2", from: 0x02, to: 0x0a, value: 6},
+	})
+	for _, call := range calls {
+		if call.TxHash != "0xaa" {
+			t.Errorf("call %s tx hash = %s, want 0xaa", call.TraceAddress, call.TxHash)
+		}
+	}
+}
+
+func TestParityTraceBlock(t *testing.T) {
+	client := newTestRPC(t, func(method string, _ []json.RawMessage) (any, error) {
+		return json.RawMessage(parityTraces), nil
+	})
+
+	calls, err := parityTraceBlock(context.Background(), client.GetClient().Client(), 100)
+	if err != nil {
+		t.Fatalf("parityTraceBlock() error = %v", err)
+	}
+	checkCalls(t, calls, []wantCall{
+		{path: "0", callType: "CALL", from: 0x02, to: 0x03, value: 1},
+		{path: "0.0", callType: "CALL", from: 0x03, to: 0x04, value: 2},
+		{path: "3", callType: "CREATE", from: 0x02, to: 0x0a, value: 6},
+		{path: "4", callType: "SELFDESTRUCT", from: 0x0a, to: 0x0b, value: 8},
+	})
+}
+
+func TestTraceBlockDetection(t *testing.T) {
+	notFound := func(method string) error {
+		return fmt.Errorf("the method %s does not exist/is not available", method)
+	}
+
+	tests := []struct {
+		name      string
+		supported map[string]bool
+		start     traceAPI
+		wantErr   error
+		wantAPI   traceAPI
+		wantCalls []string // methods called, in order
+	}{
+		{
+			name:      "debug api",
+			supported: map[string]bool{"debug_traceBlockByNumber": true, "trace_block": true},
+			wantAPI:   traceAPIDebug,
+			wantCalls: []string{"debug_traceBlockByNumber"},
+		},
+		{
+			name:      "falls back to trace_block",
+			supported: map[string]bool{"trace_block": true},
+			wantAPI:   traceAPIParity,
+			wantCalls: []string{"debug_traceBlockByNumber", "trace_block"},
+		},
+		{
+			name:      "remembered parity api skips debug",
+			supported: map[string]bool{"trace_block": true},
+			start:     traceAPIParity,
+			wantAPI:   traceAPIParity,
+			wantCalls: []string{"trace_block"},
+		},
+		{
+			name:      "neither api",
+			supported: map[string]bool{},
+			wantErr:   errMethodUnsupported,
+			wantAPI:   traceAPINone,
+			wantCalls: []string{"debug_traceBlockByNumber", "trace_block"},
+		},
+		{
+			name:      "remembered api disappears",
+			supported: map[string]bool{"trace_block": true},
+			start:     traceAPIDebug,
+			wantErr:   errMethodUnsupported,
+			wantAPI:   traceAPIUnknown,
+			wantCalls: []string{"debug_traceBlockByNumber"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called []string
+			client := newTestRPC(t, func(method string, _ []json.RawMessage) (any, error) {
+				called = append(called, method)
+				if !tt.supported[method] {
+					return nil, notFound(method)
+				}
+				return json.RawMessage(`[]`), nil
+			})
+
+			support := &traceSupport{api: tt.start}
+			_, err := traceBlock(context.Background(), client.GetClient().Client(), support, 100)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("traceBlock() error = %v, want %v", err, tt.wantErr)
+			}
+			if support.get() != tt.wantAPI {
+				t.Errorf("detected api = %d, want %d", support.get(), tt.wantAPI)
+			}
+			if fmt.Sprint(called) != fmt.Sprint(tt.wantCalls) {
+				t.Errorf("called %v, want %v", called, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIsMethodNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "geth", err: errors.New("the method trace_block does not exist/is not available"), want: true},
+		{name: "not enabled", err: errors.New("debug namespace is not enabled"), want: true},
+		{name: "pruned state", err: errors.New("required historical state unavailable (not available)"), want: false},
+		{name: "timeout", err: context.DeadlineExceeded, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isMethodNotFound(tt.err); got != tt.want {
+				t.Errorf("isMethodNotFound(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
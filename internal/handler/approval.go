@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pagrin/internal/models"
+	"pagrin/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApprovalHandler exposes ERC-20 allowance queries
+type ApprovalHandler struct {
+	chains *service.Chains
+}
+
+func NewApprovalHandler(chains *service.Chains) *ApprovalHandler {
+	return &ApprovalHandler{chains: chains}
+}
+
+// ListOpenApprovals returns the open allowances granted by an address
+func (h *ApprovalHandler) ListOpenApprovals(c *gin.Context) {
+	start := time.Now()
+
+	chain := resolveChain(c, start, h.chains)
+	if chain == nil {
+		return
+	}
+
+	params := h.parseParams(c)
+	params.Owner = c.Param("address")
+	if unlimited := c.Query("unlimited"); unlimited == "true" || unlimited == "1" {
+		params.UnlimitedOnly = true
+	}
+
+	allowances, total, err := chain.Approval.ListOpenApprovals(c.Request.Context(), params)
+	h.respondList(c, start, params, allowances, total, err)
+}
+
+// ListFlaggedApprovals returns unlimited allowances granted to risky spenders
+func (h *ApprovalHandler) ListFlaggedApprovals(c *gin.Context) {
+	start := time.Now()
+
+	chain := resolveChain(c, start, h.chains)
+	if chain == nil {
+		return
+	}
+
+	params := h.parseParams(c)
+	allowances, total, err := chain.Approval.ListFlaggedApprovals(c.Request.Context(), params)
+	h.respondList(c, start, params, allowances, total, err)
+}
+
+func (h *ApprovalHandler) parseParams(c *gin.Context) models.AllowanceQueryParams {
+	params := models.AllowanceQueryParams{
+		Token: c.Query("token"),
+		Limit: 100,
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil {
+			params.Limit = limit
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil {
+			params.Offset = offset
+		}
+	}
+	return params
+}
+
+func (h *ApprovalHandler) respondList(c *gin.Context, start time.Time, params models.AllowanceQueryParams, allowances []*models.Allowance, total int64, err error) {
+	if errors.Is(err, service.ErrInvalidInput) {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respond(c, start, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respond(c, start, http.StatusOK, gin.H{
+		"data":   allowances,
+		"total":  total,
+		"limit":  params.Limit,
+		"offset": params.Offset,
+	})
+}
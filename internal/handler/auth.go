@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"pagrin/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminActorKey holds the name of the authenticated admin API key in the gin context
+const adminActorKey = "admin_actor"
+
+// AdminAuth rejects requests without a configured admin API key
+// The key is read from "Authorization: Bearer <key>" or the X-API-Key header
+// With no keys configured every admin request is refused
+func AdminAuth(keys map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		if len(keys) == 0 {
+			respond(c, start, http.StatusForbidden, gin.H{"error": "admin API is disabled: ADMIN_API_KEYS is not set"})
+			c.Abort()
+			return
+		}
+
+		key := c.GetHeader("X-API-Key")
+		if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			key = bearer
+		}
+
+		name, ok := matchAPIKey(keys, key)
+		if !ok {
+			respond(c, start, http.StatusUnauthorized, gin.H{"error": "missing or invalid admin API key"})
+			c.Abort()
+			return
+		}
+
+		c.Set(adminActorKey, name)
+		c.Next()
+	}
+}
+
+// matchAPIKey compares key with every configured key in constant time and returns its name
+func matchAPIKey(keys map[string]string, key string) (string, bool) {
+	var name string
+	found := false
+	for candidate, candidateName := range keys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			name, found = candidateName, true
+		}
+	}
+	return name, found && key != ""
+}
+
+// adminActor identifies the admin making the request, for the audit log
+func adminActor(c *gin.Context) models.Actor {
+	return models.Actor{Name: c.GetString(adminActorKey), RemoteAddr: c.ClientIP()}
+}
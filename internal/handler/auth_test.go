@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := map[string]string{"s3cret": "ops", "other": "ci"}
+
+	tests := []struct {
+		name       string
+		keys       map[string]string
+		headers    map[string]string
+		wantStatus int
+		wantActor  string
+	}{
+		{name: "no keys configured", keys: nil, headers: map[string]string{"X-API-Key": "s3cret"}, wantStatus: http.StatusForbidden},
+		{name: "missing key", keys: keys, wantStatus: http.StatusUnauthorized},
+		{name: "unknown key", keys: keys, headers: map[string]string{"X-API-Key": "guess"}, wantStatus: http.StatusUnauthorized},
+		{name: "key prefix", keys: keys, headers: map[string]string{"X-API-Key": "s3c"}, wantStatus: http.StatusUnauthorized},
+		{name: "empty bearer", keys: keys, headers: map[string]string{"Authorization": "Bearer "}, wantStatus: http.StatusUnauthorized},
+		{name: "basic scheme", keys: keys, headers: map[string]string{"Authorization": "Basic s3cret"}, wantStatus: http.StatusUnauthorized},
+		{name: "bearer", keys: keys, headers: map[string]string{"Authorization": "Bearer s3cret"}, wantStatus: http.StatusOK, wantActor: "ops"},
+		{name: "api key header", keys: keys, headers: map[string]string{"X-API-Key": "other"}, wantStatus: http.StatusOK, wantActor: "ci"},
+		{
+			name:       "bearer takes precedence",
+			keys:       keys,
+			headers:    map[string]string{"Authorization": "Bearer guess", "X-API-Key": "s3cret"},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actor string
+			router := gin.New()
+			router.GET("/admin", AdminAuth(tt.keys), func(c *gin.Context) {
+				actor = c.GetString(adminActorKey)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			for header, value := range tt.headers {
+				req.Header.Set(header, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if actor != tt.wantActor {
+				t.Errorf("actor = %q, want %q", actor, tt.wantActor)
+			}
+		})
+	}
+}
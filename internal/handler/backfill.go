@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pagrin/internal/models"
+	"pagrin/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BackfillHandler exposes admin endpoints for historical backfill jobs
+type BackfillHandler struct {
+	chains *service.Chains
+}
+
+func NewBackfillHandler(chains *service.Chains) *BackfillHandler {
+	return &BackfillHandler{chains: chains}
+}
+
+// CreateBackfill starts a new backfill job over the requested block range
+func (h *BackfillHandler) CreateBackfill(c *gin.Context) {
+	start := time.Now()
+
+	var req models.CreateBackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	chain := h.chains.Default()
+	if req.ChainID != 0 {
+		if chain = h.chains.Get(req.ChainID); chain == nil {
+			respond(c, start, http.StatusBadRequest, gin.H{"error": service.ErrUnknownChain.Error()})
+			return
+		}
+	}
+
+	job, err := chain.Backfill.CreateJob(c.Request.Context(), req)
+	if err != nil {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	respond(c, start, http.StatusAccepted, job)
+}
+
+// ListBackfills returns the most recent backfill jobs of a chain
+func (h *BackfillHandler) ListBackfills(c *gin.Context) {
+	start := time.Now()
+
+	chain := resolveChain(c, start, h.chains)
+	if chain == nil {
+		return
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = l
+		}
+	}
+
+	jobs, err := chain.Backfill.ListJobs(c.Request.Context(), limit)
+	if err != nil {
+		respond(c, start, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respond(c, start, http.StatusOK, gin.H{"data": jobs})
+}
+
+// GetBackfill reports a job's progress
+func (h *BackfillHandler) GetBackfill(c *gin.Context) {
+	start := time.Now()
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": "invalid backfill id"})
+		return
+	}
+
+	// Job IDs are unique across chains, so look the job up on each chain in turn
+	var progress *models.BackfillProgress
+	err = service.ErrBackfillNotFound
+	for _, chain := range h.chains.All() {
+		progress, err = chain.Backfill.GetProgress(c.Request.Context(), id)
+		if !errors.Is(err, service.ErrBackfillNotFound) {
+			break
+		}
+	}
+	if errors.Is(err, service.ErrBackfillNotFound) {
+		respond(c, start, http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respond(c, start, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respond(c, start, http.StatusOK, progress)
+}
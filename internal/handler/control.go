@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pagrin/internal/models"
+	"pagrin/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ControlHandler exposes admin endpoints to pause, resume and rewind ingestion and change its batch size
+type ControlHandler struct {
+	chains *service.Chains
+}
+
+func NewControlHandler(chains *service.Chains) *ControlHandler {
+	return &ControlHandler{chains: chains}
+}
+
+// GetIngestion returns a chain's requested ingestion state and checkpoint
+func (h *ControlHandler) GetIngestion(c *gin.Context) {
+	start := time.Now()
+
+	chain := resolveChain(c, start, h.chains)
+	if chain == nil {
+		return
+	}
+
+	control, err := chain.Control.Get(c.Request.Context())
+	if err != nil {
+		respond(c, start, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respond(c, start, http.StatusOK, control)
+}
+
+// PauseIngestion stops a chain's ingestion after the batches in flight
+func (h *ControlHandler) PauseIngestion(c *gin.Context) {
+	start := time.Now()
+
+	var req models.PauseIngestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) { // The body is optional
+		respond(c, start, http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	chain := h.requestChain(c, start, req.ChainID)
+	if chain == nil {
+		return
+	}
+
+	control, err := chain.Control.Pause(c.Request.Context(), adminActor(c), req.Reason)
+	respondControl(c, start, control, err)
+}
+
+// ResumeIngestion restarts a paused chain's ingestion
+func (h *ControlHandler) ResumeIngestion(c *gin.Context) {
+	start := time.Now()
+
+	var req models.ResumeIngestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) { // The body is optional
+		respond(c, start, http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	chain := h.requestChain(c, start, req.ChainID)
+	if chain == nil {
+		return
+	}
+
+	control, err := chain.Control.Resume(c.Request.Context(), adminActor(c))
+	respondControl(c, start, control, err)
+}
+
+// RewindIngestion moves a chain's checkpoint back, optionally purging the data above it
+func (h *ControlHandler) RewindIngestion(c *gin.Context) {
+	start := time.Now()
+
+	var req models.RewindIngestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	chain := h.requestChain(c, start, req.ChainID)
+	if chain == nil {
+		return
+	}
+
+	control, err := chain.Control.Rewind(c.Request.Context(), adminActor(c), *req.ToBlock, req.Purge)
+	respondControl(c, start, control, err)
+}
+
+// SetBatchSize changes a chain's ingestion batch size at runtime
+func (h *ControlHandler) SetBatchSize(c *gin.Context) {
+	start := time.Now()
+
+	var req models.SetBatchSizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	chain := h.requestChain(c, start, req.ChainID)
+	if chain == nil {
+		return
+	}
+
+	control, err := chain.Control.SetBatchSize(c.Request.Context(), adminActor(c), req.BatchSize)
+	respondControl(c, start, control, err)
+}
+
+// ListAudit returns a chain's admin audit entries, newest first
+func (h *ControlHandler) ListAudit(c *gin.Context) {
+	start := time.Now()
+
+	chain := resolveChain(c, start, h.chains)
+	if chain == nil {
+		return
+	}
+
+	params := models.AuditQueryParams{
+		Action: c.Query("action"),
+		Limit:  100,
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil {
+			params.Limit = limit
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil {
+			params.Offset = offset
+		}
+	}
+
+	entries, total, err := chain.Control.ListAudit(c.Request.Context(), params)
+	if errors.Is(err, service.ErrInvalidInput) {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respond(c, start, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respond(c, start, http.StatusOK, gin.H{
+		"data":   entries,
+		"total":  total,
+		"limit":  params.Limit,
+		"offset": params.Offset,
+	})
+}
+
+// requestChain selects the chain named in a request body; zero means the first configured chain
+func (h *ControlHandler) requestChain(c *gin.Context, start time.Time, chainID uint64) *service.Chain {
+	if chainID == 0 {
+		return h.chains.Default()
+	}
+	chain := h.chains.Get(chainID)
+	if chain == nil {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": service.ErrUnknownChain.Error()})
+	}
+	return chain
+}
+
+func respondControl(c *gin.Context, start time.Time, control *models.IngestionControl, err error) {
+	if errors.Is(err, service.ErrInvalidInput) {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respond(c, start, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	respond(c, start, http.StatusAccepted, control)
+}
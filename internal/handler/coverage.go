@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"pagrin/internal/metrics"
+	"pagrin/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CoverageHandler reports which block ranges are completely indexed
+type CoverageHandler struct {
+	chains *service.Chains
+}
+
+func NewCoverageHandler(chains *service.Chains) *CoverageHandler {
+	return &CoverageHandler{chains: chains}
+}
+
+func (h *CoverageHandler) GetCoverage(c *gin.Context) {
+	start := time.Now()
+
+	chain := resolveChain(c, start, h.chains)
+	if chain == nil {
+		return
+	}
+
+	coverage, err := chain.Coverage.GetCoverage(c.Request.Context())
+	if err != nil {
+		metrics.HTTPRequestsTotal.WithLabelValues(c.Request.Method, c.FullPath(), "500").Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, c.FullPath()).Observe(time.Since(start).Seconds())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.HTTPRequestsTotal.WithLabelValues(c.Request.Method, c.FullPath(), "200").Inc()
+	metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, c.FullPath()).Observe(time.Since(start).Seconds())
+
+	c.JSON(http.StatusOK, coverage)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pagrin/internal/models"
+	"pagrin/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeadLetterHandler exposes admin endpoints for logs the parsers rejected or degraded
+type DeadLetterHandler struct {
+	chains *service.Chains
+}
+
+func NewDeadLetterHandler(chains *service.Chains) *DeadLetterHandler {
+	return &DeadLetterHandler{chains: chains}
+}
+
+// ListDeadLetters returns a chain's dead letters, optionally filtered by status and kind
+func (h *DeadLetterHandler) ListDeadLetters(c *gin.Context) {
+	start := time.Now()
+
+	chain := resolveChain(c, start, h.chains)
+	if chain == nil {
+		return
+	}
+
+	params := models.DeadLetterQueryParams{
+		Status: c.Query("status"),
+		Kind:   c.Query("kind"),
+		Limit:  100,
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil {
+			params.Limit = limit
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil {
+			params.Offset = offset
+		}
+	}
+
+	letters, total, err := chain.DeadLetters.List(c.Request.Context(), params)
+	if errors.Is(err, service.ErrInvalidInput) {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respond(c, start, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respond(c, start, http.StatusOK, gin.H{
+		"data":   letters,
+		"total":  total,
+		"limit":  params.Limit,
+		"offset": params.Offset,
+	})
+}
+
+// ReprocessDeadLetters decodes dead letters again with the current parsers
+func (h *DeadLetterHandler) ReprocessDeadLetters(c *gin.Context) {
+	start := time.Now()
+
+	var req models.ReprocessDeadLettersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	chain := h.chains.Default()
+	if req.ChainID != 0 {
+		if chain = h.chains.Get(req.ChainID); chain == nil {
+			respond(c, start, http.StatusBadRequest, gin.H{"error": service.ErrUnknownChain.Error()})
+			return
+		}
+	}
+
+	response, err := chain.DeadLetters.Reprocess(c.Request.Context(), req.IDs, req.Limit)
+	if errors.Is(err, service.ErrInvalidInput) {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respond(c, start, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respond(c, start, http.StatusOK, response)
+}
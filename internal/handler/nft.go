@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pagrin/internal/models"
+	"pagrin/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NFTHandler exposes ERC-721 ownership lookups
+type NFTHandler struct {
+	chains *service.Chains
+}
+
+func NewNFTHandler(chains *service.Chains) *NFTHandler {
+	return &NFTHandler{chains: chains}
+}
+
+// GetOwner returns the current owner of a token in a collection
+func (h *NFTHandler) GetOwner(c *gin.Context) {
+	start := time.Now()
+
+	chain := resolveChain(c, start, h.chains)
+	if chain == nil {
+		return
+	}
+
+	owner, err := chain.NFT.GetOwner(c.Request.Context(), c.Param("token"), c.Param("token_id"))
+	if errors.Is(err, service.ErrNFTNotFound) {
+		respond(c, start, http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidInput) {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respond(c, start, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respond(c, start, http.StatusOK, owner)
+}
+
+// ListByOwner returns the NFTs an address currently holds
+func (h *NFTHandler) ListByOwner(c *gin.Context) {
+	start := time.Now()
+
+	chain := resolveChain(c, start, h.chains)
+	if chain == nil {
+		return
+	}
+
+	params := models.NFTQueryParams{
+		Owner: c.Param("address"),
+		Token: c.Query("token"),
+		Limit: 100,
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil {
+			params.Limit = limit
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil {
+			params.Offset = offset
+		}
+	}
+
+	nfts, total, err := chain.NFT.ListByOwner(c.Request.Context(), params)
+	if errors.Is(err, service.ErrInvalidInput) {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respond(c, start, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respond(c, start, http.StatusOK, gin.H{
+		"data":   nfts,
+		"total":  total,
+		"limit":  params.Limit,
+		"offset": params.Offset,
+	})
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pagrin/internal/metrics"
+	"pagrin/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respond writes the JSON response and records HTTP metrics
+func respond(c *gin.Context, start time.Time, status int, body interface{}) {
+	metrics.HTTPRequestsTotal.WithLabelValues(c.Request.Method, c.FullPath(), strconv.Itoa(status)).Inc()
+	metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, c.FullPath()).Observe(time.Since(start).Seconds())
+	c.JSON(status, body)
+}
+
+// resolveChain selects the chain named by the chain_id query parameter, defaulting to the first configured chain
+// Responds with an error and returns nil when the parameter is malformed or the chain is not indexed
+func resolveChain(c *gin.Context, start time.Time, chains *service.Chains) *service.Chain {
+	chain, err := chains.Resolve(c.Query("chain_id"))
+	if errors.Is(err, service.ErrUnknownChain) {
+		respond(c, start, http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil
+	}
+	if err != nil {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil
+	}
+	return chain
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"pagrin/internal/models"
+	"pagrin/internal/service"
+	"pagrin/internal/stream"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusHandler reports ingestion progress, provider health and stream clients
+type StatusHandler struct {
+	chains  *service.Chains
+	stream  *stream.Stream // nil when streaming is disabled
+	elector *service.LeaderElector
+}
+
+func NewStatusHandler(chains *service.Chains, stream *stream.Stream, elector *service.LeaderElector) *StatusHandler {
+	return &StatusHandler{chains: chains, stream: stream, elector: elector}
+}
+
+// GetStatus returns the status of every chain, or only the one named by chain_id
+func (h *StatusHandler) GetStatus(c *gin.Context) {
+	start := time.Now()
+
+	chains := h.chains.All()
+	if c.Query("chain_id") != "" {
+		chain := resolveChain(c, start, h.chains)
+		if chain == nil {
+			return
+		}
+		chains = []*service.Chain{chain}
+	}
+
+	response := models.StatusResponse{
+		Chains: make([]*models.ChainStatus, 0, len(chains)),
+		Leader: h.elector.Status(),
+	}
+	for _, chain := range chains {
+		status, err := chain.Ingestion.Status(c.Request.Context())
+		if err != nil {
+			respond(c, start, http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		status.Name = chain.Name
+		response.Chains = append(response.Chains, status)
+	}
+	if h.stream != nil {
+		response.Stream = models.StreamStatus{Enabled: true, Clients: h.stream.ClientCount()}
+	}
+
+	respond(c, start, http.StatusOK, response)
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	"pagrin/internal/models"
 	"pagrin/internal/stream"
 
 	"github.com/gin-gonic/gin"
@@ -30,7 +33,20 @@ func NewStreamHandler(s *stream.Stream) *StreamHandler {
 
 // HandleWebSocket handles WebSocket connections for real-time transfer events
 // Clients receive JSON-encoded transfer events as they are processed
+// Optional ?status= limits delivery to transfers with that finality status
+// Optional ?chain_id= limits delivery to transfers of that chain
 func (h *StreamHandler) HandleWebSocket(c *gin.Context) {
+	status, err := statusFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	chainID, err := chainFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to upgrade connection"})
@@ -56,14 +72,21 @@ func (h *StreamHandler) HandleWebSocket(c *gin.Context) {
 	// Send events to client
 	for {
 		select {
-		case data, ok := <-clientChan:
+		case event, ok := <-clientChan:
 			if !ok {
 				// Channel closed
 				return
 			}
+			if status != "" && event.Status != status {
+				continue
+			}
+			if chainID != 0 && event.ChainID != chainID {
+				continue
+			}
 
+			// Removed events carry "removed": true in the payload
 			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			if err := conn.WriteMessage(websocket.TextMessage, event.Data); err != nil {
 				return // Client disconnected
 			}
 
@@ -79,7 +102,20 @@ func (h *StreamHandler) HandleWebSocket(c *gin.Context) {
 
 // HandleSSE handles Server-Sent Events for real-time transfer events
 // SSE is simpler than WebSocket but only supports server-to-client communication
+// Optional ?status= limits delivery to transfers with that finality status
+// Optional ?chain_id= limits delivery to transfers of that chain
 func (h *StreamHandler) HandleSSE(c *gin.Context) {
+	status, err := statusFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	chainID, err := chainFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
@@ -91,14 +127,20 @@ func (h *StreamHandler) HandleSSE(c *gin.Context) {
 	// Send events to client
 	for {
 		select {
-		case data, ok := <-clientChan:
+		case event, ok := <-clientChan:
 			if !ok {
 				// Channel closed
 				return
 			}
+			if status != "" && event.Status != status {
+				continue
+			}
+			if chainID != 0 && event.ChainID != chainID {
+				continue
+			}
 
-			// SSE format: "data: <json>\n\n"
-			c.SSEvent("transfer", string(data))
+			// SSE format: "event: <type>\ndata: <json>\n\n"
+			c.SSEvent(event.Type, string(event.Data))
 			c.Writer.Flush()
 
 		case <-c.Request.Context().Done():
@@ -107,3 +149,25 @@ func (h *StreamHandler) HandleSSE(c *gin.Context) {
 		}
 	}
 }
+
+// chainFilter parses the optional chain_id query parameter; 0 means every chain
+func chainFilter(c *gin.Context) (uint64, error) {
+	raw := c.Query("chain_id")
+	if raw == "" {
+		return 0, nil
+	}
+	chainID, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid chain_id: %s", raw)
+	}
+	return chainID, nil
+}
+
+// statusFilter returns the ?status= finality filter, empty for all statuses
+func statusFilter(c *gin.Context) (string, error) {
+	status := c.Query("status")
+	if status != "" && !models.IsTransferStatus(status) {
+		return "", fmt.Errorf("invalid status: %s (expected pending, safe or finalized)", status)
+	}
+	return status, nil
+}
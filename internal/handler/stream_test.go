@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStatusFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{query: "", want: ""},
+		{query: "?status=pending", want: "pending"},
+		{query: "?status=safe", want: "safe"},
+		{query: "?status=finalized", want: "finalized"},
+		{query: "?status=confirmed", wantErr: true},
+		{query: "?status=Finalized", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/ws"+tt.query, nil)
+
+			got, err := statusFilter(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("statusFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("statusFilter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query   string
+		want    uint64
+		wantErr bool
+	}{
+		{query: "", want: 0},
+		{query: "?chain_id=8453", want: 8453},
+		{query: "?chain_id=base", wantErr: true},
+		{query: "?chain_id=-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/ws"+tt.query, nil)
+
+			got, err := chainFilter(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("chainFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("chainFilter() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pagrin/internal/models"
+	"pagrin/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHandler exposes token metadata and supply lookups
+type TokenHandler struct {
+	chains *service.Chains
+}
+
+func NewTokenHandler(chains *service.Chains) *TokenHandler {
+	return &TokenHandler{chains: chains}
+}
+
+// GetMetadata returns the name, symbol and decimals of a token, resolving them on first request
+func (h *TokenHandler) GetMetadata(c *gin.Context) {
+	start := time.Now()
+
+	chain := resolveChain(c, start, h.chains)
+	if chain == nil {
+		return
+	}
+
+	metadata, err := chain.Metadata.Get(c.Request.Context(), c.Param("address"))
+	if errors.Is(err, service.ErrInvalidInput) {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respond(c, start, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respond(c, start, http.StatusOK, metadata)
+}
+
+// GetSupply returns a token's circulating supply and its history of per-block changes, newest first
+func (h *TokenHandler) GetSupply(c *gin.Context) {
+	start := time.Now()
+
+	chain := resolveChain(c, start, h.chains)
+	if chain == nil {
+		return
+	}
+
+	params := models.SupplyQueryParams{
+		Token: c.Param("address"),
+		Limit: 100,
+	}
+	if startBlockStr := c.Query("start_block"); startBlockStr != "" {
+		if startBlock, err := strconv.ParseUint(startBlockStr, 10, 64); err == nil {
+			params.StartBlock = &startBlock
+		}
+	}
+	if endBlockStr := c.Query("end_block"); endBlockStr != "" {
+		if endBlock, err := strconv.ParseUint(endBlockStr, 10, 64); err == nil {
+			params.EndBlock = &endBlock
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil {
+			params.Limit = limit
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil {
+			params.Offset = offset
+		}
+	}
+
+	supply, err := chain.Supply.GetSupply(c.Request.Context(), params)
+	if errors.Is(err, service.ErrSupplyNotFound) {
+		respond(c, start, http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidInput) {
+		respond(c, start, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respond(c, start, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respond(c, start, http.StatusOK, supply)
+}
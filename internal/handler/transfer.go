@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"pagrin/internal/metrics"
@@ -28,6 +30,11 @@ func (h *TransferHandler) GetTransfers(c *gin.Context) {
 		Offset: 0,
 	}
 
+	if chainIDStr := c.Query("chain_id"); chainIDStr != "" {
+		if chainID, err := strconv.ParseUint(chainIDStr, 10, 64); err == nil {
+			params.ChainID = &chainID
+		}
+	}
 	if token := c.Query("token"); token != "" {
 		params.Token = token
 	}
@@ -37,6 +44,18 @@ func (h *TransferHandler) GetTransfers(c *gin.Context) {
 	if to := c.Query("to"); to != "" {
 		params.To = to
 	}
+	if status := c.Query("status"); status != "" {
+		params.Status = status
+	}
+	if eventSignature := c.Query("event_signature"); eventSignature != "" {
+		params.EventSignature = eventSignature
+	}
+	if standard := c.Query("standard"); standard != "" {
+		params.Standard = strings.ToLower(standard)
+	}
+	if kind := c.Query("kind"); kind != "" {
+		params.Kind = strings.ToLower(kind)
+	}
 	if limitStr := c.Query("limit"); limitStr != "" {
 		if limit, err := strconv.Atoi(limitStr); err == nil {
 			params.Limit = limit
@@ -69,6 +88,12 @@ func (h *TransferHandler) GetTransfers(c *gin.Context) {
 	}
 
 	transfers, total, err := h.service.QueryTransfers(c.Request.Context(), params)
+	if errors.Is(err, service.ErrInvalidInput) {
+		metrics.HTTPRequestsTotal.WithLabelValues(c.Request.Method, c.FullPath(), "400").Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, c.FullPath()).Observe(time.Since(start).Seconds())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		metrics.HTTPRequestsTotal.WithLabelValues(c.Request.Method, c.FullPath(), "500").Inc()
 		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, c.FullPath()).Observe(time.Since(start).Seconds())
@@ -92,6 +117,11 @@ func (h *TransferHandler) GetAggregates(c *gin.Context) {
 
 	params := models.TransferQueryParams{}
 
+	if chainIDStr := c.Query("chain_id"); chainIDStr != "" {
+		if chainID, err := strconv.ParseUint(chainIDStr, 10, 64); err == nil {
+			params.ChainID = &chainID
+		}
+	}
 	if token := c.Query("token"); token != "" {
 		params.Token = token
 	}
@@ -101,6 +131,18 @@ func (h *TransferHandler) GetAggregates(c *gin.Context) {
 	if to := c.Query("to"); to != "" {
 		params.To = to
 	}
+	if status := c.Query("status"); status != "" {
+		params.Status = status
+	}
+	if eventSignature := c.Query("event_signature"); eventSignature != "" {
+		params.EventSignature = eventSignature
+	}
+	if standard := c.Query("standard"); standard != "" {
+		params.Standard = strings.ToLower(standard)
+	}
+	if kind := c.Query("kind"); kind != "" {
+		params.Kind = strings.ToLower(kind)
+	}
 	if startBlockStr := c.Query("start_block"); startBlockStr != "" {
 		if startBlock, err := strconv.ParseUint(startBlockStr, 10, 64); err == nil {
 			params.StartBlock = &startBlock
@@ -123,6 +165,12 @@ func (h *TransferHandler) GetAggregates(c *gin.Context) {
 	}
 
 	aggregates, err := h.service.GetAggregates(c.Request.Context(), params)
+	if errors.Is(err, service.ErrInvalidInput) {
+		metrics.HTTPRequestsTotal.WithLabelValues(c.Request.Method, c.FullPath(), "400").Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, c.FullPath()).Observe(time.Since(start).Seconds())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		metrics.HTTPRequestsTotal.WithLabelValues(c.Request.Method, c.FullPath(), "500").Inc()
 		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, c.FullPath()).Observe(time.Since(start).Seconds())
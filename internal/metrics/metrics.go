@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -11,7 +13,7 @@ var (
 			Name: "eth_transfers_processed_total",
 			Help: "Total number of ERC-20 transfer events processed",
 		},
-		[]string{"status"},
+		[]string{"chain_id", "status"},
 	)
 
 	TransfersProcessingDuration = promauto.NewHistogramVec(
@@ -23,11 +25,12 @@ var (
 		[]string{"operation"},
 	)
 
-	BlocksProcessedTotal = promauto.NewCounter(
+	BlocksProcessedTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "eth_blocks_processed_total",
 			Help: "Total number of blocks processed",
 		},
+		[]string{"chain_id"},
 	)
 
 	IngestionErrorsTotal = promauto.NewCounterVec(
@@ -35,7 +38,174 @@ var (
 			Name: "eth_ingestion_errors_total",
 			Help: "Total number of ingestion errors",
 		},
-		[]string{"type"},
+		[]string{"chain_id", "type"},
+	)
+
+	// Reorg metrics
+	ReorgsDetectedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eth_reorgs_detected_total",
+			Help: "Total number of chain reorganizations detected during ingestion",
+		},
+		[]string{"chain_id"},
+	)
+
+	ReorgDepth = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "eth_reorg_depth_blocks",
+			Help:    "Number of blocks rolled back per detected reorganization",
+			Buckets: []float64{1, 2, 4, 8, 16, 32, 64, 128},
+		},
+		[]string{"chain_id"},
+	)
+
+	IngestionMode = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eth_ingestion_mode",
+			Help: "1 for the current ingestion mode (catching_up or at_tip), 0 for the other",
+		},
+		[]string{"chain_id", "mode"},
+	)
+
+	HeadLagBlocks = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eth_head_lag_blocks",
+			Help: "Blocks between the live checkpoint and the indexing head",
+		},
+		[]string{"chain_id"},
+	)
+
+	HeadLagSeconds = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eth_head_lag_seconds",
+			Help: "Seconds since the block at the live checkpoint was produced",
+		},
+		[]string{"chain_id"},
+	)
+
+	HeadBlock = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eth_head_block",
+			Help: "Indexing head seen by the last batch: the head by INDEXING_MODE less CONFIRMATION_DEPTH",
+		},
+		[]string{"chain_id"},
+	)
+
+	LastProcessedBlock = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eth_last_processed_block",
+			Help: "Block at the live checkpoint",
+		},
+		[]string{"chain_id"},
+	)
+
+	IngestionBatchSize = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eth_ingestion_batch_size",
+			Help: "Blocks per batch the next live batch is planned with",
+		},
+		[]string{"chain_id"},
+	)
+
+	HeadSubscriptionActive = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eth_head_subscription_active",
+			Help: "1 while ingestion is driven by pushed new heads, 0 while it falls back to polling",
+		},
+		[]string{"chain_id"},
+	)
+
+	LeaderElected = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "eth_leader",
+			Help: "1 while this replica holds the leader lease and runs ingestion, 0 otherwise",
+		},
+	)
+
+	IngestionPaused = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eth_ingestion_paused",
+			Help: "1 while ingestion is paused by an admin request",
+		},
+		[]string{"chain_id"},
+	)
+
+	// Ingestion pipeline metrics
+	IngestionStageDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "eth_ingestion_stage_duration_seconds",
+			Help:    "Time a batch spends in each ingestion pipeline stage (fetch, parse, store)",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0, 60.0},
+		},
+		[]string{"chain_id", "stage"},
+	)
+
+	IngestionStageQueueDepth = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eth_ingestion_stage_queue_depth",
+			Help: "Batches waiting for each ingestion pipeline stage (parse, store)",
+		},
+		[]string{"chain_id", "stage"},
+	)
+
+	// Dead letter metrics
+	DeadLettersTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eth_dead_letters_total",
+			Help: "Logs rejected by the parsers (kind=rejected) or stored with degraded values (kind=degraded)",
+		},
+		[]string{"chain_id", "kind", "event"},
+	)
+
+	DeadLettersReprocessedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eth_dead_letters_reprocessed_total",
+			Help: "Dead letter re-processing attempts by result (resolved or failed)",
+		},
+		[]string{"chain_id", "result"},
+	)
+
+	// Block header cache metrics
+	HeaderCacheRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eth_header_cache_requests_total",
+			Help: "Block header cache lookups by tier (memory, redis) and result (hit, miss)",
+		},
+		[]string{"chain_id", "tier", "result"},
+	)
+
+	HeaderCacheEvictionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eth_header_cache_evictions_total",
+			Help: "Block headers evicted from the in-memory LRU to stay within its size",
+		},
+		[]string{"chain_id"},
+	)
+
+	// Coverage metrics
+	CoverageGaps = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eth_coverage_gaps",
+			Help: "Number of gaps between indexed block ranges",
+		},
+		[]string{"chain_id"},
+	)
+
+	CoverageMissingBlocks = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eth_coverage_missing_blocks",
+			Help: "Total number of blocks inside coverage gaps",
+		},
+		[]string{"chain_id"},
+	)
+
+	// Approval metrics
+	RiskyApprovalsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eth_risky_approvals_total",
+			Help: "Total number of unlimited approvals granted to spenders on the risky list",
+		},
+		[]string{"chain_id"},
 	)
 
 	HTTPRequestsTotal = promauto.NewCounterVec(
@@ -61,7 +231,7 @@ var (
 			Name: "rpc_requests_total",
 			Help: "Total number of RPC requests by provider and method",
 		},
-		[]string{"provider", "method"},
+		[]string{"chain_id", "provider", "method"},
 	)
 
 	RPCErrorsTotal = promauto.NewCounterVec(
@@ -69,7 +239,7 @@ var (
 			Name: "rpc_errors_total",
 			Help: "Total number of RPC errors by provider and error code",
 		},
-		[]string{"provider", "error_code"},
+		[]string{"chain_id", "provider", "error_code"},
 	)
 
 	RPCRequestDuration = promauto.NewHistogramVec(
@@ -78,7 +248,46 @@ var (
 			Help:    "RPC request duration in seconds",
 			Buckets: []float64{0.1, 0.5, 1.0, 2.0, 5.0, 10.0, 30.0},
 		},
-		[]string{"provider", "method"},
+		[]string{"chain_id", "provider", "method"},
+	)
+
+	RPCLogRangeSplitsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rpc_log_range_splits_total",
+			Help: "Total number of eth_getLogs ranges split after a provider rejected them for their result size",
+		},
+		[]string{"chain_id", "provider"},
+	)
+
+	ProviderState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rpc_provider_state",
+			Help: "Circuit breaker state per provider: 0 healthy, 1 unhealthy, 2 half open",
+		},
+		[]string{"chain_id", "provider"},
+	)
+
+	ProviderLatency = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rpc_provider_latency_seconds",
+			Help: "Moving average of request durations per provider",
+		},
+		[]string{"chain_id", "provider"},
+	)
+
+	ProviderLogRangeLimit = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rpc_provider_log_range_limit",
+			Help: "Learned eth_getLogs block range limit per provider, with AIMD batch sizing",
+		},
+		[]string{"chain_id", "provider"},
+	)
+
+	StreamClients = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "stream_clients",
+			Help: "Stream clients connected to this replica",
+		},
 	)
 
 	CurrentBlockHeight = promauto.NewGaugeVec(
@@ -86,6 +295,11 @@ var (
 			Name: "current_block_height",
 			Help: "Current block height per provider",
 		},
-		[]string{"provider"},
+		[]string{"chain_id", "provider"},
 	)
 )
+
+// ChainLabel formats a chain ID as the value of the chain_id label
+func ChainLabel(chainID uint64) string {
+	return strconv.FormatUint(chainID, 10)
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Approval represents an ERC-20 Approval event
+// Value is kept as a decimal string: unlimited approvals (2^256-1) exceed Decimal128 precision
+type Approval struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ChainID        uint64             `bson:"chain_id" json:"chain_id"`
+	EventSignature string             `bson:"event_signature" json:"event_signature"` // "Approval"
+	Token          string             `bson:"token" json:"token"`
+	Owner          string             `bson:"owner" json:"owner"`
+	Spender        string             `bson:"spender" json:"spender"`
+	Value          string             `bson:"value" json:"value"`
+	Unlimited      bool               `bson:"unlimited" json:"unlimited"`
+	BlockNumber    uint64             `bson:"block_number" json:"block_number"`
+	BlockHash      string             `bson:"block_hash,omitempty" json:"block_hash,omitempty"`
+	TxHash         string             `bson:"tx_hash" json:"tx_hash"`
+	TxIndex        uint               `bson:"tx_index" json:"tx_index"`
+	LogIndex       uint               `bson:"log_index" json:"log_index"`
+	Status         string             `bson:"status" json:"status"` // pending, safe or finalized
+	Timestamp      time.Time          `bson:"timestamp" json:"timestamp"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// Allowance is the current allowance of (token, owner, spender), derived from the latest Approval
+// Tokens that spend allowances without emitting Approval may report more than is left on-chain
+type Allowance struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	ChainID     uint64             `bson:"chain_id" json:"chain_id"`
+	Token       string             `bson:"token" json:"token"`
+	Owner       string             `bson:"owner" json:"owner"`
+	Spender     string             `bson:"spender" json:"spender"`
+	Value       string             `bson:"value" json:"value"`
+	Unlimited   bool               `bson:"unlimited" json:"unlimited"`
+	Open        bool               `bson:"open" json:"-"` // Value > 0
+	BlockNumber uint64             `bson:"block_number" json:"block_number"`
+	LogIndex    uint               `bson:"log_index" json:"log_index"`
+	TxHash      string             `bson:"tx_hash" json:"tx_hash"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	Risky       bool               `bson:"-" json:"risky"`              // Spender is on the configured risky list
+	Symbol      string             `bson:"-" json:"symbol,omitempty"`   // Token symbol from metadata
+	Decimals    *uint8             `bson:"-" json:"decimals,omitempty"` // Token decimals from metadata
+}
+
+// AllowanceQueryParams represents query parameters for listing open allowances
+type AllowanceQueryParams struct {
+	Owner         string // Optional when Spenders is set
+	Token         string
+	Spenders      []string // Restrict to these spenders
+	UnlimitedOnly bool
+	Limit         int
+	Offset        int
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Backfill job and chunk statuses
+const (
+	BackfillStatusPending   = "pending"
+	BackfillStatusRunning   = "running"
+	BackfillStatusCompleted = "completed"
+	BackfillStatusFailed    = "failed"
+)
+
+// BackfillJob is a historical ingestion request over [FromBlock, ToBlock]
+// The range is split into BackfillChunk documents that workers claim independently
+type BackfillJob struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ChainID         uint64             `bson:"chain_id" json:"chain_id"`
+	FromBlock       uint64             `bson:"from_block" json:"from_block"`
+	ToBlock         uint64             `bson:"to_block" json:"to_block"`
+	ChunkSize       uint64             `bson:"chunk_size" json:"chunk_size"`
+	Workers         int                `bson:"workers" json:"workers"`
+	Tokens          []string           `bson:"tokens,omitempty" json:"tokens,omitempty"` // Restrict to these contracts (empty = all)
+	Status          string             `bson:"status" json:"status"`
+	TotalChunks     int64              `bson:"total_chunks" json:"total_chunks"`
+	CompletedChunks int64              `bson:"completed_chunks" json:"completed_chunks"`
+	FailedChunks    int64              `bson:"failed_chunks" json:"failed_chunks"`
+	Transfers       int64              `bson:"transfers" json:"transfers"`
+	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time          `bson:"updated_at" json:"updated_at"`
+	CompletedAt     *time.Time         `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+}
+
+// BackfillChunk is a contiguous block range within a backfill job
+// Chunk state is persisted so unfinished work resumes after a restart
+type BackfillChunk struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ChainID   uint64             `bson:"chain_id" json:"chain_id"`
+	JobID     primitive.ObjectID `bson:"job_id" json:"job_id"`
+	FromBlock uint64             `bson:"from_block" json:"from_block"`
+	ToBlock   uint64             `bson:"to_block" json:"to_block"`
+	Status    string             `bson:"status" json:"status"`
+	Attempts  int                `bson:"attempts" json:"attempts"`
+	LastError string             `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// CreateBackfillRequest is the request body for POST /api/v1/admin/backfills
+// ChunkSize and Workers fall back to configured defaults when zero
+// Tokens optionally restricts the backfill to specific token contracts
+// ChainID selects the chain to backfill; zero means the first configured chain
+type CreateBackfillRequest struct {
+	ChainID   uint64   `json:"chain_id"`
+	FromBlock uint64   `json:"from_block"`
+	ToBlock   uint64   `json:"to_block"`
+	ChunkSize uint64   `json:"chunk_size"`
+	Workers   int      `json:"workers"`
+	Tokens    []string `json:"tokens"`
+}
+
+// BackfillProgress reports a job together with its chunk counts by status
+type BackfillProgress struct {
+	Job           *BackfillJob     `json:"job"`
+	Chunks        map[string]int64 `json:"chunks"`
+	PercentDone   float64          `json:"percent_done"`
+	BlocksTotal   uint64           `json:"blocks_total"`
+	BlocksIndexed uint64           `json:"blocks_indexed"`
+}
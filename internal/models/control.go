@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IngestionControl is the admin-requested state of a chain's ingestion, stored in ingestion_control
+// Any replica records requests; the replica running ingestion applies them before its next batch
+type IngestionControl struct {
+	ChainID            uint64           `bson:"chain_id" json:"chain_id"`
+	Paused             bool             `bson:"paused" json:"paused"`
+	PauseReason        string           `bson:"pause_reason,omitempty" json:"pause_reason,omitempty"`
+	BatchSize          uint64           `bson:"batch_size,omitempty" json:"batch_size,omitempty"` // Zero keeps the configured size
+	BatchSizeSetAt     *time.Time       `bson:"batch_size_set_at,omitempty" json:"batch_size_set_at,omitempty"`
+	Rewind             *IngestionRewind `bson:"rewind,omitempty" json:"rewind,omitempty"` // Pending until ingestion applies it
+	UpdatedAt          time.Time        `bson:"updated_at" json:"updated_at"`
+	UpdatedBy          string           `bson:"updated_by" json:"updated_by"`
+	LastProcessedBlock uint64           `bson:"-" json:"last_processed_block"` // Filled in when served
+}
+
+// IngestionRewind moves the checkpoint back to ToBlock; with Purge, data above it is deleted first
+type IngestionRewind struct {
+	ToBlock     uint64    `bson:"to_block" json:"to_block"`
+	Purge       bool      `bson:"purge" json:"purge"`
+	RequestedAt time.Time `bson:"requested_at" json:"requested_at"`
+	RequestedBy string    `bson:"requested_by" json:"requested_by"`
+}
+
+// PauseIngestionRequest is the request body for POST /api/v1/admin/ingestion/pause
+// ChainID selects the chain; zero means the first configured chain
+type PauseIngestionRequest struct {
+	ChainID uint64 `json:"chain_id"`
+	Reason  string `json:"reason"`
+}
+
+// ResumeIngestionRequest is the request body for POST /api/v1/admin/ingestion/resume
+type ResumeIngestionRequest struct {
+	ChainID uint64 `json:"chain_id"`
+}
+
+// RewindIngestionRequest is the request body for POST /api/v1/admin/ingestion/rewind
+// Without Purge, data above ToBlock is kept and overwritten idempotently as it is re-ingested
+type RewindIngestionRequest struct {
+	ChainID uint64  `json:"chain_id"`
+	ToBlock *uint64 `json:"to_block" binding:"required"`
+	Purge   bool    `json:"purge"`
+}
+
+// SetBatchSizeRequest is the request body for POST /api/v1/admin/ingestion/batch-size
+type SetBatchSizeRequest struct {
+	ChainID   uint64 `json:"chain_id"`
+	BatchSize uint64 `json:"batch_size" binding:"required"`
+}
+
+// Audited admin actions
+const (
+	AuditActionPause     = "pause"
+	AuditActionResume    = "resume"
+	AuditActionRewind    = "rewind"
+	AuditActionBatchSize = "batch_size"
+)
+
+// Audit results
+const (
+	AuditResultApplied  = "applied"
+	AuditResultRejected = "rejected"
+)
+
+// AuditEntry records one admin action on a chain's ingestion, stored in admin_audit
+type AuditEntry struct {
+	ID         primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	ChainID    uint64                 `bson:"chain_id" json:"chain_id"`
+	Action     string                 `bson:"action" json:"action"`
+	Actor      string                 `bson:"actor" json:"actor"` // Name of the admin API key used
+	RemoteAddr string                 `bson:"remote_addr" json:"remote_addr"`
+	Params     map[string]interface{} `bson:"params,omitempty" json:"params,omitempty"`
+	Result     string                 `bson:"result" json:"result"` // applied or rejected
+	Error      string                 `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt  time.Time              `bson:"created_at" json:"created_at"`
+}
+
+// AuditQueryParams represents query parameters for listing audit entries
+type AuditQueryParams struct {
+	Action string
+	Limit  int
+	Offset int
+}
+
+// Actor identifies who made an admin request
+type Actor struct {
+	Name       string
+	RemoteAddr string
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CoveredRange is a contiguous, fully ingested block range [FromBlock, ToBlock]
+// Adjacent and overlapping ranges are merged on write, so the collection stays small
+type CoveredRange struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	ChainID   uint64             `bson:"chain_id" json:"-"`
+	FromBlock uint64             `bson:"from_block" json:"from_block"`
+	ToBlock   uint64             `bson:"to_block" json:"to_block"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// BlockRange is an inclusive block range used to report gaps
+type BlockRange struct {
+	FromBlock uint64 `json:"from_block"`
+	ToBlock   uint64 `json:"to_block"`
+}
+
+// CoverageResponse reports which block ranges are completely indexed
+type CoverageResponse struct {
+	ChainID            uint64          `json:"chain_id"`
+	Ranges             []*CoveredRange `json:"ranges"`
+	Gaps               []BlockRange    `json:"gaps"`
+	LastProcessedBlock uint64          `json:"last_processed_block"`
+	Contiguous         bool            `json:"contiguous"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Dead letter kinds
+const (
+	DeadLetterRejected = "rejected" // The parser rejected the log, nothing was stored
+	DeadLetterDegraded = "degraded" // Stored, but a value could not be represented exactly
+)
+
+// Dead letter statuses
+const (
+	DeadLetterStatusOpen     = "open"
+	DeadLetterStatusResolved = "resolved" // Re-processed successfully after a parser fix
+)
+
+// DeadLetter is a log the parsers rejected or could only store in degraded form
+// The raw topics and data are kept so the log can be re-processed without refetching it
+type DeadLetter struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ChainID     uint64             `bson:"chain_id" json:"chain_id"`
+	Kind        string             `bson:"kind" json:"kind"`   // rejected or degraded
+	Event       string             `bson:"event" json:"event"` // Event name, e.g. "Transfer"
+	Reason      string             `bson:"reason" json:"reason"`
+	Address     string             `bson:"address" json:"address"` // Emitting contract
+	Topics      []string           `bson:"topics" json:"topics"`
+	Data        string             `bson:"data" json:"data"` // 0x-prefixed hex
+	BlockNumber uint64             `bson:"block_number" json:"block_number"`
+	BlockHash   string             `bson:"block_hash" json:"block_hash"`
+	TxHash      string             `bson:"tx_hash" json:"tx_hash"`
+	TxIndex     uint               `bson:"tx_index" json:"tx_index"`
+	LogIndex    uint               `bson:"log_index" json:"log_index"`
+	Timestamp   time.Time          `bson:"timestamp" json:"timestamp"` // Block time, reused when re-processing
+	Status      string             `bson:"status" json:"status"`
+	Attempts    int                `bson:"attempts" json:"attempts"` // Re-processing attempts
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	ResolvedAt  *time.Time         `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+}
+
+// DeadLetterQueryParams represents query parameters for listing dead letters
+type DeadLetterQueryParams struct {
+	Status string
+	Kind   string
+	Limit  int
+	Offset int
+}
+
+// ReprocessDeadLettersRequest is the request body for POST /api/v1/admin/dead-letters/reprocess
+// IDs selects specific dead letters; without IDs the oldest open ones are re-processed, up to Limit
+// ChainID selects the chain; zero means the first configured chain
+type ReprocessDeadLettersRequest struct {
+	ChainID uint64   `json:"chain_id"`
+	IDs     []string `json:"ids"`
+	Limit   int      `json:"limit"`
+}
+
+// ReprocessDeadLettersResponse reports the outcome of a re-processing run
+type ReprocessDeadLettersResponse struct {
+	Resolved int           `json:"resolved"`
+	Failed   int           `json:"failed"`
+	Open     []*DeadLetter `json:"open"` // Dead letters the parsers still reject or degrade
+}
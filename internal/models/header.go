@@ -0,0 +1,9 @@
+package models
+
+// BlockHeader is the part of a block header kept in the header cache
+type BlockHeader struct {
+	Number     uint64 `json:"number"`
+	Hash       string `json:"hash"`
+	ParentHash string `json:"parent_hash"`
+	Timestamp  uint64 `json:"timestamp"` // Unix seconds
+}
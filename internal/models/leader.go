@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// LeaderStatus reports this replica's part in leader election on /health
+type LeaderStatus struct {
+	Election string     `json:"election"` // none, mongo or redis
+	Instance string     `json:"instance"`
+	Leader   bool       `json:"leader"`          // True while this replica runs ingestion
+	Since    *time.Time `json:"since,omitempty"` // When this replica last became or stopped being leader
+}
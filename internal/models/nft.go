@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NFTTransfer represents an ERC-721 Transfer event
+// TokenID is a decimal string since uint256 IDs exceed Decimal128 precision
+type NFTTransfer struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ChainID     uint64             `bson:"chain_id" json:"chain_id"`
+	Token       string             `bson:"token" json:"token"` // Collection contract address
+	TokenID     string             `bson:"token_id" json:"token_id"`
+	From        string             `bson:"from" json:"from"`
+	To          string             `bson:"to" json:"to"`
+	BlockNumber uint64             `bson:"block_number" json:"block_number"`
+	BlockHash   string             `bson:"block_hash,omitempty" json:"block_hash,omitempty"`
+	TxHash      string             `bson:"tx_hash" json:"tx_hash"`
+	TxIndex     uint               `bson:"tx_index" json:"tx_index"`
+	LogIndex    uint               `bson:"log_index" json:"log_index"`
+	Status      string             `bson:"status" json:"status"` // pending, safe or finalized
+	Timestamp   time.Time          `bson:"timestamp" json:"timestamp"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// NFTOwner is the current owner of an ERC-721 token, derived from its latest transfer
+// BlockNumber and LogIndex identify that transfer so out-of-order writes never regress ownership
+// Burned tokens keep their row with Owner set to the zero address
+type NFTOwner struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	ChainID     uint64             `bson:"chain_id" json:"chain_id"`
+	Token       string             `bson:"token" json:"token"`
+	TokenID     string             `bson:"token_id" json:"token_id"`
+	Owner       string             `bson:"owner" json:"owner"`
+	BlockNumber uint64             `bson:"block_number" json:"block_number"`
+	LogIndex    uint               `bson:"log_index" json:"log_index"`
+	TxHash      string             `bson:"tx_hash" json:"tx_hash"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	Burned      bool               `bson:"-" json:"burned,omitempty"`
+}
+
+// NFTQueryParams represents query parameters for listing NFTs held by an address
+type NFTQueryParams struct {
+	Owner  string
+	Token  string // Optional collection filter
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Provider states reported on /api/v1/status, matching the circuit breaker states
+const (
+	ProviderStateHealthy   = "healthy"
+	ProviderStateUnhealthy = "unhealthy"
+	ProviderStateHalfOpen  = "half_open"
+)
+
+// ProviderStatus reports an RPC provider's circuit breaker state and latency
+type ProviderStatus struct {
+	Name          string     `json:"name"`
+	State         string     `json:"state"`           // healthy, unhealthy or half_open
+	LatencyMs     float64    `json:"latency_ms"`      // Moving average of request durations
+	LogRangeLimit uint64     `json:"log_range_limit"` // Widest eth_getLogs range the provider is sent
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+}
+
+// ChainStatus reports how far a chain's ingestion is behind its head
+// On the replica running ingestion the head and batch size are those of the last batch;
+// other replicas query the head from their providers and report the configured batch size
+type ChainStatus struct {
+	ChainID            uint64           `json:"chain_id"`
+	Name               string           `json:"name"`
+	Ingesting          bool             `json:"ingesting"` // True on the replica running this chain's ingestion
+	Mode               string           `json:"mode,omitempty"`
+	Paused             bool             `json:"paused"`
+	HeadBlock          uint64           `json:"head_block"` // Indexing head: the head by INDEXING_MODE less CONFIRMATION_DEPTH
+	LastProcessedBlock uint64           `json:"last_processed_block"`
+	LagBlocks          uint64           `json:"lag_blocks"`
+	LagSeconds         float64          `json:"lag_seconds"` // Time since the last processed block was produced
+	BatchSize          uint64           `json:"batch_size"`
+	AdaptiveBatch      bool             `json:"adaptive_batch"`
+	BatchStrategy      string           `json:"batch_strategy,omitempty"` // streak or aimd, with adaptive batching
+	Providers          []ProviderStatus `json:"providers"`
+	Error              string           `json:"error,omitempty"` // Why head or lag could not be determined
+}
+
+// StreamStatus reports the stream clients connected to this replica
+type StreamStatus struct {
+	Enabled bool `json:"enabled"`
+	Clients int  `json:"clients"`
+}
+
+// StatusResponse is the body of /api/v1/status
+type StatusResponse struct {
+	Chains []*ChainStatus `json:"chains"`
+	Stream StreamStatus   `json:"stream"`
+	Leader LeaderStatus   `json:"leader"`
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Transfer kinds, derived from the zero address on either side
+const (
+	TransferKindMint     = "mint"     // From the zero address
+	TransferKindBurn     = "burn"     // To the zero address
+	TransferKindTransfer = "transfer" // Anything else, including zero-to-zero
+)
+
+// ZeroAddress is the lowercase hex zero address used as sender of mints and recipient of burns
+const ZeroAddress = "0x0000000000000000000000000000000000000000"
+
+// ClassifyTransfer returns the kind of a transfer between two lowercase addresses
+func ClassifyTransfer(from, to string) string {
+	switch {
+	case from == ZeroAddress && to == ZeroAddress:
+		return TransferKindTransfer
+	case from == ZeroAddress:
+		return TransferKindMint
+	case to == ZeroAddress:
+		return TransferKindBurn
+	default:
+		return TransferKindTransfer
+	}
+}
+
+// SupplyChange is the net supply movement of an ERC-20 token in one block
+// Amounts are raw base units as decimal strings; Supply is the running circulating supply after the block,
+// folded when the token's current supply is next read
+type SupplyChange struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	ChainID     uint64             `bson:"chain_id" json:"chain_id"`
+	Token       string             `bson:"token" json:"token"`
+	BlockNumber uint64             `bson:"block_number" json:"block_number"`
+	BlockHash   string             `bson:"block_hash,omitempty" json:"block_hash,omitempty"`
+	Minted      string             `bson:"minted" json:"minted"`
+	Burned      string             `bson:"burned" json:"burned"`
+	Supply      string             `bson:"supply" json:"supply"`
+	Timestamp   time.Time          `bson:"timestamp" json:"timestamp"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"-"`
+}
+
+// TokenSupply is the current circulating supply of an ERC-20 token, as seen from indexed mints and burns
+// Tokens indexed from a later start block than their deployment report the supply change since then
+type TokenSupply struct {
+	ID        string    `bson:"_id" json:"-"` // "<chain_id>:<token>"
+	ChainID   uint64    `bson:"chain_id" json:"chain_id"`
+	Token     string    `bson:"token" json:"token"`
+	Supply    string    `bson:"supply" json:"supply"`
+	LastBlock uint64    `bson:"last_block" json:"last_block"` // Block of the latest mint or burn
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+	DirtyFrom *uint64   `bson:"dirty_from,omitempty" json:"-"` // Lowest block whose running supply must be refolded
+	Version   int64     `bson:"version" json:"-"`              // Bumped by every change, guards folds against concurrent writes
+}
+
+// SupplyQueryParams represents query parameters for a token's supply history
+type SupplyQueryParams struct {
+	Token      string
+	StartBlock *uint64
+	EndBlock   *uint64
+	Limit      int
+	Offset     int
+}
+
+// SupplyResponse is a token's current supply with a page of its history, newest first
+type SupplyResponse struct {
+	ChainID       uint64          `json:"chain_id"`
+	Token         string          `json:"token"`
+	Symbol        string          `json:"symbol,omitempty"`
+	Decimals      *uint8          `json:"decimals,omitempty"`
+	Supply        string          `json:"supply"`
+	SupplyDecimal float64         `json:"supply_decimal"`
+	LastBlock     uint64          `json:"last_block"`
+	History       []*SupplyChange `json:"history"`
+	Total         int64           `json:"total"`
+	Limit         int             `json:"limit"`
+	Offset        int             `json:"offset"`
+}
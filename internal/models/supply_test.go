@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+func TestClassifyTransfer(t *testing.T) {
+	const holder = "0x1111111111111111111111111111111111111111"
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{name: "mint", from: ZeroAddress, to: holder, want: TransferKindMint},
+		{name: "burn", from: holder, to: ZeroAddress, want: TransferKindBurn},
+		{name: "transfer", from: holder, to: "0x2222222222222222222222222222222222222222", want: TransferKindTransfer},
+		{name: "zero to zero", from: ZeroAddress, to: ZeroAddress, want: TransferKindTransfer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyTransfer(tt.from, tt.to); got != tt.want {
+				t.Errorf("ClassifyTransfer(%s, %s) = %s, want %s", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
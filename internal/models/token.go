@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// TokenMetadata holds the ERC-20 metadata of a token contract, read once via eth_call
+// Fields the contract does not implement are left empty; Decimals then falls back to 18
+type TokenMetadata struct {
+	ID          string    `bson:"_id" json:"-"` // "<chain_id>:<address>"
+	ChainID     uint64    `bson:"chain_id" json:"chain_id"`
+	Address     string    `bson:"address" json:"address"` // Lowercase contract address
+	Name        string    `bson:"name" json:"name"`
+	Symbol      string    `bson:"symbol" json:"symbol"`
+	Decimals    uint8     `bson:"decimals" json:"decimals"`
+	HasDecimals bool      `bson:"has_decimals" json:"has_decimals"` // False if decimals() is missing and the default was used
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// TokenFilterRule is a persisted allow/deny entry (lowercase token address and start block)
+type TokenFilterRule struct {
+	Address    string `bson:"address" json:"address"`
+	StartBlock uint64 `bson:"start_block" json:"start_block"`
+}
+
+// TokenFilterState is the token allow/deny configuration the indexer last ran with
+// Compared against the current config at startup to backfill newly added tokens
+type TokenFilterState struct {
+	ID        string            `bson:"_id" json:"-"` // Chain ID as a decimal string
+	ChainID   uint64            `bson:"chain_id" json:"chain_id"`
+	Allow     []TokenFilterRule `bson:"allow" json:"allow"`
+	Deny      []TokenFilterRule `bson:"deny" json:"deny"`
+	UpdatedAt time.Time         `bson:"updated_at" json:"updated_at"`
+}
@@ -6,44 +6,91 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// Transfer represents a normalized ERC-20 Transfer event
+// Transfer finality statuses, promoted as the chain advances
+const (
+	TransferStatusPending   = "pending"   // Above the safe head, may still be reorged
+	TransferStatusSafe      = "safe"      // At or below the safe head
+	TransferStatusFinalized = "finalized" // At or below the finalized head, will not be reorged
+)
+
+// IsTransferStatus reports whether status is one of the transfer finality statuses
+func IsTransferStatus(status string) bool {
+	switch status {
+	case TransferStatusPending, TransferStatusSafe, TransferStatusFinalized:
+		return true
+	}
+	return false
+}
+
+// Token standards stored on transfers
+const (
+	StandardERC20   = "erc20"
+	StandardERC1155 = "erc1155"
+	StandardNative  = "native" // Plain ETH value transfers, recorded under a pseudo-token address
+)
+
+// Transfer represents a normalized fungible transfer (ERC-20 Transfer, ERC-1155 TransferSingle/TransferBatch, native or internal ETH)
 // Value is stored as Decimal128 for precise arithmetic operations and faster aggregations
-// event_signature allows future expansion to other event types (Approval, etc.)
+// A TransferBatch log is fanned out into one record per id, told apart by BatchIndex
+// Internal transfers are numbered within their parent transaction by BatchIndex
 type Transfer struct {
 	ID             primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
-	EventSignature string               `bson:"event_signature" json:"event_signature"` // "Transfer" for ERC-20 transfers
+	ChainID        uint64               `bson:"chain_id" json:"chain_id"`
+	EventSignature string               `bson:"event_signature" json:"event_signature"` // "Transfer", "TransferSingle", "TransferBatch", "NativeTransfer" or "InternalTransfer"
+	Standard       string               `bson:"standard" json:"standard"`               // erc20, erc1155 or native (missing on legacy records = erc20)
 	Token          string               `bson:"token" json:"token"`
+	Operator       string               `bson:"operator,omitempty" json:"operator,omitempty"` // ERC-1155 only
+	TokenID        string               `bson:"token_id,omitempty" json:"token_id,omitempty"` // ERC-1155 only, decimal string
+	BatchIndex     uint                 `bson:"batch_index,omitempty" json:"batch_index,omitempty"`
+	CallType       string               `bson:"call_type,omitempty" json:"call_type,omitempty"`         // InternalTransfer only: CALL, CREATE, CREATE2 or SELFDESTRUCT
+	TraceAddress   string               `bson:"trace_address,omitempty" json:"trace_address,omitempty"` // InternalTransfer only: position in the call tree, e.g. "0.2"
+	Kind           string               `bson:"kind" json:"kind"`                                       // mint, burn or transfer
 	From           string               `bson:"from" json:"from"`
 	To             string               `bson:"to" json:"to"`
 	Value          primitive.Decimal128 `bson:"value" json:"value"`                                   // Decimal128 for precision and performance
 	ValueString    string               `bson:"value_string,omitempty" json:"value_string,omitempty"` // Legacy: kept for backward compatibility
 	ValueDecimal   float64              `bson:"value_decimal" json:"value_decimal"`                   // Human-readable decimal representation
 	BlockNumber    uint64               `bson:"block_number" json:"block_number"`
+	BlockHash      string               `bson:"block_hash,omitempty" json:"block_hash,omitempty"` // Used to detect transfers orphaned by a reorg
 	TxHash         string               `bson:"tx_hash" json:"tx_hash"`
 	TxIndex        uint                 `bson:"tx_index" json:"tx_index"`
 	LogIndex       uint                 `bson:"log_index" json:"log_index"`
+	Status         string               `bson:"status" json:"status"` // pending, safe or finalized
 	Timestamp      time.Time            `bson:"timestamp" json:"timestamp"`
 	CreatedAt      time.Time            `bson:"created_at" json:"created_at"`
+	Symbol         string               `bson:"-" json:"symbol,omitempty"`   // Token symbol from metadata, filled in when served
+	Decimals       *uint8               `bson:"-" json:"decimals,omitempty"` // Token decimals from metadata; unset for ERC-1155
+	Removed        bool                 `bson:"-" json:"removed,omitempty"`  // Set on stream events for transfers rolled back by a reorg
+	ValueOverflow  bool                 `bson:"-" json:"-"`                  // Set by the parsers when Value fell back to zero; ValueString stays exact
 }
 
 // ProcessedBlock tracks the last processed block for idempotency
+// BlockHash and ParentHash are compared against the canonical chain to detect reorgs
 type ProcessedBlock struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	ChainID     uint64             `bson:"chain_id"`
 	BlockNumber uint64             `bson:"block_number"`
+	BlockHash   string             `bson:"block_hash,omitempty"`
+	ParentHash  string             `bson:"parent_hash,omitempty"`
 	ProcessedAt time.Time          `bson:"processed_at"`
 }
 
 // TransferQueryParams represents query parameters for filtering transfers
 type TransferQueryParams struct {
-	Token      string
-	From       string
-	To         string
-	StartBlock *uint64
-	EndBlock   *uint64
-	StartTime  *time.Time
-	EndTime    *time.Time
-	Limit      int
-	Offset     int
+	ChainID        *uint64 // nil = all indexed chains
+	Token          string
+	EventSignature string
+	Standard       string
+	Kind           string
+	From           string
+	To             string
+	StartBlock     *uint64
+	EndBlock       *uint64
+	StartTime      *time.Time
+	EndTime        *time.Time
+	Status         string
+	Limit          int
+	Offset         int
 }
 
 // AggregateResponse represents aggregated statistics
@@ -51,6 +98,8 @@ type AggregateResponse struct {
 	TotalTransfers    int64   `json:"total_transfers"`
 	TotalValue        string  `json:"total_value"`
 	TotalValueDecimal float64 `json:"total_value_decimal"`
+	Symbol            string  `json:"symbol,omitempty"`   // Set when filtered by token
+	Decimals          *uint8  `json:"decimals,omitempty"` // Set when filtered by token
 	UniqueTokens      int64   `json:"unique_tokens"`
 	UniqueAddresses   int64   `json:"unique_addresses"`
 	TimeRange         struct {
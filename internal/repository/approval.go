@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pagrin/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ApprovalRepository persists ERC-20 Approval events and the derived allowance table
+type ApprovalRepository interface {
+	InsertApprovals(ctx context.Context, approvals []*models.Approval) error
+	GetAllowances(ctx context.Context, params models.AllowanceQueryParams) ([]*models.Allowance, int64, error)
+}
+
+func (r *MongoRepository) createApprovalIndexes(ctx context.Context) error {
+	// Superseded by the chain-scoped unique indexes below
+	if err := dropIndexIfExists(ctx, r.approvalsColl, "tx_hash_1_log_index_1"); err != nil {
+		return err
+	}
+	if err := dropIndexIfExists(ctx, r.allowancesColl, "token_1_owner_1_spender_1"); err != nil {
+		return err
+	}
+
+	approvalIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "chain_id", Value: int32(1)},
+				{Key: "tx_hash", Value: int32(1)},
+				{Key: "log_index", Value: int32(1)},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "token", Value: int32(1)},
+				{Key: "owner", Value: int32(1)},
+				{Key: "spender", Value: int32(1)},
+				{Key: "block_number", Value: int32(-1)},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "block_number", Value: int32(-1)},
+			},
+		},
+	}
+
+	if _, err := r.approvalsColl.Indexes().CreateMany(ctx, approvalIndexes); err != nil {
+		return err
+	}
+
+	allowanceIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "chain_id", Value: int32(1)},
+				{Key: "token", Value: int32(1)},
+				{Key: "owner", Value: int32(1)},
+				{Key: "spender", Value: int32(1)},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "owner", Value: int32(1)},
+				{Key: "open", Value: int32(1)},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "spender", Value: int32(1)},
+				{Key: "open", Value: int32(1)},
+				{Key: "unlimited", Value: int32(1)},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "block_number", Value: int32(-1)},
+			},
+		},
+	}
+
+	_, err := r.allowancesColl.Indexes().CreateMany(ctx, allowanceIndexes)
+	return err
+}
+
+// InsertApprovals stores Approval events and advances the allowance of each (token, owner, spender)
+// As with NFT ownership, an allowance only moves to a later event in chain order
+func (r *MongoRepository) InsertApprovals(ctx context.Context, approvals []*models.Approval) error {
+	if len(approvals) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, len(approvals))
+	for i, approval := range approvals {
+		approval.ChainID = r.chainID
+		// Duplicate keys abort a transaction, so inside one only missing approvals are upserted
+		if inTransaction(ctx) {
+			filter := r.scoped(bson.M{"tx_hash": approval.TxHash, "log_index": approval.LogIndex})
+			writes[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(bson.M{"$setOnInsert": approval}).SetUpsert(true)
+			continue
+		}
+		writes[i] = mongo.NewInsertOneModel().SetDocument(approval)
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+	if _, err := r.approvalsColl.BulkWrite(ctx, writes, opts); err != nil && !mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("failed to bulk write approvals: %w", err)
+	}
+
+	return r.applyAllowances(ctx, approvals)
+}
+
+// applyAllowances upserts the allowance row of every (token, owner, spender) touched by approvals
+func (r *MongoRepository) applyAllowances(ctx context.Context, approvals []*models.Approval) error {
+	latest := make(map[[3]string]*models.Approval, len(approvals))
+	for _, approval := range approvals {
+		key := [3]string{approval.Token, approval.Owner, approval.Spender}
+		current, ok := latest[key]
+		if !ok || approval.BlockNumber > current.BlockNumber ||
+			(approval.BlockNumber == current.BlockNumber && approval.LogIndex > current.LogIndex) {
+			latest[key] = approval
+		}
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(latest))
+	for _, approval := range latest {
+		filter := r.scoped(bson.M{"token": approval.Token, "owner": approval.Owner, "spender": approval.Spender})
+		update := advanceTo(approval.BlockNumber, approval.LogIndex, allowanceFields(approval))
+		writes = append(writes, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	// A duplicate key means two writers raced to create the row; retry once so the loser updates it
+	opts := options.BulkWrite().SetOrdered(false)
+	for attempt := 0; attempt < 2; attempt++ {
+		_, err := r.allowancesColl.BulkWrite(ctx, writes, opts)
+		if err == nil {
+			return nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("failed to update allowances: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetAllowances returns open (non-zero) allowances matching params, most recent first
+func (r *MongoRepository) GetAllowances(ctx context.Context, params models.AllowanceQueryParams) ([]*models.Allowance, int64, error) {
+	filter := r.scoped(bson.M{"open": true})
+	if params.Owner != "" {
+		filter["owner"] = params.Owner
+	}
+	if params.Token != "" {
+		filter["token"] = params.Token
+	}
+	if params.Spenders != nil {
+		filter["spender"] = bson.M{"$in": params.Spenders}
+	}
+	if params.UnlimitedOnly {
+		filter["unlimited"] = true
+	}
+
+	count, err := r.allowancesColl.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count allowances: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{
+			{Key: "block_number", Value: -1},
+			{Key: "log_index", Value: -1},
+		}).
+		SetSkip(int64(params.Offset)).
+		SetLimit(int64(params.Limit))
+
+	cursor, err := r.allowancesColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query allowances: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var allowances []*models.Allowance
+	if err := cursor.All(ctx, &allowances); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode allowances: %w", err)
+	}
+
+	return allowances, count, nil
+}
+
+// rollbackApprovals removes Approval events above blockNumber and restores every affected allowance
+func (r *MongoRepository) rollbackApprovals(ctx context.Context, blockNumber uint64) error {
+	filter := r.scoped(bson.M{"block_number": bson.M{"$gt": blockNumber}})
+
+	cursor, err := r.allowancesColl.Find(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to find orphaned allowances: %w", err)
+	}
+	var affected []*models.Allowance
+	if err := cursor.All(ctx, &affected); err != nil {
+		return fmt.Errorf("failed to decode orphaned allowances: %w", err)
+	}
+
+	if _, err := r.approvalsColl.DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("failed to delete orphaned approvals: %w", err)
+	}
+
+	for _, allowance := range affected {
+		keyFilter := r.scoped(bson.M{"token": allowance.Token, "owner": allowance.Owner, "spender": allowance.Spender})
+
+		var previous models.Approval
+		opts := options.FindOne().SetSort(bson.D{
+			{Key: "block_number", Value: -1},
+			{Key: "log_index", Value: -1},
+		})
+		err := r.approvalsColl.FindOne(ctx, keyFilter, opts).Decode(&previous)
+		if err == mongo.ErrNoDocuments {
+			if _, err := r.allowancesColl.DeleteOne(ctx, keyFilter); err != nil {
+				return fmt.Errorf("failed to delete allowance: %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find previous approval: %w", err)
+		}
+
+		if _, err := r.allowancesColl.UpdateOne(ctx, keyFilter, bson.M{"$set": allowanceFields(&previous)}); err != nil {
+			return fmt.Errorf("failed to restore allowance: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// allowanceFields returns the allowance state set by an approval
+func allowanceFields(approval *models.Approval) bson.M {
+	return bson.M{
+		"value":        approval.Value,
+		"unlimited":    approval.Unlimited,
+		"open":         approval.Value != "0",
+		"block_number": approval.BlockNumber,
+		"log_index":    approval.LogIndex,
+		"tx_hash":      approval.TxHash,
+		"updated_at":   time.Now(),
+	}
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"testing"
+
+	"pagrin/internal/models"
+)
+
+func TestAllowanceFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		approval  models.Approval
+		wantOpen  bool
+		wantValue string
+	}{
+		{name: "granted", approval: models.Approval{Value: "1000", BlockNumber: 10, LogIndex: 2}, wantOpen: true, wantValue: "1000"},
+		{name: "unlimited", approval: models.Approval{Value: "340282366920938463463374607431768211456", Unlimited: true}, wantOpen: true, wantValue: "340282366920938463463374607431768211456"},
+		{name: "revoked", approval: models.Approval{Value: "0"}, wantOpen: false, wantValue: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := allowanceFields(&tt.approval)
+			if fields["open"] != tt.wantOpen {
+				t.Errorf("open = %v, want %v", fields["open"], tt.wantOpen)
+			}
+			if fields["value"] != tt.wantValue || fields["unlimited"] != tt.approval.Unlimited {
+				t.Errorf("value = %v (unlimited %v), want %s (unlimited %v)", fields["value"], fields["unlimited"], tt.wantValue, tt.approval.Unlimited)
+			}
+			if fields["block_number"] != tt.approval.BlockNumber || fields["log_index"] != tt.approval.LogIndex {
+				t.Errorf("position = %v/%v, want %d/%d", fields["block_number"], fields["log_index"], tt.approval.BlockNumber, tt.approval.LogIndex)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pagrin/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BackfillRepository persists backfill jobs and their chunk state
+// Kept separate from Repository so the live ingestion path does not depend on it
+type BackfillRepository interface {
+	CreateBackfillJob(ctx context.Context, job *models.BackfillJob, chunks []*models.BackfillChunk) error
+	GetBackfillJob(ctx context.Context, id primitive.ObjectID) (*models.BackfillJob, error)
+	ListBackfillJobs(ctx context.Context, limit int) ([]*models.BackfillJob, error)
+	GetUnfinishedBackfillJobs(ctx context.Context) ([]*models.BackfillJob, error)
+	GetFailedBackfillJobsSince(ctx context.Context, since time.Time) ([]*models.BackfillJob, error)
+	SetBackfillJobStatus(ctx context.Context, id primitive.ObjectID, status string) error
+	ClaimBackfillChunk(ctx context.Context, jobID primitive.ObjectID) (*models.BackfillChunk, error)
+	CompleteBackfillChunk(ctx context.Context, chunk *models.BackfillChunk, transfers int) error
+	FailBackfillChunk(ctx context.Context, chunk *models.BackfillChunk, chunkErr error, maxAttempts int) error
+	ResetRunningBackfillChunks(ctx context.Context) (int64, error)
+	GetBackfillChunkStats(ctx context.Context, jobID primitive.ObjectID) (map[string]int64, uint64, error)
+}
+
+func (r *MongoRepository) createBackfillIndexes(ctx context.Context) error {
+	chunkIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "job_id", Value: int32(1)},
+				{Key: "status", Value: int32(1)},
+				{Key: "from_block", Value: int32(1)},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "chain_id", Value: int32(1)},
+				{Key: "status", Value: int32(1)},
+			},
+		},
+	}
+
+	if err := dropIndexIfExists(ctx, r.backfillChunksColl, "status_1"); err != nil {
+		return err
+	}
+	if err := dropIndexIfExists(ctx, r.backfillJobsColl, "status_1_created_at_-1"); err != nil {
+		return err
+	}
+
+	if _, err := r.backfillChunksColl.Indexes().CreateMany(ctx, chunkIndexes); err != nil {
+		return err
+	}
+
+	jobIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "chain_id", Value: int32(1)},
+			{Key: "status", Value: int32(1)},
+			{Key: "created_at", Value: int32(-1)},
+		},
+	}
+
+	if _, err := r.backfillJobsColl.Indexes().CreateOne(ctx, jobIndex); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateBackfillJob stores a new job and all of its chunks
+// Chunks are inserted with InsertMany since a job may span thousands of them
+func (r *MongoRepository) CreateBackfillJob(ctx context.Context, job *models.BackfillJob, chunks []*models.BackfillChunk) error {
+	now := time.Now()
+	job.ID = primitive.NewObjectID()
+	job.ChainID = r.chainID
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	job.TotalChunks = int64(len(chunks))
+
+	if _, err := r.backfillJobsColl.InsertOne(ctx, job); err != nil {
+		return fmt.Errorf("failed to insert backfill job: %w", err)
+	}
+
+	docs := make([]interface{}, len(chunks))
+	for i, chunk := range chunks {
+		chunk.ChainID = r.chainID
+		chunk.JobID = job.ID
+		chunk.Status = models.BackfillStatusPending
+		chunk.UpdatedAt = now
+		docs[i] = chunk
+	}
+
+	if len(docs) > 0 {
+		if _, err := r.backfillChunksColl.InsertMany(ctx, docs); err != nil {
+			return fmt.Errorf("failed to insert backfill chunks: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetBackfillJob retrieves a job by ID
+// Returns nil without error if the job does not exist on this chain
+func (r *MongoRepository) GetBackfillJob(ctx context.Context, id primitive.ObjectID) (*models.BackfillJob, error) {
+	var job models.BackfillJob
+	err := r.backfillJobsColl.FindOne(ctx, r.scoped(bson.M{"_id": id})).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backfill job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// ListBackfillJobs returns the most recent jobs, newest first
+func (r *MongoRepository) ListBackfillJobs(ctx context.Context, limit int) ([]*models.BackfillJob, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	return r.findBackfillJobs(ctx, r.scoped(bson.M{}), opts)
+}
+
+// GetUnfinishedBackfillJobs returns jobs that were pending or running, used to resume after a restart
+func (r *MongoRepository) GetUnfinishedBackfillJobs(ctx context.Context) ([]*models.BackfillJob, error) {
+	filter := r.scoped(bson.M{"status": bson.M{"$in": []string{models.BackfillStatusPending, models.BackfillStatusRunning}}})
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	return r.findBackfillJobs(ctx, filter, opts)
+}
+
+// GetFailedBackfillJobsSince returns jobs that failed at or after since, most recent first
+func (r *MongoRepository) GetFailedBackfillJobsSince(ctx context.Context, since time.Time) ([]*models.BackfillJob, error) {
+	filter := r.scoped(bson.M{"status": models.BackfillStatusFailed, "completed_at": bson.M{"$gte": since}})
+	opts := options.Find().SetSort(bson.D{{Key: "completed_at", Value: -1}})
+
+	return r.findBackfillJobs(ctx, filter, opts)
+}
+
+func (r *MongoRepository) findBackfillJobs(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*models.BackfillJob, error) {
+	cursor, err := r.backfillJobsColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query backfill jobs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []*models.BackfillJob
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to decode backfill jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// SetBackfillJobStatus updates a job's status, stamping completed_at for terminal states
+func (r *MongoRepository) SetBackfillJobStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	now := time.Now()
+	set := bson.M{
+		"status":     status,
+		"updated_at": now,
+	}
+	if status == models.BackfillStatusCompleted || status == models.BackfillStatusFailed {
+		set["completed_at"] = now
+	}
+
+	if _, err := r.backfillJobsColl.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set}); err != nil {
+		return fmt.Errorf("failed to set backfill job status: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimBackfillChunk atomically moves the lowest pending chunk of a job to running
+// Returns nil without error when no pending chunks remain
+func (r *MongoRepository) ClaimBackfillChunk(ctx context.Context, jobID primitive.ObjectID) (*models.BackfillChunk, error) {
+	filter := bson.M{
+		"job_id": jobID,
+		"status": models.BackfillStatusPending,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     models.BackfillStatusRunning,
+			"updated_at": time.Now(),
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "from_block", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var chunk models.BackfillChunk
+	err := r.backfillChunksColl.FindOneAndUpdate(ctx, filter, update, opts).Decode(&chunk)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim backfill chunk: %w", err)
+	}
+
+	return &chunk, nil
+}
+
+// CompleteBackfillChunk marks a chunk completed and updates the job's progress counters
+func (r *MongoRepository) CompleteBackfillChunk(ctx context.Context, chunk *models.BackfillChunk, transfers int) error {
+	now := time.Now()
+	update := bson.M{
+		"$set":   bson.M{"status": models.BackfillStatusCompleted, "updated_at": now},
+		"$unset": bson.M{"last_error": ""},
+	}
+	if _, err := r.backfillChunksColl.UpdateOne(ctx, bson.M{"_id": chunk.ID}, update); err != nil {
+		return fmt.Errorf("failed to complete backfill chunk: %w", err)
+	}
+
+	jobUpdate := bson.M{
+		"$inc": bson.M{"completed_chunks": 1, "transfers": transfers},
+		"$set": bson.M{"updated_at": now},
+	}
+	if _, err := r.backfillJobsColl.UpdateOne(ctx, bson.M{"_id": chunk.JobID}, jobUpdate); err != nil {
+		return fmt.Errorf("failed to update backfill job progress: %w", err)
+	}
+
+	return nil
+}
+
+// FailBackfillChunk records a chunk failure
+// The chunk returns to pending for another attempt until maxAttempts is reached, then it is marked failed
+func (r *MongoRepository) FailBackfillChunk(ctx context.Context, chunk *models.BackfillChunk, chunkErr error, maxAttempts int) error {
+	now := time.Now()
+	status := models.BackfillStatusPending
+	if chunk.Attempts >= maxAttempts {
+		status = models.BackfillStatusFailed
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":     status,
+			"last_error": chunkErr.Error(),
+			"updated_at": now,
+		},
+	}
+	if _, err := r.backfillChunksColl.UpdateOne(ctx, bson.M{"_id": chunk.ID}, update); err != nil {
+		return fmt.Errorf("failed to record backfill chunk failure: %w", err)
+	}
+
+	if status == models.BackfillStatusFailed {
+		jobUpdate := bson.M{
+			"$inc": bson.M{"failed_chunks": 1},
+			"$set": bson.M{"updated_at": now},
+		}
+		if _, err := r.backfillJobsColl.UpdateOne(ctx, bson.M{"_id": chunk.JobID}, jobUpdate); err != nil {
+			return fmt.Errorf("failed to update backfill job progress: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ResetRunningBackfillChunks returns chunks left running by a previous process to pending
+// Must only be called at startup, before any workers are running
+func (r *MongoRepository) ResetRunningBackfillChunks(ctx context.Context) (int64, error) {
+	update := bson.M{"$set": bson.M{"status": models.BackfillStatusPending, "updated_at": time.Now()}}
+
+	result, err := r.backfillChunksColl.UpdateMany(ctx, r.scoped(bson.M{"status": models.BackfillStatusRunning}), update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset running backfill chunks: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// GetBackfillChunkStats counts a job's chunks by status and sums the blocks covered by completed chunks
+func (r *MongoRepository) GetBackfillChunkStats(ctx context.Context, jobID primitive.ObjectID) (map[string]int64, uint64, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"job_id": jobID}},
+		{
+			"$group": bson.M{
+				"_id":   "$status",
+				"count": bson.M{"$sum": 1},
+				"blocks": bson.M{"$sum": bson.M{
+					"$add": []interface{}{bson.M{"$subtract": []interface{}{"$to_block", "$from_block"}}, 1},
+				}},
+			},
+		},
+	}
+
+	cursor, err := r.backfillChunksColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to aggregate backfill chunks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Status string `bson:"_id"`
+		Count  int64  `bson:"count"`
+		Blocks int64  `bson:"blocks"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode backfill chunk stats: %w", err)
+	}
+
+	counts := make(map[string]int64, len(results))
+	var blocksDone uint64
+	for _, result := range results {
+		counts[result.Status] = result.Count
+		if result.Status == models.BackfillStatusCompleted {
+			blocksDone = uint64(result.Blocks)
+		}
+	}
+
+	return counts, blocksDone, nil
+}
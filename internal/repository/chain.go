@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pagrin/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// legacyChainFilter matches documents written before multi-chain support
+var legacyChainFilter = bson.M{"chain_id": bson.M{"$exists": false}}
+
+// AssignLegacyChainID assigns documents written before multi-chain support to this repository's chain
+// Only documents without a chain_id are touched, so this is a no-op once migrated
+// Must run before ingestion starts; returns the number of documents migrated
+func (r *MongoRepository) AssignLegacyChainID(ctx context.Context) (int64, error) {
+	scoped := []*mongo.Collection{
+		r.transfersColl,
+		r.processedColl,
+		r.backfillJobsColl,
+		r.backfillChunksColl,
+		r.coverageColl,
+		r.nftTransfersColl,
+		r.nftOwnersColl,
+		r.approvalsColl,
+		r.allowancesColl,
+		r.supplyChangesColl,
+	}
+
+	update := bson.M{"$set": bson.M{"chain_id": r.chainID}}
+	var migrated int64
+	for _, coll := range scoped {
+		result, err := coll.UpdateMany(ctx, legacyChainFilter, update)
+		if err != nil {
+			return migrated, fmt.Errorf("failed to assign chain to %s: %w", coll.Name(), err)
+		}
+		migrated += result.ModifiedCount
+	}
+
+	// Metadata was keyed by address alone; it only caches eth_call results, so it is resolved again on demand
+	if _, err := r.tokenMetadataColl.DeleteMany(ctx, legacyChainFilter); err != nil {
+		return migrated, fmt.Errorf("failed to delete legacy token metadata: %w", err)
+	}
+
+	supplies, err := r.rekeyLegacySupplies(ctx)
+	if err != nil {
+		return migrated, err
+	}
+	migrated += supplies
+
+	filterState, err := r.rekeyLegacyTokenFilterState(ctx)
+	if err != nil {
+		return migrated, err
+	}
+	if filterState {
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+// rekeyLegacySupplies moves token supplies keyed by token address to chain-scoped keys
+func (r *MongoRepository) rekeyLegacySupplies(ctx context.Context) (int64, error) {
+	cursor, err := r.tokenSuppliesColl.Find(ctx, legacyChainFilter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find legacy token supplies: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rekeyed int64
+	for cursor.Next(ctx) {
+		var legacy struct {
+			Token     string    `bson:"_id"`
+			Supply    string    `bson:"supply"`
+			LastBlock uint64    `bson:"last_block"`
+			UpdatedAt time.Time `bson:"updated_at"`
+		}
+		if err := cursor.Decode(&legacy); err != nil {
+			return rekeyed, fmt.Errorf("failed to decode legacy token supply: %w", err)
+		}
+
+		supply := models.TokenSupply{
+			ID:        r.chainKey(legacy.Token),
+			ChainID:   r.chainID,
+			Token:     legacy.Token,
+			Supply:    legacy.Supply,
+			LastBlock: legacy.LastBlock,
+			UpdatedAt: legacy.UpdatedAt,
+		}
+		if _, err := r.tokenSuppliesColl.ReplaceOne(ctx, bson.M{"_id": supply.ID}, supply, options.Replace().SetUpsert(true)); err != nil {
+			return rekeyed, fmt.Errorf("failed to save token supply: %w", err)
+		}
+		if _, err := r.tokenSuppliesColl.DeleteOne(ctx, bson.M{"_id": legacy.Token}); err != nil {
+			return rekeyed, fmt.Errorf("failed to delete legacy token supply: %w", err)
+		}
+		rekeyed++
+	}
+	if err := cursor.Err(); err != nil {
+		return rekeyed, fmt.Errorf("failed to scan legacy token supplies: %w", err)
+	}
+
+	return rekeyed, nil
+}
+
+// rekeyLegacyTokenFilterState moves the single pre-multi-chain token filter state to this chain
+// A state already saved for this chain wins; the legacy document is dropped either way
+func (r *MongoRepository) rekeyLegacyTokenFilterState(ctx context.Context) (bool, error) {
+	var legacy models.TokenFilterState
+	err := r.tokenFiltersColl.FindOne(ctx, bson.M{"_id": legacyTokenFilterStateID}).Decode(&legacy)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get legacy token filter state: %w", err)
+	}
+
+	current, err := r.GetTokenFilterState(ctx)
+	if err != nil {
+		return false, err
+	}
+	if current == nil {
+		if err := r.SaveTokenFilterState(ctx, &legacy); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err := r.tokenFiltersColl.DeleteOne(ctx, bson.M{"_id": legacyTokenFilterStateID}); err != nil {
+		return false, fmt.Errorf("failed to delete legacy token filter state: %w", err)
+	}
+	return current == nil, nil
+}
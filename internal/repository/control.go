@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pagrin/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ControlRepository persists admin requests for a chain's ingestion and their audit trail
+type ControlRepository interface {
+	GetIngestionControl(ctx context.Context) (*models.IngestionControl, error)
+	SetIngestionPaused(ctx context.Context, paused bool, reason, actor string) (*models.IngestionControl, error)
+	SetIngestionBatchSize(ctx context.Context, batchSize uint64, actor string) (*models.IngestionControl, error)
+	RequestIngestionRewind(ctx context.Context, rewind *models.IngestionRewind) (*models.IngestionControl, error)
+	CompleteIngestionRewind(ctx context.Context, requestedAt time.Time) error
+	RewindToBlock(ctx context.Context, blockNumber uint64, purge bool) (int64, error)
+	InsertAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	ListAuditEntries(ctx context.Context, params models.AuditQueryParams) ([]*models.AuditEntry, int64, error)
+}
+
+func (r *MongoRepository) createControlIndexes(ctx context.Context) error {
+	controlIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "chain_id", Value: int32(1)}},
+		Options: options.Index().SetUnique(true),
+	}
+	if _, err := r.controlColl.Indexes().CreateOne(ctx, controlIndex); err != nil {
+		return err
+	}
+
+	auditIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "chain_id", Value: int32(1)},
+			{Key: "created_at", Value: int32(-1)},
+		},
+	}
+	_, err := r.auditColl.Indexes().CreateOne(ctx, auditIndex)
+	return err
+}
+
+// GetIngestionControl returns the chain's ingestion control document
+// Returns nil without error if no admin request was ever made
+func (r *MongoRepository) GetIngestionControl(ctx context.Context) (*models.IngestionControl, error) {
+	var control models.IngestionControl
+	err := r.controlColl.FindOne(ctx, r.scoped(bson.M{})).Decode(&control)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingestion control: %w", err)
+	}
+	return &control, nil
+}
+
+// SetIngestionPaused pauses or resumes ingestion; resuming clears the pause reason
+func (r *MongoRepository) SetIngestionPaused(ctx context.Context, paused bool, reason, actor string) (*models.IngestionControl, error) {
+	set := bson.M{"paused": paused}
+	unset := bson.M{}
+	if paused && reason != "" {
+		set["pause_reason"] = reason
+	} else {
+		unset["pause_reason"] = ""
+	}
+	return r.updateIngestionControl(ctx, actor, set, unset)
+}
+
+// SetIngestionBatchSize records a batch size for ingestion to switch to
+func (r *MongoRepository) SetIngestionBatchSize(ctx context.Context, batchSize uint64, actor string) (*models.IngestionControl, error) {
+	return r.updateIngestionControl(ctx, actor, bson.M{"batch_size": batchSize, "batch_size_set_at": time.Now()}, nil)
+}
+
+// RequestIngestionRewind records a rewind, replacing any rewind not applied yet
+func (r *MongoRepository) RequestIngestionRewind(ctx context.Context, rewind *models.IngestionRewind) (*models.IngestionControl, error) {
+	return r.updateIngestionControl(ctx, rewind.RequestedBy, bson.M{"rewind": rewind}, nil)
+}
+
+// CompleteIngestionRewind clears the pending rewind once applied
+// Matching on requestedAt keeps a newer rewind requested in the meantime
+func (r *MongoRepository) CompleteIngestionRewind(ctx context.Context, requestedAt time.Time) error {
+	filter := r.scoped(bson.M{"rewind.requested_at": requestedAt})
+	if _, err := r.controlColl.UpdateOne(ctx, filter, bson.M{"$unset": bson.M{"rewind": ""}}); err != nil {
+		return fmt.Errorf("failed to clear ingestion rewind: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) updateIngestionControl(ctx context.Context, actor string, set, unset bson.M) (*models.IngestionControl, error) {
+	set["updated_at"] = time.Now()
+	set["updated_by"] = actor
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var control models.IngestionControl
+	if err := r.controlColl.FindOneAndUpdate(ctx, r.scoped(bson.M{}), update, opts).Decode(&control); err != nil {
+		return nil, fmt.Errorf("failed to update ingestion control: %w", err)
+	}
+	return &control, nil
+}
+
+// RewindToBlock moves the checkpoint back to blockNumber and returns how many transfers were purged
+// With purge, transfers and derived data above blockNumber are deleted as on a reorg, without
+// loading them; otherwise only later checkpoints are deleted and the data is re-ingested over
+func (r *MongoRepository) RewindToBlock(ctx context.Context, blockNumber uint64, purge bool) (int64, error) {
+	if !purge {
+		return 0, r.rollbackCheckpoints(ctx, blockNumber)
+	}
+
+	result, err := r.transfersColl.DeleteMany(ctx, r.scoped(bson.M{"block_number": bson.M{"$gt": blockNumber}}))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge transfers: %w", err)
+	}
+
+	if err := r.rollbackDerived(ctx, blockNumber); err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// InsertAuditEntry records an admin action
+func (r *MongoRepository) InsertAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	entry.ID = primitive.NewObjectID()
+	entry.ChainID = r.chainID
+	entry.CreatedAt = time.Now()
+
+	if _, err := r.auditColl.InsertOne(ctx, entry); err != nil {
+		return fmt.Errorf("failed to insert audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEntries returns audit entries newest first, with the total matching count
+func (r *MongoRepository) ListAuditEntries(ctx context.Context, params models.AuditQueryParams) ([]*models.AuditEntry, int64, error) {
+	filter := r.scoped(bson.M{})
+	if params.Action != "" {
+		filter["action"] = params.Action
+	}
+
+	count, err := r.auditColl.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64(params.Offset)).
+		SetLimit(int64(params.Limit))
+
+	cursor, err := r.auditColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query audit entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*models.AuditEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode audit entries: %w", err)
+	}
+
+	return entries, count, nil
+}
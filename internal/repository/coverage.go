@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pagrin/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *MongoRepository) createCoverageIndexes(ctx context.Context) error {
+	if err := dropIndexIfExists(ctx, r.coverageColl, "from_block_1_to_block_1"); err != nil {
+		return err
+	}
+
+	index := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "chain_id", Value: int32(1)},
+			{Key: "from_block", Value: int32(1)},
+			{Key: "to_block", Value: int32(1)},
+		},
+	}
+
+	_, err := r.coverageColl.Indexes().CreateOne(ctx, index)
+	return err
+}
+
+// AddCoveredRange records [fromBlock, toBlock] as fully ingested
+// Overlapping and adjacent ranges are merged into one document
+// Existing ranges are deleted by ID, so concurrent writers can at worst leave overlaps, never lose coverage
+func (r *MongoRepository) AddCoveredRange(ctx context.Context, fromBlock, toBlock uint64) error {
+	r.coverageMu.Lock()
+	defer r.coverageMu.Unlock()
+
+	// Adjacent ranges (to_block == fromBlock-1) are merged too
+	lower := fromBlock
+	if lower > 0 {
+		lower--
+	}
+	filter := r.scoped(bson.M{
+		"from_block": bson.M{"$lte": toBlock + 1},
+		"to_block":   bson.M{"$gte": lower},
+	})
+
+	existing, err := r.findCoveredRanges(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	merged := models.CoveredRange{ChainID: r.chainID, FromBlock: fromBlock, ToBlock: toBlock, UpdatedAt: time.Now()}
+	ids := make([]primitive.ObjectID, 0, len(existing))
+	for _, rng := range existing {
+		if rng.FromBlock < merged.FromBlock {
+			merged.FromBlock = rng.FromBlock
+		}
+		if rng.ToBlock > merged.ToBlock {
+			merged.ToBlock = rng.ToBlock
+		}
+		ids = append(ids, rng.ID)
+	}
+
+	if _, err := r.coverageColl.InsertOne(ctx, merged); err != nil {
+		return fmt.Errorf("failed to insert covered range: %w", err)
+	}
+
+	if len(ids) > 0 {
+		if _, err := r.coverageColl.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+			return fmt.Errorf("failed to delete merged covered ranges: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetCoveredRanges returns all covered ranges sorted by start block, with overlaps coalesced
+func (r *MongoRepository) GetCoveredRanges(ctx context.Context) ([]*models.CoveredRange, error) {
+	ranges, err := r.findCoveredRanges(ctx, r.scoped(bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+
+	coalesced := make([]*models.CoveredRange, 0, len(ranges))
+	for _, rng := range ranges {
+		if n := len(coalesced); n > 0 && rng.FromBlock <= coalesced[n-1].ToBlock+1 {
+			if rng.ToBlock > coalesced[n-1].ToBlock {
+				coalesced[n-1].ToBlock = rng.ToBlock
+			}
+			continue
+		}
+		coalesced = append(coalesced, rng)
+	}
+
+	return coalesced, nil
+}
+
+// CountCoveredRanges returns the number of stored range documents
+func (r *MongoRepository) CountCoveredRanges(ctx context.Context) (int64, error) {
+	count, err := r.coverageColl.CountDocuments(ctx, r.scoped(bson.M{}))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count covered ranges: %w", err)
+	}
+	return count, nil
+}
+
+// truncateCoverage drops coverage above blockNumber after a reorg rollback
+func (r *MongoRepository) truncateCoverage(ctx context.Context, blockNumber uint64) error {
+	r.coverageMu.Lock()
+	defer r.coverageMu.Unlock()
+
+	if _, err := r.coverageColl.DeleteMany(ctx, r.scoped(bson.M{"from_block": bson.M{"$gt": blockNumber}})); err != nil {
+		return fmt.Errorf("failed to delete covered ranges: %w", err)
+	}
+
+	update := bson.M{"$set": bson.M{"to_block": blockNumber, "updated_at": time.Now()}}
+	if _, err := r.coverageColl.UpdateMany(ctx, r.scoped(bson.M{"to_block": bson.M{"$gt": blockNumber}}), update); err != nil {
+		return fmt.Errorf("failed to truncate covered ranges: %w", err)
+	}
+
+	return nil
+}
+
+// SeedCoverageFromCheckpoints reconstructs covered ranges from legacy processed_blocks records
+// Each checkpoint is the last block of a batch, so consecutive checkpoints at most maxBatch
+// apart are treated as contiguous; larger jumps (e.g. a RESET_START_BLOCK run) become gaps
+// Returns the number of ranges written
+func (r *MongoRepository) SeedCoverageFromCheckpoints(ctx context.Context, maxBatch uint64) (int, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "block_number", Value: 1}}).
+		SetProjection(bson.M{"block_number": 1})
+
+	cursor, err := r.processedColl.Find(ctx, r.scoped(bson.M{}), opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan processed blocks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var ranges []models.CoveredRange
+	for cursor.Next(ctx) {
+		var processed models.ProcessedBlock
+		if err := cursor.Decode(&processed); err != nil {
+			return 0, fmt.Errorf("failed to decode processed block: %w", err)
+		}
+
+		n := len(ranges)
+		if n > 0 && processed.BlockNumber <= ranges[n-1].ToBlock+maxBatch {
+			ranges[n-1].ToBlock = processed.BlockNumber
+			continue
+		}
+		// The start of the first batch after a jump is unknown - only claim the checkpoint itself
+		ranges = append(ranges, models.CoveredRange{
+			FromBlock: processed.BlockNumber,
+			ToBlock:   processed.BlockNumber,
+			UpdatedAt: time.Now(),
+		})
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, fmt.Errorf("failed to scan processed blocks: %w", err)
+	}
+
+	for _, rng := range ranges {
+		if err := r.AddCoveredRange(ctx, rng.FromBlock, rng.ToBlock); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(ranges), nil
+}
+
+func (r *MongoRepository) findCoveredRanges(ctx context.Context, filter bson.M) ([]*models.CoveredRange, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "from_block", Value: 1}})
+
+	cursor, err := r.coverageColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query covered ranges: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var ranges []*models.CoveredRange
+	if err := cursor.All(ctx, &ranges); err != nil {
+		return nil, fmt.Errorf("failed to decode covered ranges: %w", err)
+	}
+
+	return ranges, nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pagrin/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeadLetterRepository persists logs the parsers rejected or stored in degraded form
+type DeadLetterRepository interface {
+	SaveDeadLetters(ctx context.Context, letters []*models.DeadLetter) error
+	ListDeadLetters(ctx context.Context, params models.DeadLetterQueryParams) ([]*models.DeadLetter, int64, error)
+	GetDeadLetters(ctx context.Context, ids []primitive.ObjectID) ([]*models.DeadLetter, error)
+	ResolveDeadLetter(ctx context.Context, id primitive.ObjectID) error
+	FailDeadLetter(ctx context.Context, id primitive.ObjectID, kind, reason string) error
+	DeleteLogTransfers(ctx context.Context, txHash string, logIndex uint) error
+}
+
+func (r *MongoRepository) createDeadLetterIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			// One dead letter per log, so re-ingesting a range updates it instead of adding another
+			Keys: bson.D{
+				{Key: "chain_id", Value: int32(1)},
+				{Key: "tx_hash", Value: int32(1)},
+				{Key: "log_index", Value: int32(1)},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "chain_id", Value: int32(1)},
+				{Key: "status", Value: int32(1)},
+				{Key: "block_number", Value: int32(1)},
+			},
+		},
+	}
+
+	_, err := r.deadLettersColl.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// SaveDeadLetters upserts dead letters by (tx_hash, log_index)
+// A log recorded again is reopened with its latest reason; its attempt count is kept
+func (r *MongoRepository) SaveDeadLetters(ctx context.Context, letters []*models.DeadLetter) error {
+	if len(letters) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	writes := make([]mongo.WriteModel, len(letters))
+	for i, letter := range letters {
+		letter.ChainID = r.chainID
+		letter.Status = models.DeadLetterStatusOpen
+		letter.UpdatedAt = now
+
+		filter := r.scoped(bson.M{"tx_hash": letter.TxHash, "log_index": letter.LogIndex})
+		update := bson.M{
+			"$set": bson.M{
+				"kind":         letter.Kind,
+				"event":        letter.Event,
+				"reason":       letter.Reason,
+				"address":      letter.Address,
+				"topics":       letter.Topics,
+				"data":         letter.Data,
+				"block_number": letter.BlockNumber,
+				"block_hash":   letter.BlockHash,
+				"tx_index":     letter.TxIndex,
+				"timestamp":    letter.Timestamp,
+				"status":       letter.Status,
+				"updated_at":   now,
+			},
+			"$unset":       bson.M{"resolved_at": ""},
+			"$setOnInsert": bson.M{"attempts": 0, "created_at": now},
+		}
+		writes[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true)
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+	if _, err := r.deadLettersColl.BulkWrite(ctx, writes, opts); err != nil {
+		return fmt.Errorf("failed to save dead letters: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeadLetters returns dead letters oldest block first, with the total matching count
+func (r *MongoRepository) ListDeadLetters(ctx context.Context, params models.DeadLetterQueryParams) ([]*models.DeadLetter, int64, error) {
+	filter := r.scoped(bson.M{})
+	if params.Status != "" {
+		filter["status"] = params.Status
+	}
+	if params.Kind != "" {
+		filter["kind"] = params.Kind
+	}
+
+	count, err := r.deadLettersColl.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{
+			{Key: "block_number", Value: 1},
+			{Key: "log_index", Value: 1},
+		}).
+		SetSkip(int64(params.Offset)).
+		SetLimit(int64(params.Limit))
+
+	letters, err := r.findDeadLetters(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return letters, count, nil
+}
+
+// GetDeadLetters returns the dead letters with the given IDs, oldest block first
+func (r *MongoRepository) GetDeadLetters(ctx context.Context, ids []primitive.ObjectID) ([]*models.DeadLetter, error) {
+	opts := options.Find().SetSort(bson.D{
+		{Key: "block_number", Value: 1},
+		{Key: "log_index", Value: 1},
+	})
+	return r.findDeadLetters(ctx, r.scoped(bson.M{"_id": bson.M{"$in": ids}}), opts)
+}
+
+// ResolveDeadLetter marks a dead letter as re-processed successfully
+func (r *MongoRepository) ResolveDeadLetter(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{"status": models.DeadLetterStatusResolved, "updated_at": now, "resolved_at": now},
+		"$inc": bson.M{"attempts": 1},
+	}
+	if _, err := r.deadLettersColl.UpdateOne(ctx, r.scoped(bson.M{"_id": id}), update); err != nil {
+		return fmt.Errorf("failed to resolve dead letter: %w", err)
+	}
+	return nil
+}
+
+// FailDeadLetter records a re-processing attempt that was still rejected or degraded
+func (r *MongoRepository) FailDeadLetter(ctx context.Context, id primitive.ObjectID, kind, reason string) error {
+	update := bson.M{
+		"$set": bson.M{"kind": kind, "reason": reason, "updated_at": time.Now()},
+		"$inc": bson.M{"attempts": 1},
+	}
+	if _, err := r.deadLettersColl.UpdateOne(ctx, r.scoped(bson.M{"_id": id}), update); err != nil {
+		return fmt.Errorf("failed to update dead letter: %w", err)
+	}
+	return nil
+}
+
+// DeleteLogTransfers removes the transfers decoded from one log so a re-processed version can replace them
+func (r *MongoRepository) DeleteLogTransfers(ctx context.Context, txHash string, logIndex uint) error {
+	filter := r.scoped(bson.M{"tx_hash": txHash, "log_index": logIndex})
+	if _, err := r.transfersColl.DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("failed to delete transfers of log: %w", err)
+	}
+	return nil
+}
+
+// rollbackDeadLetters removes dead letters of blocks above blockNumber after a reorg
+func (r *MongoRepository) rollbackDeadLetters(ctx context.Context, blockNumber uint64) error {
+	filter := r.scoped(bson.M{"block_number": bson.M{"$gt": blockNumber}})
+	if _, err := r.deadLettersColl.DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("failed to delete orphaned dead letters: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) findDeadLetters(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*models.DeadLetter, error) {
+	cursor, err := r.deadLettersColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letters: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var letters []*models.DeadLetter
+	if err := cursor.All(ctx, &letters); err != nil {
+		return nil, fmt.Errorf("failed to decode dead letters: %w", err)
+	}
+
+	return letters, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"pagrin/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestSaveDeadLetters(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("reopens a log recorded again", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		letters := []*models.DeadLetter{
+			{Kind: models.DeadLetterRejected, Reason: "bad topics", TxHash: "0xa", LogIndex: 3, Status: models.DeadLetterStatusResolved},
+			{Kind: models.DeadLetterDegraded, Reason: "overflow", TxHash: "0xb", LogIndex: 0},
+		}
+		mt.AddMockResponses(mockWrite(2))
+
+		if err := r.SaveDeadLetters(context.Background(), letters); err != nil {
+			mt.Fatalf("SaveDeadLetters() error = %v", err)
+		}
+		for _, letter := range letters {
+			if letter.ChainID != 1 || letter.Status != models.DeadLetterStatusOpen {
+				mt.Errorf("dead letter %s:%d = chain %d %s, want chain 1 open", letter.TxHash, letter.LogIndex, letter.ChainID, letter.Status)
+			}
+		}
+
+		names, bodies := sentCommands(mt)
+		if fmt.Sprint(names) != "[update]" {
+			mt.Fatalf("commands = %v, want one update", names)
+		}
+		if ordered := bodies[0].Lookup("ordered").Boolean(); ordered {
+			mt.Errorf("bulk write is ordered, want unordered")
+		}
+		update := bodies[0].Lookup("updates", "0")
+		if upsert := update.Document().Lookup("upsert").Boolean(); !upsert {
+			mt.Errorf("update is not an upsert")
+		}
+		filter := update.Document().Lookup("q").Document()
+		if chain, tx, index := filter.Lookup("chain_id").AsInt64(), filter.Lookup("tx_hash").StringValue(), filter.Lookup("log_index").AsInt64(); chain != 1 || tx != "0xa" || index != 3 {
+			mt.Errorf("filter = %v, want chain 1, tx 0xa, log 3", filter)
+		}
+		u := update.Document().Lookup("u").Document()
+		if status := u.Lookup("$set", "status").StringValue(); status != models.DeadLetterStatusOpen {
+			mt.Errorf("$set status = %s, want open", status)
+		}
+		if _, err := u.LookupErr("$unset", "resolved_at"); err != nil {
+			mt.Errorf("resolved_at is not cleared: %v", err)
+		}
+		// Attempts only start at zero for new dead letters
+		if _, err := u.LookupErr("$set", "attempts"); err == nil {
+			mt.Errorf("$set resets attempts")
+		}
+		if attempts := u.Lookup("$setOnInsert", "attempts").AsInt64(); attempts != 0 {
+			mt.Errorf("$setOnInsert attempts = %d, want 0", attempts)
+		}
+	})
+
+	mt.Run("skips empty batches", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		if err := r.SaveDeadLetters(context.Background(), nil); err != nil {
+			mt.Fatalf("SaveDeadLetters() error = %v", err)
+		}
+		if names, _ := sentCommands(mt); len(names) != 0 {
+			mt.Errorf("commands = %v, want none", names)
+		}
+	})
+}
+
+func TestUpdateDeadLetter(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("resolve", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		mt.AddMockResponses(mockWrite(1))
+		if err := r.ResolveDeadLetter(context.Background(), id); err != nil {
+			mt.Fatalf("ResolveDeadLetter() error = %v", err)
+		}
+
+		_, bodies := sentCommands(mt)
+		update := bodies[0].Lookup("updates", "0").Document()
+		if got := update.Lookup("q", "_id").ObjectID(); got != id {
+			mt.Errorf("filter _id = %s, want %s", got.Hex(), id.Hex())
+		}
+		if status := update.Lookup("u", "$set", "status").StringValue(); status != models.DeadLetterStatusResolved {
+			mt.Errorf("$set status = %s, want resolved", status)
+		}
+		if _, err := update.LookupErr("u", "$set", "resolved_at"); err != nil {
+			mt.Errorf("resolved_at not set: %v", err)
+		}
+		if inc := update.Lookup("u", "$inc", "attempts").AsInt64(); inc != 1 {
+			mt.Errorf("$inc attempts = %d, want 1", inc)
+		}
+	})
+
+	mt.Run("fail keeps it open", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		mt.AddMockResponses(mockWrite(1))
+		if err := r.FailDeadLetter(context.Background(), id, models.DeadLetterDegraded, "still overflows"); err != nil {
+			mt.Fatalf("FailDeadLetter() error = %v", err)
+		}
+
+		_, bodies := sentCommands(mt)
+		set := bodies[0].Lookup("updates", "0", "u", "$set").Document()
+		if kind, reason := set.Lookup("kind").StringValue(), set.Lookup("reason").StringValue(); kind != models.DeadLetterDegraded || reason != "still overflows" {
+			mt.Errorf("$set = %s %q, want the latest kind and reason", kind, reason)
+		}
+		if _, err := set.LookupErr("status"); err == nil {
+			mt.Errorf("$set changes the status, want it left open")
+		}
+		if inc := bodies[0].Lookup("updates", "0", "u", "$inc", "attempts").AsInt64(); inc != 1 {
+			mt.Errorf("$inc attempts = %d, want 1", inc)
+		}
+	})
+
+	mt.Run("write error", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11600, Message: "interrupted"}))
+		if err := r.ResolveDeadLetter(context.Background(), id); err == nil {
+			mt.Errorf("ResolveDeadLetter() error = nil, want the write error")
+		}
+	})
+}
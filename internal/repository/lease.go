@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LeaseRepository stores named leases shared by every replica; leases are not scoped by chain
+type LeaseRepository interface {
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+// AcquireLease takes the named lease for holder, or extends it if holder already has it
+// Returns false while another holder's lease has not expired
+// Expiry is compared with the server clock ($$NOW) so replicas need not agree on the time
+func (r *MongoRepository) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{"holder": holder},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$expires_at", "$$NOW"}}},
+		},
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"holder":     holder,
+			"expires_at": bson.M{"$add": bson.A{"$$NOW", ttl.Milliseconds()}},
+			"renewed_at": "$$NOW",
+		}}},
+	}
+
+	// A missing lease is inserted; one held by another replica fails the upsert on _id
+	result, err := r.leasesColl.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+
+	return result.MatchedCount > 0 || result.UpsertedCount > 0, nil
+}
+
+// ReleaseLease gives up the named lease if holder still has it
+func (r *MongoRepository) ReleaseLease(ctx context.Context, name, holder string) error {
+	if _, err := r.leasesColl.DeleteOne(ctx, bson.M{"_id": name, "holder": holder}); err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+	return nil
+}
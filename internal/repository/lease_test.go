@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestAcquireLease(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	upserted := mtest.CreateSuccessResponse(
+		bson.E{Key: "n", Value: 1},
+		bson.E{Key: "nModified", Value: 0},
+		bson.E{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: "ingestion"}}}},
+	)
+
+	tests := []struct {
+		name     string
+		response bson.D
+		want     bool
+		wantErr  bool
+	}{
+		{name: "extends own lease", response: mockWrite(1), want: true},
+		{name: "takes a missing lease", response: upserted, want: true},
+		{
+			name:     "held by another replica",
+			response: mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "E11000 duplicate key error"}),
+			want:     false,
+		},
+		{
+			name:     "server error",
+			response: mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad value"}),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			r := newMockRepository(mt)
+			mt.AddMockResponses(tt.response)
+
+			got, err := r.AcquireLease(context.Background(), "ingestion", "replica-a", 15*time.Second)
+			if (err != nil) != tt.wantErr {
+				mt.Fatalf("AcquireLease() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				mt.Errorf("AcquireLease() = %v, want %v", got, tt.want)
+			}
+
+			_, bodies := sentCommands(mt)
+			update := bodies[0].Lookup("updates", "0").Document()
+			if upsert := update.Lookup("upsert").Boolean(); !upsert {
+				mt.Errorf("lease update is not an upsert")
+			}
+			// Leases are shared by every chain, so the filter is not scoped
+			filter := update.Lookup("q").Document()
+			if _, err := filter.LookupErr("chain_id"); err == nil {
+				mt.Errorf("lease filter is scoped to a chain")
+			}
+			if id := filter.Lookup("_id").StringValue(); id != "ingestion" {
+				mt.Errorf("lease filter _id = %s, want ingestion", id)
+			}
+			if holder := filter.Lookup("$or", "0", "holder").StringValue(); holder != "replica-a" {
+				mt.Errorf("lease filter holder = %s, want replica-a", holder)
+			}
+			if _, err := filter.LookupErr("$or", "1", "$expr", "$lt"); err != nil {
+				mt.Errorf("lease filter does not match expired leases: %v", err)
+			}
+			// Expiry is computed from the server clock
+			expires := update.Lookup("u", "0", "$set", "expires_at", "$add").Array()
+			if now := expires.Index(0).Value().StringValue(); now != "$$NOW" {
+				mt.Errorf("expires_at base = %s, want $$NOW", now)
+			}
+			if ttl := expires.Index(1).Value().AsInt64(); ttl != 15000 {
+				mt.Errorf("expires_at ttl = %dms, want 15000ms", ttl)
+			}
+		})
+	}
+}
+
+func TestReleaseLease(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("deletes only the holder's lease", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		mt.AddMockResponses(mockWrite(0))
+		if err := r.ReleaseLease(context.Background(), "ingestion", "replica-a"); err != nil {
+			mt.Fatalf("ReleaseLease() error = %v", err)
+		}
+
+		names, bodies := sentCommands(mt)
+		if len(names) != 1 || names[0] != "delete" {
+			mt.Fatalf("commands = %v, want one delete", names)
+		}
+		filter := bodies[0].Lookup("deletes", "0", "q").Document()
+		if id, holder := filter.Lookup("_id").StringValue(), filter.Lookup("holder").StringValue(); id != "ingestion" || holder != "replica-a" {
+			mt.Errorf("release filter = %v, want the ingestion lease of replica-a", filter)
+		}
+	})
+}
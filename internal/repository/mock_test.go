@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// newMockRepository returns a chain 1 repository whose collections all talk to the mock deployment of mt
+// Responses are consumed in order, one per command sent
+func newMockRepository(mt *mtest.T) *MongoRepository {
+	coll := mt.Coll
+	return &MongoRepository{
+		chainID: 1,
+		collections: &collections{
+			transfersColl:      coll,
+			processedColl:      coll,
+			backfillJobsColl:   coll,
+			backfillChunksColl: coll,
+			coverageColl:       coll,
+			tokenFiltersColl:   coll,
+			nftTransfersColl:   coll,
+			nftOwnersColl:      coll,
+			approvalsColl:      coll,
+			allowancesColl:     coll,
+			tokenMetadataColl:  coll,
+			supplyChangesColl:  coll,
+			tokenSuppliesColl:  coll,
+			deadLettersColl:    coll,
+			leasesColl:         coll,
+			controlColl:        coll,
+			auditColl:          coll,
+		},
+	}
+}
+
+// mockCursor returns a single-batch find response holding docs
+func mockCursor(mt *mtest.T, docs ...bson.D) bson.D {
+	return mtest.CreateCursorResponse(0, mt.Coll.Database().Name()+"."+mt.Coll.Name(), mtest.FirstBatch, docs...)
+}
+
+// mockWrite returns the response of a write command affecting n documents
+func mockWrite(n int) bson.D {
+	return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n}, bson.E{Key: "nModified", Value: n})
+}
+
+// sentCommands returns the name and body of every command sent to the mock deployment, in order
+func sentCommands(mt *mtest.T) ([]string, []bson.Raw) {
+	names := make([]string, 0)
+	bodies := make([]bson.Raw, 0)
+	for _, started := range mt.GetAllStartedEvents() {
+		names = append(names, started.CommandName)
+		bodies = append(bodies, started.Command)
+	}
+	return names, bodies
+}
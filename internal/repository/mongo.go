@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"pagrin/internal/models"
@@ -15,18 +17,56 @@ import (
 type Repository interface {
 	InsertTransfers(ctx context.Context, transfers []*models.Transfer) error
 	GetLastProcessedBlock(ctx context.Context) (uint64, error)
-	SetLastProcessedBlock(ctx context.Context, blockNumber uint64) error
+	SetLastProcessedBlock(ctx context.Context, blockNumber uint64, blockHash, parentHash string) error
+	GetProcessedBlock(ctx context.Context, blockNumber uint64) (*models.ProcessedBlock, error)
+	GetProcessedBlocks(ctx context.Context, fromBlock, toBlock uint64) ([]*models.ProcessedBlock, error)
+	RollbackToBlock(ctx context.Context, blockNumber uint64) ([]*models.Transfer, error)
+	GetTransfersByBlockRange(ctx context.Context, fromBlock, toBlock uint64) ([]*models.Transfer, error)
+	SetTransferStatus(ctx context.Context, status string, fromBlock, toBlock uint64) (int64, error)
+	AddCoveredRange(ctx context.Context, fromBlock, toBlock uint64) error
+	GetCoveredRanges(ctx context.Context) ([]*models.CoveredRange, error)
+	CountCoveredRanges(ctx context.Context) (int64, error)
+	SeedCoverageFromCheckpoints(ctx context.Context, maxBatch uint64) (int, error)
 	QueryTransfers(ctx context.Context, params models.TransferQueryParams) ([]*models.Transfer, int64, error)
 	GetAggregates(ctx context.Context, params models.TransferQueryParams) (*models.AggregateResponse, error)
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	ChainID() uint64
 	Close(ctx context.Context) error
 }
 
+// MongoRepository stores indexed data for one chain; every read and write is scoped by chainID
+// The root repository returned by NewMongoRepository is only used for cross-chain queries and
+// to derive per-chain repositories with ForChain, which share its client and collections
 type MongoRepository struct {
-	client        *mongo.Client
-	db            *mongo.Database
-	transfersColl *mongo.Collection
-	processedColl *mongo.Collection
-	cache         BlockCache // Optional Redis cache for fast lookups
+	*collections
+	client     *mongo.Client
+	db         *mongo.Database
+	chainID    uint64
+	coverageMu sync.Mutex // Serializes range merges within this process
+	supplyMu   sync.Mutex // Serializes supply folding within this process
+	cache      BlockCache // Optional Redis cache for fast lookups
+	// Replica set or sharded cluster; standalone servers fall back to non-transactional writes
+	transactions bool
+}
+
+type collections struct {
+	transfersColl      *mongo.Collection
+	processedColl      *mongo.Collection
+	backfillJobsColl   *mongo.Collection
+	backfillChunksColl *mongo.Collection
+	coverageColl       *mongo.Collection
+	tokenFiltersColl   *mongo.Collection
+	nftTransfersColl   *mongo.Collection
+	nftOwnersColl      *mongo.Collection
+	approvalsColl      *mongo.Collection
+	allowancesColl     *mongo.Collection
+	tokenMetadataColl  *mongo.Collection
+	supplyChangesColl  *mongo.Collection
+	tokenSuppliesColl  *mongo.Collection
+	deadLettersColl    *mongo.Collection
+	leasesColl         *mongo.Collection
+	controlColl        *mongo.Collection
+	auditColl          *mongo.Collection
 }
 
 // BlockCache interface for last processed block caching
@@ -36,9 +76,9 @@ type BlockCache interface {
 	SetLastProcessedBlock(ctx context.Context, blockNumber uint64) error
 }
 
-// NewMongoRepository creates a new MongoDB repository
-// cache can be nil if Redis is not available - repository will work with MongoDB only
-func NewMongoRepository(uri, database string, cache BlockCache) (*MongoRepository, error) {
+// NewMongoRepository connects to MongoDB and ensures indexes exist
+// Use ForChain to obtain the repository of an indexed chain
+func NewMongoRepository(uri, database string) (*MongoRepository, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -52,15 +92,30 @@ func NewMongoRepository(uri, database string, cache BlockCache) (*MongoRepositor
 	}
 
 	db := client.Database(database)
-	transfersColl := db.Collection("transfers")
-	processedColl := db.Collection("processed_blocks")
 
 	repo := &MongoRepository{
-		client:        client,
-		db:            db,
-		transfersColl: transfersColl,
-		processedColl: processedColl,
-		cache:         cache,
+		collections: &collections{
+			transfersColl:      db.Collection("transfers"),
+			processedColl:      db.Collection("processed_blocks"),
+			backfillJobsColl:   db.Collection("backfill_jobs"),
+			backfillChunksColl: db.Collection("backfill_chunks"),
+			coverageColl:       db.Collection("coverage"),
+			tokenFiltersColl:   db.Collection("token_filters"),
+			nftTransfersColl:   db.Collection("nft_transfers"),
+			nftOwnersColl:      db.Collection("nft_owners"),
+			approvalsColl:      db.Collection("approvals"),
+			allowancesColl:     db.Collection("allowances"),
+			tokenMetadataColl:  db.Collection("token_metadata"),
+			supplyChangesColl:  db.Collection("supply_changes"),
+			tokenSuppliesColl:  db.Collection("token_supplies"),
+			deadLettersColl:    db.Collection("dead_letters"),
+			leasesColl:         db.Collection("leases"),
+			controlColl:        db.Collection("ingestion_control"),
+			auditColl:          db.Collection("admin_audit"),
+		},
+		client:       client,
+		db:           db,
+		transactions: supportsTransactions(ctx, client),
 	}
 
 	if err := repo.createIndexes(ctx); err != nil {
@@ -70,7 +125,38 @@ func NewMongoRepository(uri, database string, cache BlockCache) (*MongoRepositor
 	return repo, nil
 }
 
+// ForChain returns a repository scoped to chainID, sharing this repository's connection
+// cache can be nil if Redis is not available - the repository then works with MongoDB only
+func (r *MongoRepository) ForChain(chainID uint64, cache BlockCache) *MongoRepository {
+	return &MongoRepository{
+		collections:  r.collections,
+		client:       r.client,
+		db:           r.db,
+		chainID:      chainID,
+		cache:        cache,
+		transactions: r.transactions,
+	}
+}
+
+// ChainID returns the chain this repository is scoped to
+func (r *MongoRepository) ChainID() uint64 {
+	return r.chainID
+}
+
+// scoped adds the repository's chain to a query filter and returns it
+func (r *MongoRepository) scoped(filter bson.M) bson.M {
+	filter["chain_id"] = r.chainID
+	return filter
+}
+
+// chainKey builds the _id of documents keyed by chain and address
+func (r *MongoRepository) chainKey(address string) string {
+	return fmt.Sprintf("%d:%s", r.chainID, address)
+}
+
 func (r *MongoRepository) createIndexes(ctx context.Context) error {
+	// Ingestion-path indexes lead with chain_id since those queries are always scoped to one chain;
+	// API query indexes stay unprefixed because /transfers may search across every chain
 	indexes := []mongo.IndexModel{
 		{
 			Keys: bson.D{
@@ -84,6 +170,23 @@ func (r *MongoRepository) createIndexes(ctx context.Context) error {
 				{Key: "block_number", Value: int32(-1)},
 			},
 		},
+		{
+			Keys: bson.D{
+				{Key: "block_number", Value: int32(-1)},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "chain_id", Value: int32(1)},
+				{Key: "block_number", Value: int32(-1)},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: int32(1)},
+				{Key: "block_number", Value: int32(-1)},
+			},
+		},
 		{
 			Keys: bson.D{
 				{Key: "from", Value: int32(1)},
@@ -101,19 +204,47 @@ func (r *MongoRepository) createIndexes(ctx context.Context) error {
 		},
 		{
 			Keys: bson.D{
+				{Key: "standard", Value: int32(1)},
+				{Key: "block_number", Value: int32(-1)},
+			},
+		},
+		{
+			// batch_index separates the per-id records of an ERC-1155 TransferBatch log;
+			// event_signature separates native transfers (which have no log) from log-based ones
+			Keys: bson.D{
+				{Key: "chain_id", Value: int32(1)},
 				{Key: "tx_hash", Value: int32(1)},
 				{Key: "log_index", Value: int32(1)},
+				{Key: "batch_index", Value: int32(1)},
+				{Key: "event_signature", Value: int32(1)},
 			},
 			Options: options.Index().SetUnique(true),
 		},
 	}
 
+	// Superseded by the chain-scoped indexes above
+	superseded := []string{
+		"tx_hash_1_log_index_1",
+		"tx_hash_1_log_index_1_batch_index_1",
+		"tx_hash_1_log_index_1_batch_index_1_event_signature_1",
+	}
+	for _, name := range superseded {
+		if err := dropIndexIfExists(ctx, r.transfersColl, name); err != nil {
+			return err
+		}
+	}
+
 	if _, err := r.transfersColl.Indexes().CreateMany(ctx, indexes); err != nil {
 		return err
 	}
 
+	if err := dropIndexIfExists(ctx, r.processedColl, "block_number_-1"); err != nil {
+		return err
+	}
+
 	processedIndex := mongo.IndexModel{
 		Keys: bson.D{
+			{Key: "chain_id", Value: int32(1)},
 			{Key: "block_number", Value: int32(-1)},
 		},
 		Options: options.Index().SetUnique(true),
@@ -123,9 +254,52 @@ func (r *MongoRepository) createIndexes(ctx context.Context) error {
 		return err
 	}
 
+	if err := r.createBackfillIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := r.createCoverageIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := r.createNFTIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := r.createApprovalIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := r.createSupplyIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := r.createTokenMetadataIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := r.createDeadLetterIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := r.createControlIndexes(ctx); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// dropIndexIfExists drops a named index, ignoring missing indexes and collections
+func dropIndexIfExists(ctx context.Context, coll *mongo.Collection, name string) error {
+	_, err := coll.Indexes().DropOne(ctx, name)
+	var cmdErr mongo.CommandError
+	if err != nil && errors.As(err, &cmdErr) && (cmdErr.Code == 26 || cmdErr.Code == 27) {
+		// 26 = NamespaceNotFound, 27 = IndexNotFound
+		return nil
+	}
+	return err
+}
+
 // InsertTransfers inserts transfer documents using BulkWrite for optimal performance
 // BulkWrite is ~30% faster than InsertMany and handles duplicates more gracefully
 // Uses unordered operations to maximize throughput
@@ -134,10 +308,17 @@ func (r *MongoRepository) InsertTransfers(ctx context.Context, transfers []*mode
 		return nil
 	}
 
+	// A duplicate key error aborts a transaction, so inside one transfers are upserted by their
+	// unique key instead; existing documents are left untouched just like ignored duplicates
+	if inTransaction(ctx) {
+		return r.upsertTransfers(ctx, transfers)
+	}
+
 	// Build write models for BulkWrite
 	// Each transfer becomes an InsertOneModel operation
 	models := make([]mongo.WriteModel, len(transfers))
 	for i, transfer := range transfers {
+		transfer.ChainID = r.chainID
 		models[i] = mongo.NewInsertOneModel().SetDocument(transfer)
 	}
 
@@ -167,6 +348,35 @@ func (r *MongoRepository) InsertTransfers(ctx context.Context, transfers []*mode
 	return nil
 }
 
+// upsertTransfers inserts transfers that are not stored yet, matching on the transfers unique index
+func (r *MongoRepository) upsertTransfers(ctx context.Context, transfers []*models.Transfer) error {
+	writes := make([]mongo.WriteModel, len(transfers))
+	for i, transfer := range transfers {
+		transfer.ChainID = r.chainID
+
+		filter := r.scoped(bson.M{
+			"tx_hash":         transfer.TxHash,
+			"log_index":       transfer.LogIndex,
+			"event_signature": transfer.EventSignature,
+		})
+		// batch_index is omitted when zero; $exists keeps it out of the inserted document
+		if transfer.BatchIndex == 0 {
+			filter["batch_index"] = bson.M{"$exists": false}
+		} else {
+			filter["batch_index"] = transfer.BatchIndex
+		}
+
+		update := bson.M{"$setOnInsert": transfer}
+		writes[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true)
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+	if _, err := r.transfersColl.BulkWrite(ctx, writes, opts); err != nil {
+		return fmt.Errorf("failed to bulk write transfers: %w", err)
+	}
+	return nil
+}
+
 // GetLastProcessedBlock retrieves the last processed block number
 // Uses Redis cache (if available) for fast lookup, falls back to MongoDB
 // This look-aside cache pattern provides sub-millisecond reads on hot path
@@ -184,7 +394,7 @@ func (r *MongoRepository) GetLastProcessedBlock(ctx context.Context) (uint64, er
 
 	// Fallback to MongoDB (source of truth)
 	var processed models.ProcessedBlock
-	err := r.processedColl.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.M{"block_number": -1})).Decode(&processed)
+	err := r.processedColl.FindOne(ctx, r.scoped(bson.M{}), options.FindOne().SetSort(bson.M{"block_number": -1})).Decode(&processed)
 	if err == mongo.ErrNoDocuments {
 		return 0, nil
 	}
@@ -204,15 +414,17 @@ func (r *MongoRepository) GetLastProcessedBlock(ctx context.Context) (uint64, er
 	return processed.BlockNumber, nil
 }
 
-// SetLastProcessedBlock stores the last processed block number
+// SetLastProcessedBlock stores the last processed block number along with its hash and parent hash
 // Writes to both Redis cache (if available) and MongoDB for durability
-// Write-through cache pattern ensures consistency
-func (r *MongoRepository) SetLastProcessedBlock(ctx context.Context, blockNumber uint64) error {
+// Write-through cache pattern ensures consistency; inside a transaction Redis is written after commit
+func (r *MongoRepository) SetLastProcessedBlock(ctx context.Context, blockNumber uint64, blockHash, parentHash string) error {
 	// Write to MongoDB first (source of truth)
-	filter := bson.M{"block_number": blockNumber}
+	filter := r.scoped(bson.M{"block_number": blockNumber})
 	update := bson.M{
 		"$set": bson.M{
 			"block_number": blockNumber,
+			"block_hash":   blockHash,
+			"parent_hash":  parentHash,
 			"processed_at": time.Now(),
 		},
 	}
@@ -223,16 +435,177 @@ func (r *MongoRepository) SetLastProcessedBlock(ctx context.Context, blockNumber
 	}
 
 	// Update Redis cache (best effort, don't fail if Redis is down)
+	// Deferred until commit so Redis never points past a checkpoint that could still roll back
 	if r.cache != nil {
-		if err := r.cache.SetLastProcessedBlock(ctx, blockNumber); err != nil {
-			// Log but don't fail - MongoDB write succeeded, cache is just optimization
-			// In production, you might want to use a logger here
-		}
+		afterCommit(ctx, func(ctx context.Context) {
+			if err := r.cache.SetLastProcessedBlock(ctx, blockNumber); err != nil {
+				// Log but don't fail - MongoDB write succeeded, cache is just optimization
+				// In production, you might want to use a logger here
+			}
+		})
 	}
 
 	return nil
 }
 
+// GetProcessedBlock retrieves the checkpoint record for a specific block
+// Returns nil without error if the block was never recorded as a checkpoint
+func (r *MongoRepository) GetProcessedBlock(ctx context.Context, blockNumber uint64) (*models.ProcessedBlock, error) {
+	var processed models.ProcessedBlock
+	err := r.processedColl.FindOne(ctx, r.scoped(bson.M{"block_number": blockNumber})).Decode(&processed)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get processed block %d: %w", blockNumber, err)
+	}
+
+	return &processed, nil
+}
+
+// GetProcessedBlocks retrieves checkpoint records within [fromBlock, toBlock], newest first
+// Used to walk back through recorded hashes when searching for a common ancestor
+func (r *MongoRepository) GetProcessedBlocks(ctx context.Context, fromBlock, toBlock uint64) ([]*models.ProcessedBlock, error) {
+	filter := r.scoped(bson.M{"block_number": bson.M{"$gte": fromBlock, "$lte": toBlock}})
+	opts := options.Find().SetSort(bson.D{{Key: "block_number", Value: -1}})
+
+	cursor, err := r.processedColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query processed blocks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var blocks []*models.ProcessedBlock
+	if err := cursor.All(ctx, &blocks); err != nil {
+		return nil, fmt.Errorf("failed to decode processed blocks: %w", err)
+	}
+
+	return blocks, nil
+}
+
+// RollbackToBlock removes all transfers, checkpoints and coverage above blockNumber after a reorg
+// NFT transfers and approvals above blockNumber are removed too and the derived owners, allowances and supplies restored
+// Returns the removed ERC-20 transfers so callers can notify stream subscribers
+// The Redis checkpoint is rewound as well so the next read does not skip the re-ingested range
+func (r *MongoRepository) RollbackToBlock(ctx context.Context, blockNumber uint64) ([]*models.Transfer, error) {
+	filter := r.scoped(bson.M{"block_number": bson.M{"$gt": blockNumber}})
+
+	cursor, err := r.transfersColl.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find orphaned transfers: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var removed []*models.Transfer
+	if err := cursor.All(ctx, &removed); err != nil {
+		return nil, fmt.Errorf("failed to decode orphaned transfers: %w", err)
+	}
+
+	if _, err := r.transfersColl.DeleteMany(ctx, filter); err != nil {
+		return nil, fmt.Errorf("failed to delete orphaned transfers: %w", err)
+	}
+
+	if err := r.rollbackDerived(ctx, blockNumber); err != nil {
+		return nil, err
+	}
+
+	return removed, nil
+}
+
+// rollbackDerived removes everything but transfers above blockNumber and rewinds the checkpoint to it
+func (r *MongoRepository) rollbackDerived(ctx context.Context, blockNumber uint64) error {
+	if err := r.rollbackNFTs(ctx, blockNumber); err != nil {
+		return err
+	}
+
+	if err := r.rollbackApprovals(ctx, blockNumber); err != nil {
+		return err
+	}
+
+	if err := r.rollbackSupply(ctx, blockNumber); err != nil {
+		return err
+	}
+
+	if err := r.rollbackDeadLetters(ctx, blockNumber); err != nil {
+		return err
+	}
+
+	if err := r.rollbackCheckpoints(ctx, blockNumber); err != nil {
+		return err
+	}
+
+	return r.truncateCoverage(ctx, blockNumber)
+}
+
+// rollbackCheckpoints deletes checkpoints above blockNumber and points the Redis checkpoint at it
+func (r *MongoRepository) rollbackCheckpoints(ctx context.Context, blockNumber uint64) error {
+	if _, err := r.processedColl.DeleteMany(ctx, r.scoped(bson.M{"block_number": bson.M{"$gt": blockNumber}})); err != nil {
+		return fmt.Errorf("failed to delete orphaned checkpoints: %w", err)
+	}
+
+	// Rewind Redis cache (best effort, MongoDB is the source of truth)
+	if r.cache != nil {
+		_ = r.cache.SetLastProcessedBlock(ctx, blockNumber)
+	}
+
+	return nil
+}
+
+// GetTransfersByBlockRange retrieves all transfers within [fromBlock, toBlock] in chain order
+func (r *MongoRepository) GetTransfersByBlockRange(ctx context.Context, fromBlock, toBlock uint64) ([]*models.Transfer, error) {
+	filter := r.scoped(bson.M{"block_number": bson.M{"$gte": fromBlock, "$lte": toBlock}})
+	opts := options.Find().SetSort(bson.D{
+		{Key: "block_number", Value: 1},
+		{Key: "log_index", Value: 1},
+	})
+
+	cursor, err := r.transfersColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transfers by block range: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var transfers []*models.Transfer
+	if err := cursor.All(ctx, &transfers); err != nil {
+		return nil, fmt.Errorf("failed to decode transfers: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// SetTransferStatus promotes transfers within [fromBlock, toBlock] to the given finality status
+// Only moves statuses forward (pending -> safe -> finalized); returns the number of updated documents
+// NFT transfers and approvals in the range are promoted alongside
+func (r *MongoRepository) SetTransferStatus(ctx context.Context, status string, fromBlock, toBlock uint64) (int64, error) {
+	filter := r.scoped(bson.M{"block_number": bson.M{"$gte": fromBlock, "$lte": toBlock}})
+	switch status {
+	case models.TransferStatusSafe:
+		filter["status"] = bson.M{"$nin": []string{models.TransferStatusSafe, models.TransferStatusFinalized}}
+	case models.TransferStatusFinalized:
+		filter["status"] = bson.M{"$ne": models.TransferStatusFinalized}
+	default:
+		return 0, fmt.Errorf("invalid transfer status for promotion: %s", status)
+	}
+
+	update := bson.M{"$set": bson.M{"status": status}}
+	result, err := r.transfersColl.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to set transfer status: %w", err)
+	}
+
+	nftResult, err := r.nftTransfersColl.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to set NFT transfer status: %w", err)
+	}
+
+	approvalResult, err := r.approvalsColl.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to set approval status: %w", err)
+	}
+
+	return result.ModifiedCount + nftResult.ModifiedCount + approvalResult.ModifiedCount, nil
+}
+
 func (r *MongoRepository) QueryTransfers(ctx context.Context, params models.TransferQueryParams) ([]*models.Transfer, int64, error) {
 	filter := r.buildFilter(params)
 
@@ -264,18 +637,38 @@ func (r *MongoRepository) QueryTransfers(ctx context.Context, params models.Tran
 	return transfers, count, nil
 }
 
+// buildFilter translates query params into a transfers filter
+// Not scoped to the repository's chain: params.ChainID selects the chain, nil searches all of them
 func (r *MongoRepository) buildFilter(params models.TransferQueryParams) bson.M {
 	filter := bson.M{}
 
+	if params.ChainID != nil {
+		filter["chain_id"] = *params.ChainID
+	}
 	if params.Token != "" {
 		filter["token"] = params.Token
 	}
+	if params.EventSignature != "" {
+		filter["event_signature"] = params.EventSignature
+	}
+	if params.Standard == models.StandardERC20 {
+		// Records written before ERC-1155 support have no standard field
+		filter["standard"] = erc20Standard
+	} else if params.Standard != "" {
+		filter["standard"] = params.Standard
+	}
+	if params.Kind != "" {
+		filter["kind"] = params.Kind
+	}
 	if params.From != "" {
 		filter["from"] = params.From
 	}
 	if params.To != "" {
 		filter["to"] = params.To
 	}
+	if params.Status != "" {
+		filter["status"] = params.Status
+	}
 	if params.StartBlock != nil {
 		filter["block_number"] = bson.M{"$gte": *params.StartBlock}
 	}
@@ -300,6 +693,9 @@ func (r *MongoRepository) buildFilter(params models.TransferQueryParams) bson.M
 	return filter
 }
 
+// GetAggregates computes transfer statistics for the matching transfers
+// Values are summed per chain and token and scaled by the token's decimals from token_metadata (18 if unknown);
+// native values always use 18 and ERC-1155 amounts are unit counts
 func (r *MongoRepository) GetAggregates(ctx context.Context, params models.TransferQueryParams) (*models.AggregateResponse, error) {
 	filter := r.buildFilter(params)
 
@@ -327,29 +723,85 @@ func (r *MongoRepository) GetAggregates(ctx context.Context, params models.Trans
 			},
 		},
 		{
-			"$group": bson.M{
-				"_id":             nil,
-				"total_transfers": bson.M{"$sum": 1},
-				"total_value":     bson.M{"$sum": "$value_numeric"},
-				"unique_tokens":   bson.M{"$addToSet": "$token"},
-				"from_addresses":  bson.M{"$addToSet": "$from"},
-				"to_addresses":    bson.M{"$addToSet": "$to"},
-				"min_time":        bson.M{"$min": "$timestamp"},
-				"max_time":        bson.M{"$max": "$timestamp"},
-			},
-		},
-		{
-			"$project": bson.M{
-				"total_transfers": 1,
-				"total_value":     1,
-				"unique_tokens":   bson.M{"$size": "$unique_tokens"},
-				"unique_addresses": bson.M{
-					"$size": bson.M{
-						"$setUnion": []interface{}{"$from_addresses", "$to_addresses"},
+			"$facet": bson.M{
+				"totals": []bson.M{
+					{
+						// One document per token keeps the metadata lookup cheap
+						"$group": bson.M{
+							"_id":         bson.M{"chain_id": "$chain_id", "token": "$token", "standard": "$standard"},
+							"count":       bson.M{"$sum": 1},
+							"total_value": bson.M{"$sum": "$value_numeric"},
+							"min_time":    bson.M{"$min": "$timestamp"},
+							"max_time":    bson.M{"$max": "$timestamp"},
+						},
+					},
+					{
+						"$lookup": bson.M{
+							"from":         r.tokenMetadataColl.Name(),
+							"localField":   "_id.token",
+							"foreignField": "address",
+							"let":          bson.M{"chain_id": "$_id.chain_id"},
+							"pipeline": []bson.M{
+								{"$match": bson.M{"$expr": bson.M{"$eq": []interface{}{"$chain_id", "$$chain_id"}}}},
+							},
+							"as": "metadata",
+						},
+					},
+					{
+						"$addFields": bson.M{
+							"decimals": bson.M{
+								"$switch": bson.M{
+									"branches": []bson.M{
+										{"case": bson.M{"$eq": []interface{}{"$_id.standard", models.StandardNative}}, "then": 18},
+										{"case": bson.M{"$eq": []interface{}{"$_id.standard", models.StandardERC1155}}, "then": 0},
+									},
+									"default": bson.M{"$ifNull": []interface{}{bson.M{"$arrayElemAt": []interface{}{"$metadata.decimals", 0}}, 18}},
+								},
+							},
+						},
+					},
+					{
+						"$group": bson.M{
+							"_id":             nil,
+							"total_transfers": bson.M{"$sum": "$count"},
+							"total_value":     bson.M{"$sum": "$total_value"},
+							"total_value_decimal": bson.M{
+								"$sum": bson.M{"$divide": []interface{}{"$total_value", bson.M{"$pow": []interface{}{10, "$decimals"}}}},
+							},
+							"unique_tokens": bson.M{"$addToSet": bson.M{"chain_id": "$_id.chain_id", "token": "$_id.token"}},
+							"min_time":      bson.M{"$min": "$min_time"},
+							"max_time":      bson.M{"$max": "$max_time"},
+						},
+					},
+					{
+						"$project": bson.M{
+							"total_transfers":     1,
+							"total_value":         1,
+							"total_value_decimal": 1,
+							"unique_tokens":       bson.M{"$size": "$unique_tokens"},
+							"min_time":            1,
+							"max_time":            1,
+						},
+					},
+				},
+				"addresses": []bson.M{
+					{
+						"$group": bson.M{
+							"_id":            nil,
+							"from_addresses": bson.M{"$addToSet": "$from"},
+							"to_addresses":   bson.M{"$addToSet": "$to"},
+						},
+					},
+					{
+						"$project": bson.M{
+							"unique_addresses": bson.M{
+								"$size": bson.M{
+									"$setUnion": []interface{}{"$from_addresses", "$to_addresses"},
+								},
+							},
+						},
 					},
 				},
-				"min_time": 1,
-				"max_time": 1,
 			},
 		},
 	}
@@ -361,12 +813,17 @@ func (r *MongoRepository) GetAggregates(ctx context.Context, params models.Trans
 	defer cursor.Close(ctx)
 
 	var result struct {
-		TotalTransfers  int64     `bson:"total_transfers"`
-		TotalValue      float64   `bson:"total_value"`
-		UniqueTokens    int64     `bson:"unique_tokens"`
-		UniqueAddresses int64     `bson:"unique_addresses"`
-		MinTime         time.Time `bson:"min_time"`
-		MaxTime         time.Time `bson:"max_time"`
+		Totals []struct {
+			TotalTransfers    int64     `bson:"total_transfers"`
+			TotalValue        float64   `bson:"total_value"`
+			TotalValueDecimal float64   `bson:"total_value_decimal"`
+			UniqueTokens      int64     `bson:"unique_tokens"`
+			MinTime           time.Time `bson:"min_time"`
+			MaxTime           time.Time `bson:"max_time"`
+		} `bson:"totals"`
+		Addresses []struct {
+			UniqueAddresses int64 `bson:"unique_addresses"`
+		} `bson:"addresses"`
 	}
 
 	if !cursor.Next(ctx) {
@@ -376,17 +833,23 @@ func (r *MongoRepository) GetAggregates(ctx context.Context, params models.Trans
 	if err := cursor.Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode aggregate result: %w", err)
 	}
+	if len(result.Totals) == 0 {
+		return &models.AggregateResponse{}, nil
+	}
 
+	totals := result.Totals[0]
 	response := &models.AggregateResponse{
-		TotalTransfers:    result.TotalTransfers,
-		TotalValue:        fmt.Sprintf("%.0f", result.TotalValue),
-		TotalValueDecimal: result.TotalValue / 1e18,
-		UniqueTokens:      result.UniqueTokens,
-		UniqueAddresses:   result.UniqueAddresses,
+		TotalTransfers:    totals.TotalTransfers,
+		TotalValue:        fmt.Sprintf("%.0f", totals.TotalValue),
+		TotalValueDecimal: totals.TotalValueDecimal,
+		UniqueTokens:      totals.UniqueTokens,
+	}
+	if len(result.Addresses) > 0 {
+		response.UniqueAddresses = result.Addresses[0].UniqueAddresses
 	}
 
-	response.TimeRange.Start = result.MinTime
-	response.TimeRange.End = result.MaxTime
+	response.TimeRange.Start = totals.MinTime
+	response.TimeRange.End = totals.MaxTime
 
 	return response, nil
 }
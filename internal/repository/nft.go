@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pagrin/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NFTRepository persists ERC-721 transfers and the derived current-owner table
+type NFTRepository interface {
+	InsertNFTTransfers(ctx context.Context, transfers []*models.NFTTransfer) error
+	GetNFTOwner(ctx context.Context, token, tokenID string) (*models.NFTOwner, error)
+	GetNFTsByOwner(ctx context.Context, params models.NFTQueryParams) ([]*models.NFTOwner, int64, error)
+}
+
+func (r *MongoRepository) createNFTIndexes(ctx context.Context) error {
+	// Superseded by the chain-scoped unique indexes below
+	if err := dropIndexIfExists(ctx, r.nftTransfersColl, "tx_hash_1_log_index_1"); err != nil {
+		return err
+	}
+	if err := dropIndexIfExists(ctx, r.nftOwnersColl, "token_1_token_id_1"); err != nil {
+		return err
+	}
+
+	transferIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "chain_id", Value: int32(1)},
+				{Key: "tx_hash", Value: int32(1)},
+				{Key: "log_index", Value: int32(1)},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "token", Value: int32(1)},
+				{Key: "token_id", Value: int32(1)},
+				{Key: "block_number", Value: int32(-1)},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "block_number", Value: int32(-1)},
+			},
+		},
+	}
+
+	if _, err := r.nftTransfersColl.Indexes().CreateMany(ctx, transferIndexes); err != nil {
+		return err
+	}
+
+	ownerIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "chain_id", Value: int32(1)},
+				{Key: "token", Value: int32(1)},
+				{Key: "token_id", Value: int32(1)},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "owner", Value: int32(1)},
+				{Key: "token", Value: int32(1)},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "block_number", Value: int32(-1)},
+			},
+		},
+	}
+
+	_, err := r.nftOwnersColl.Indexes().CreateMany(ctx, ownerIndexes)
+	return err
+}
+
+// InsertNFTTransfers stores ERC-721 transfers and advances the owner of each token
+// Ownership only moves to a transfer later in chain order than the one recorded,
+// so backfills and retries can write in any order
+func (r *MongoRepository) InsertNFTTransfers(ctx context.Context, transfers []*models.NFTTransfer) error {
+	if len(transfers) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, len(transfers))
+	for i, transfer := range transfers {
+		transfer.ChainID = r.chainID
+		// Duplicate keys abort a transaction, so inside one only missing transfers are upserted
+		if inTransaction(ctx) {
+			filter := r.scoped(bson.M{"tx_hash": transfer.TxHash, "log_index": transfer.LogIndex})
+			writes[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(bson.M{"$setOnInsert": transfer}).SetUpsert(true)
+			continue
+		}
+		writes[i] = mongo.NewInsertOneModel().SetDocument(transfer)
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+	if _, err := r.nftTransfersColl.BulkWrite(ctx, writes, opts); err != nil && !mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("failed to bulk write NFT transfers: %w", err)
+	}
+
+	return r.applyNFTOwnership(ctx, transfers)
+}
+
+// applyNFTOwnership upserts the owner row of every token touched by transfers
+func (r *MongoRepository) applyNFTOwnership(ctx context.Context, transfers []*models.NFTTransfer) error {
+	// Only the latest transfer per token matters
+	latest := make(map[[2]string]*models.NFTTransfer, len(transfers))
+	for _, transfer := range transfers {
+		key := [2]string{transfer.Token, transfer.TokenID}
+		if current, ok := latest[key]; !ok || nftTransferAfter(transfer, current) {
+			latest[key] = transfer
+		}
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(latest))
+	for _, transfer := range latest {
+		filter := r.scoped(bson.M{"token": transfer.Token, "token_id": transfer.TokenID})
+		update := advanceTo(transfer.BlockNumber, transfer.LogIndex, bson.M{
+			"owner":        transfer.To,
+			"block_number": transfer.BlockNumber,
+			"log_index":    transfer.LogIndex,
+			"tx_hash":      transfer.TxHash,
+			"updated_at":   time.Now(),
+		})
+		writes = append(writes, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	// A duplicate key means two writers raced to create the row; retry once so the loser updates it
+	opts := options.BulkWrite().SetOrdered(false)
+	for attempt := 0; attempt < 2; attempt++ {
+		_, err := r.nftOwnersColl.BulkWrite(ctx, writes, opts)
+		if err == nil {
+			return nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("failed to update NFT owners: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetNFTOwner returns the current owner row of a token, or nil if it has never been transferred
+func (r *MongoRepository) GetNFTOwner(ctx context.Context, token, tokenID string) (*models.NFTOwner, error) {
+	var owner models.NFTOwner
+	err := r.nftOwnersColl.FindOne(ctx, r.scoped(bson.M{"token": token, "token_id": tokenID})).Decode(&owner)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NFT owner: %w", err)
+	}
+	return &owner, nil
+}
+
+// GetNFTsByOwner returns the tokens currently held by an address
+func (r *MongoRepository) GetNFTsByOwner(ctx context.Context, params models.NFTQueryParams) ([]*models.NFTOwner, int64, error) {
+	filter := r.scoped(bson.M{"owner": params.Owner})
+	if params.Token != "" {
+		filter["token"] = params.Token
+	}
+
+	count, err := r.nftOwnersColl.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count NFTs: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{
+			{Key: "token", Value: 1},
+			{Key: "token_id", Value: 1},
+		}).
+		SetSkip(int64(params.Offset)).
+		SetLimit(int64(params.Limit))
+
+	cursor, err := r.nftOwnersColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query NFTs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var owners []*models.NFTOwner
+	if err := cursor.All(ctx, &owners); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode NFTs: %w", err)
+	}
+
+	return owners, count, nil
+}
+
+// rollbackNFTs removes NFT transfers above blockNumber and recomputes the owner of every affected token
+func (r *MongoRepository) rollbackNFTs(ctx context.Context, blockNumber uint64) error {
+	filter := r.scoped(bson.M{"block_number": bson.M{"$gt": blockNumber}})
+
+	cursor, err := r.nftOwnersColl.Find(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to find orphaned NFT owners: %w", err)
+	}
+	var affected []*models.NFTOwner
+	if err := cursor.All(ctx, &affected); err != nil {
+		return fmt.Errorf("failed to decode orphaned NFT owners: %w", err)
+	}
+
+	if _, err := r.nftTransfersColl.DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("failed to delete orphaned NFT transfers: %w", err)
+	}
+
+	for _, owner := range affected {
+		tokenFilter := r.scoped(bson.M{"token": owner.Token, "token_id": owner.TokenID})
+
+		var previous models.NFTTransfer
+		opts := options.FindOne().SetSort(bson.D{
+			{Key: "block_number", Value: -1},
+			{Key: "log_index", Value: -1},
+		})
+		err := r.nftTransfersColl.FindOne(ctx, tokenFilter, opts).Decode(&previous)
+		if err == mongo.ErrNoDocuments {
+			if _, err := r.nftOwnersColl.DeleteOne(ctx, tokenFilter); err != nil {
+				return fmt.Errorf("failed to delete NFT owner: %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find previous NFT transfer: %w", err)
+		}
+
+		update := bson.M{"$set": bson.M{
+			"owner":        previous.To,
+			"block_number": previous.BlockNumber,
+			"log_index":    previous.LogIndex,
+			"tx_hash":      previous.TxHash,
+			"updated_at":   time.Now(),
+		}}
+		if _, err := r.nftOwnersColl.UpdateOne(ctx, tokenFilter, update); err != nil {
+			return fmt.Errorf("failed to restore NFT owner: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// nftTransferAfter reports whether a comes after b in chain order
+func nftTransferAfter(a, b *models.NFTTransfer) bool {
+	if a.BlockNumber != b.BlockNumber {
+		return a.BlockNumber > b.BlockNumber
+	}
+	return a.LogIndex > b.LogIndex
+}
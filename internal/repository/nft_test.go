@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"testing"
+
+	"pagrin/internal/models"
+)
+
+func TestNFTTransferAfter(t *testing.T) {
+	at := func(block uint64, logIndex uint) *models.NFTTransfer {
+		return &models.NFTTransfer{BlockNumber: block, LogIndex: logIndex}
+	}
+
+	tests := []struct {
+		name string
+		a, b *models.NFTTransfer
+		want bool
+	}{
+		{name: "later block", a: at(101, 0), b: at(100, 9), want: true},
+		{name: "earlier block", a: at(100, 9), b: at(101, 0), want: false},
+		{name: "later log in the block", a: at(100, 4), b: at(100, 3), want: true},
+		{name: "earlier log in the block", a: at(100, 3), b: at(100, 4), want: false},
+		{name: "same log", a: at(100, 3), b: at(100, 3), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nftTransferAfter(tt.a, tt.b); got != tt.want {
+				t.Errorf("nftTransferAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
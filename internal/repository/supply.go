@@ -0,0 +1,502 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"pagrin/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SupplyRepository maintains per-block ERC-20 supply changes and each token's running circulating supply
+type SupplyRepository interface {
+	RecordSupplyChanges(ctx context.Context, transfers []*models.Transfer) error
+	GetTokenSupply(ctx context.Context, token string) (*models.TokenSupply, error)
+	GetSupplyHistory(ctx context.Context, params models.SupplyQueryParams) ([]*models.SupplyChange, int64, error)
+	ClassifyLegacyTransfers(ctx context.Context) (int64, error)
+	RebuildSupply(ctx context.Context) (int, error)
+}
+
+// erc20Standard matches ERC-20 transfers, including legacy records without a standard field
+var erc20Standard = bson.M{"$in": []interface{}{models.StandardERC20, nil}}
+
+// supplyFoldBatch bounds the running supply updates sent in one bulk write while folding
+const supplyFoldBatch = 1000
+
+func (r *MongoRepository) createSupplyIndexes(ctx context.Context) error {
+	// Superseded by the chain-scoped indexes below
+	if err := dropIndexIfExists(ctx, r.transfersColl, "token_1_kind_1_block_number_1"); err != nil {
+		return err
+	}
+	if err := dropIndexIfExists(ctx, r.supplyChangesColl, "token_1_block_number_-1"); err != nil {
+		return err
+	}
+
+	transferIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "chain_id", Value: int32(1)},
+			{Key: "token", Value: int32(1)},
+			{Key: "kind", Value: int32(1)},
+			{Key: "block_number", Value: int32(1)},
+		},
+	}
+	if _, err := r.transfersColl.Indexes().CreateOne(ctx, transferIndex); err != nil {
+		return err
+	}
+
+	changeIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "chain_id", Value: int32(1)},
+				{Key: "token", Value: int32(1)},
+				{Key: "block_number", Value: int32(-1)},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "block_number", Value: int32(-1)},
+			},
+		},
+	}
+
+	_, err := r.supplyChangesColl.Indexes().CreateMany(ctx, changeIndexes)
+	return err
+}
+
+// RecordSupplyChanges writes the net mint and burn of every (token, block) among transfers' ERC-20 mints and burns
+// Each touched block is summed from the stored transfers of that block only, so the write is bounded by the batch
+// and repeated or out-of-order batches (backfills) converge; running supplies are folded lazily by GetTokenSupply
+func (r *MongoRepository) RecordSupplyChanges(ctx context.Context, transfers []*models.Transfer) error {
+	touched := make(map[string]map[uint64]struct{})
+	for _, transfer := range transfers {
+		if transfer.Kind != models.TransferKindMint && transfer.Kind != models.TransferKindBurn {
+			continue
+		}
+		if transfer.Standard != models.StandardERC20 && transfer.Standard != "" {
+			continue
+		}
+		if touched[transfer.Token] == nil {
+			touched[transfer.Token] = make(map[uint64]struct{})
+		}
+		touched[transfer.Token][transfer.BlockNumber] = struct{}{}
+	}
+
+	for token, blockSet := range touched {
+		blocks := make([]uint64, 0, len(blockSet))
+		fromBlock := uint64(math.MaxUint64)
+		for block := range blockSet {
+			blocks = append(blocks, block)
+			fromBlock = min(fromBlock, block)
+		}
+
+		blockFilter := bson.M{"$in": blocks}
+		changes, err := r.sumSupplyChanges(ctx, token, blockFilter)
+		if err != nil {
+			return err
+		}
+		if err := r.writeSupplyChanges(ctx, token, blockFilter, changes); err != nil {
+			return err
+		}
+		if err := r.markSupplyStale(ctx, token, fromBlock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sumSupplyChanges sums a token's stored mints and burns per block, in block order
+// blockFilter restricts the blocks summed; nil sums every block
+func (r *MongoRepository) sumSupplyChanges(ctx context.Context, token string, blockFilter bson.M) ([]*models.SupplyChange, error) {
+	filter := r.scoped(bson.M{
+		"token":    token,
+		"kind":     bson.M{"$in": []string{models.TransferKindMint, models.TransferKindBurn}},
+		"standard": erc20Standard,
+	})
+	if blockFilter != nil {
+		filter["block_number"] = blockFilter
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "block_number", Value: 1}}).
+		SetProjection(bson.M{"kind": 1, "value": 1, "value_string": 1, "block_number": 1, "block_hash": 1, "timestamp": 1})
+
+	cursor, err := r.transfersColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mints and burns: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	changes := make([]*models.SupplyChange, 0)
+	var current *models.SupplyChange
+	var blockMinted, blockBurned *big.Int
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Minted = blockMinted.String()
+		current.Burned = blockBurned.String()
+		changes = append(changes, current)
+	}
+
+	for cursor.Next(ctx) {
+		var transfer models.Transfer
+		if err := cursor.Decode(&transfer); err != nil {
+			return nil, fmt.Errorf("failed to decode transfer: %w", err)
+		}
+
+		if current == nil || current.BlockNumber != transfer.BlockNumber {
+			flush()
+			current = &models.SupplyChange{
+				ChainID:     r.chainID,
+				Token:       token,
+				BlockNumber: transfer.BlockNumber,
+				BlockHash:   transfer.BlockHash,
+				Timestamp:   transfer.Timestamp,
+			}
+			blockMinted, blockBurned = new(big.Int), new(big.Int)
+		}
+
+		value, ok := transferValue(&transfer)
+		if !ok {
+			continue
+		}
+		if transfer.Kind == models.TransferKindMint {
+			blockMinted.Add(blockMinted, value)
+		} else {
+			blockBurned.Add(blockBurned, value)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan mints and burns: %w", err)
+	}
+	flush()
+
+	return changes, nil
+}
+
+// writeSupplyChanges upserts a token's summed changes and deletes the changes of blocks within blockFilter
+// that no longer have a mint or burn (reorg rollback); the running supply is left for the next fold
+func (r *MongoRepository) writeSupplyChanges(ctx context.Context, token string, blockFilter bson.M, changes []*models.SupplyChange) error {
+	now := time.Now()
+	writes := make([]mongo.WriteModel, 0, len(changes)+1)
+	blocks := make([]uint64, 0, len(changes))
+	for _, change := range changes {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(r.scoped(bson.M{"token": token, "block_number": change.BlockNumber})).
+			SetUpdate(bson.M{
+				"$set": bson.M{
+					"block_hash": change.BlockHash,
+					"minted":     change.Minted,
+					"burned":     change.Burned,
+					"timestamp":  change.Timestamp,
+					"updated_at": now,
+				},
+				"$setOnInsert": bson.M{"supply": "0"},
+			}).
+			SetUpsert(true))
+		blocks = append(blocks, change.BlockNumber)
+	}
+
+	orphaned := bson.M{"$nin": blocks}
+	for op, value := range blockFilter {
+		orphaned[op] = value
+	}
+	writes = append(writes, mongo.NewDeleteManyModel().SetFilter(r.scoped(bson.M{
+		"token":        token,
+		"block_number": orphaned,
+	})))
+
+	if _, err := r.supplyChangesColl.BulkWrite(ctx, writes); err != nil {
+		return fmt.Errorf("failed to write supply changes: %w", err)
+	}
+	return nil
+}
+
+// markSupplyStale records that a token's running supply must be refolded from fromBlock onwards
+// Version is bumped so a fold racing with this write does not clear the marker
+func (r *MongoRepository) markSupplyStale(ctx context.Context, token string, fromBlock uint64) error {
+	update := bson.M{
+		"$min":         bson.M{"dirty_from": fromBlock},
+		"$inc":         bson.M{"version": int64(1)},
+		"$setOnInsert": bson.M{"chain_id": r.chainID, "token": token, "supply": "0"},
+	}
+	_, err := r.tokenSuppliesColl.UpdateOne(ctx, bson.M{"_id": r.chainKey(token)}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to mark token supply stale: %w", err)
+	}
+	return nil
+}
+
+// foldSupply recomputes the running supply of a token's changes from its dirty block onwards
+// and stores its current supply, removing it when no mint or burn remains
+// If the token is marked stale again meanwhile, the marker is kept and the next read folds again
+func (r *MongoRepository) foldSupply(ctx context.Context, current *models.TokenSupply) (*models.TokenSupply, error) {
+	r.supplyMu.Lock()
+	defer r.supplyMu.Unlock()
+
+	fromBlock := *current.DirtyFrom
+	supply, err := r.supplyBefore(ctx, current.Token, fromBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := r.scoped(bson.M{"token": current.Token, "block_number": bson.M{"$gte": fromBlock}})
+	opts := options.Find().
+		SetSort(bson.D{{Key: "block_number", Value: 1}}).
+		SetProjection(bson.M{"block_number": 1, "minted": 1, "burned": 1, "supply": 1})
+
+	cursor, err := r.supplyChangesColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query supply changes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	writes := make([]mongo.WriteModel, 0)
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+		if _, err := r.supplyChangesColl.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("failed to write running supply: %w", err)
+		}
+		writes = writes[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var change models.SupplyChange
+		if err := cursor.Decode(&change); err != nil {
+			return nil, fmt.Errorf("failed to decode supply change: %w", err)
+		}
+		minted, ok := new(big.Int).SetString(change.Minted, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid minted amount %q stored for token %s at block %d", change.Minted, current.Token, change.BlockNumber)
+		}
+		burned, ok := new(big.Int).SetString(change.Burned, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid burned amount %q stored for token %s at block %d", change.Burned, current.Token, change.BlockNumber)
+		}
+		supply.Add(supply, minted).Sub(supply, burned)
+
+		if running := supply.String(); change.Supply != running {
+			writes = append(writes, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": change.ID}).
+				SetUpdate(bson.M{"$set": bson.M{"supply": running}}))
+		}
+		if len(writes) >= supplyFoldBatch {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan supply changes: %w", err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	unchanged := bson.M{"_id": current.ID, "version": current.Version}
+
+	var latest models.SupplyChange
+	latestOpts := options.FindOne().SetSort(bson.D{{Key: "block_number", Value: -1}})
+	err = r.supplyChangesColl.FindOne(ctx, r.scoped(bson.M{"token": current.Token}), latestOpts).Decode(&latest)
+	if err == mongo.ErrNoDocuments {
+		if _, err := r.tokenSuppliesColl.DeleteOne(ctx, unchanged); err != nil {
+			return nil, fmt.Errorf("failed to delete token supply: %w", err)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest supply change: %w", err)
+	}
+
+	folded := *current
+	folded.Supply = supply.String()
+	folded.LastBlock = latest.BlockNumber
+	folded.UpdatedAt = time.Now()
+	folded.DirtyFrom = nil
+	update := bson.M{
+		"$set":   bson.M{"supply": folded.Supply, "last_block": folded.LastBlock, "updated_at": folded.UpdatedAt},
+		"$unset": bson.M{"dirty_from": ""},
+	}
+	if _, err := r.tokenSuppliesColl.UpdateOne(ctx, unchanged, update); err != nil {
+		return nil, fmt.Errorf("failed to save token supply: %w", err)
+	}
+	return &folded, nil
+}
+
+// supplyBefore returns the running supply of a token after its last change below fromBlock
+func (r *MongoRepository) supplyBefore(ctx context.Context, token string, fromBlock uint64) (*big.Int, error) {
+	filter := r.scoped(bson.M{"token": token, "block_number": bson.M{"$lt": fromBlock}})
+	opts := options.FindOne().SetSort(bson.D{{Key: "block_number", Value: -1}})
+
+	var previous models.SupplyChange
+	err := r.supplyChangesColl.FindOne(ctx, filter, opts).Decode(&previous)
+	if err == mongo.ErrNoDocuments {
+		return new(big.Int), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous supply change: %w", err)
+	}
+
+	supply, ok := new(big.Int).SetString(previous.Supply, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid supply %q stored for token %s at block %d", previous.Supply, token, previous.BlockNumber)
+	}
+	return supply, nil
+}
+
+// GetTokenSupply returns a token's current supply, or nil if no mint or burn has been indexed
+// Supplies marked stale by new or rolled back changes are folded first
+func (r *MongoRepository) GetTokenSupply(ctx context.Context, token string) (*models.TokenSupply, error) {
+	var supply models.TokenSupply
+	err := r.tokenSuppliesColl.FindOne(ctx, bson.M{"_id": r.chainKey(token)}).Decode(&supply)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token supply: %w", err)
+	}
+	if supply.DirtyFrom != nil {
+		return r.foldSupply(ctx, &supply)
+	}
+	return &supply, nil
+}
+
+// GetSupplyHistory returns a page of a token's supply changes, newest first
+func (r *MongoRepository) GetSupplyHistory(ctx context.Context, params models.SupplyQueryParams) ([]*models.SupplyChange, int64, error) {
+	filter := r.scoped(bson.M{"token": params.Token})
+	if params.StartBlock != nil || params.EndBlock != nil {
+		blockRange := bson.M{}
+		if params.StartBlock != nil {
+			blockRange["$gte"] = *params.StartBlock
+		}
+		if params.EndBlock != nil {
+			blockRange["$lte"] = *params.EndBlock
+		}
+		filter["block_number"] = blockRange
+	}
+
+	count, err := r.supplyChangesColl.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count supply changes: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "block_number", Value: -1}}).
+		SetSkip(int64(params.Offset)).
+		SetLimit(int64(params.Limit))
+
+	cursor, err := r.supplyChangesColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query supply changes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var changes []*models.SupplyChange
+	if err := cursor.All(ctx, &changes); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode supply changes: %w", err)
+	}
+
+	return changes, count, nil
+}
+
+// ClassifyLegacyTransfers sets kind on transfers stored before mint/burn classification
+// Returns the number of transfers classified
+func (r *MongoRepository) ClassifyLegacyTransfers(ctx context.Context) (int64, error) {
+	unclassified := bson.M{"$exists": false}
+	steps := []struct {
+		filter bson.M
+		kind   string
+	}{
+		{r.scoped(bson.M{"kind": unclassified, "from": models.ZeroAddress, "to": models.ZeroAddress}), models.TransferKindTransfer},
+		{r.scoped(bson.M{"kind": unclassified, "from": models.ZeroAddress}), models.TransferKindMint},
+		{r.scoped(bson.M{"kind": unclassified, "to": models.ZeroAddress}), models.TransferKindBurn},
+		{r.scoped(bson.M{"kind": unclassified}), models.TransferKindTransfer},
+	}
+
+	var classified int64
+	for _, step := range steps {
+		result, err := r.transfersColl.UpdateMany(ctx, step.filter, bson.M{"$set": bson.M{"kind": step.kind}})
+		if err != nil {
+			return classified, fmt.Errorf("failed to classify legacy transfers: %w", err)
+		}
+		classified += result.ModifiedCount
+	}
+	return classified, nil
+}
+
+// RebuildSupply recomputes the supply changes and current supply of every token with an indexed ERC-20 mint or burn
+// Returns the number of tokens rebuilt
+func (r *MongoRepository) RebuildSupply(ctx context.Context) (int, error) {
+	filter := r.scoped(bson.M{
+		"kind":     bson.M{"$in": []string{models.TransferKindMint, models.TransferKindBurn}},
+		"standard": erc20Standard,
+	})
+	tokens, err := r.transfersColl.Distinct(ctx, "token", filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list minted and burned tokens: %w", err)
+	}
+
+	for _, token := range tokens {
+		address, ok := token.(string)
+		if !ok {
+			continue
+		}
+		changes, err := r.sumSupplyChanges(ctx, address, nil)
+		if err != nil {
+			return 0, err
+		}
+		if err := r.writeSupplyChanges(ctx, address, nil, changes); err != nil {
+			return 0, err
+		}
+		if err := r.markSupplyStale(ctx, address, 0); err != nil {
+			return 0, err
+		}
+		if _, err := r.GetTokenSupply(ctx, address); err != nil {
+			return 0, err
+		}
+	}
+	return len(tokens), nil
+}
+
+// rollbackSupply deletes supply changes above blockNumber after a reorg
+// and marks the affected tokens for refolding from the block after it
+func (r *MongoRepository) rollbackSupply(ctx context.Context, blockNumber uint64) error {
+	orphaned := r.scoped(bson.M{"block_number": bson.M{"$gt": blockNumber}})
+	tokens, err := r.supplyChangesColl.Distinct(ctx, "token", orphaned)
+	if err != nil {
+		return fmt.Errorf("failed to find orphaned supply changes: %w", err)
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	if _, err := r.supplyChangesColl.DeleteMany(ctx, orphaned); err != nil {
+		return fmt.Errorf("failed to delete orphaned supply changes: %w", err)
+	}
+	for _, token := range tokens {
+		if address, ok := token.(string); ok {
+			if err := r.markSupplyStale(ctx, address, blockNumber+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// transferValue returns the raw amount of a transfer, preferring the exact string form
+func transferValue(transfer *models.Transfer) (*big.Int, bool) {
+	raw := transfer.ValueString
+	if raw == "" {
+		raw = transfer.Value.String()
+	}
+	return new(big.Int).SetString(raw, 10)
+}
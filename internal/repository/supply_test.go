@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"pagrin/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestTransferValue(t *testing.T) {
+	decimal, _ := primitive.ParseDecimal128("1500")
+	tests := []struct {
+		name     string
+		transfer models.Transfer
+		want     string
+		wantOK   bool
+	}{
+		{name: "exact string", transfer: models.Transfer{ValueString: "340282366920938463463374607431768211456", Value: decimal}, want: "340282366920938463463374607431768211456", wantOK: true},
+		{name: "legacy decimal", transfer: models.Transfer{Value: decimal}, want: "1500", wantOK: true},
+		{name: "corrupt", transfer: models.Transfer{ValueString: "1e18"}, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := transferValue(&tt.transfer)
+			if ok != tt.wantOK {
+				t.Fatalf("transferValue() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && value.String() != tt.want {
+				t.Errorf("transferValue() = %s, want %s", value, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordSupplyChanges(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("sums touched blocks and marks the supply stale", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		transfers := []*models.Transfer{
+			{Token: "0xt", Kind: models.TransferKindMint, Standard: models.StandardERC20, BlockNumber: 12},
+			{Token: "0xt", Kind: models.TransferKindBurn, BlockNumber: 10}, // Legacy record without a standard
+			{Token: "0xt", Kind: models.TransferKindTransfer, Standard: models.StandardERC20, BlockNumber: 5},
+			{Token: "0xt", Kind: models.TransferKindMint, Standard: models.StandardERC1155, BlockNumber: 3},
+		}
+		mt.AddMockResponses(
+			// Stored mints and burns of blocks 10 and 12, including ones from earlier batches
+			mockCursor(mt,
+				bson.D{{Key: "kind", Value: "mint"}, {Key: "value_string", Value: "100"}, {Key: "block_number", Value: int64(10)}},
+				bson.D{{Key: "kind", Value: "burn"}, {Key: "value_string", Value: "30"}, {Key: "block_number", Value: int64(10)}},
+				bson.D{{Key: "kind", Value: "mint"}, {Key: "value_string", Value: "7"}, {Key: "block_number", Value: int64(12)}},
+				bson.D{{Key: "kind", Value: "mint"}, {Key: "value_string", Value: "8"}, {Key: "block_number", Value: int64(12)}},
+			),
+			mockWrite(2),
+			mockWrite(0),
+			mockWrite(1),
+		)
+
+		if err := r.RecordSupplyChanges(context.Background(), transfers); err != nil {
+			mt.Fatalf("RecordSupplyChanges() error = %v", err)
+		}
+
+		names, bodies := sentCommands(mt)
+		if fmt.Sprint(names) != "[find update delete update]" {
+			mt.Fatalf("commands = %v, want find, update, delete, update", names)
+		}
+		values, _ := bodies[0].Lookup("filter", "block_number", "$in").Array().Values()
+		summed := make(map[int64]bool)
+		for _, value := range values {
+			summed[value.Int64()] = true
+		}
+		if len(summed) != 2 || !summed[10] || !summed[12] {
+			mt.Errorf("summed blocks = %v, want 10 and 12", summed)
+		}
+		for i, want := range [][2]string{{"100", "30"}, {"15", "0"}} {
+			set := bodies[1].Lookup("updates", fmt.Sprint(i), "u", "$set")
+			if minted, burned := set.Document().Lookup("minted").StringValue(), set.Document().Lookup("burned").StringValue(); minted != want[0] || burned != want[1] {
+				mt.Errorf("change %d = +%s -%s, want +%s -%s", i, minted, burned, want[0], want[1])
+			}
+		}
+		if _, err := bodies[2].LookupErr("deletes", "0", "q", "block_number", "$nin"); err != nil {
+			mt.Errorf("orphaned changes of the touched blocks are not deleted: %v", err)
+		}
+		if dirty := bodies[3].Lookup("updates", "0", "u", "$min", "dirty_from").Int64(); dirty != 10 {
+			mt.Errorf("dirty_from = %d, want 10", dirty)
+		}
+	})
+
+	mt.Run("ignores batches without mints or burns", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		transfers := []*models.Transfer{{Token: "0xt", Kind: models.TransferKindTransfer, BlockNumber: 5}}
+		if err := r.RecordSupplyChanges(context.Background(), transfers); err != nil {
+			mt.Fatalf("RecordSupplyChanges() error = %v", err)
+		}
+		if names, _ := sentCommands(mt); len(names) != 0 {
+			mt.Errorf("commands = %v, want none", names)
+		}
+	})
+}
+
+func TestFoldSupply(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	change := func(id primitive.ObjectID, block int64, minted, burned, supply string) bson.D {
+		return bson.D{{Key: "_id", Value: id}, {Key: "block_number", Value: block}, {Key: "minted", Value: minted}, {Key: "burned", Value: burned}, {Key: "supply", Value: supply}}
+	}
+	stale := func() *models.TokenSupply {
+		dirty := uint64(101)
+		return &models.TokenSupply{ID: "1:0xt", Token: "0xt", DirtyFrom: &dirty, Version: 7}
+	}
+
+	mt.Run("refolds from the dirty block", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		stale101 := primitive.NewObjectID()
+		mt.AddMockResponses(
+			mockCursor(mt, bson.D{{Key: "block_number", Value: int64(100)}, {Key: "supply", Value: "1000"}}),
+			mockCursor(mt,
+				change(stale101, 101, "500", "0", "0"),
+				change(primitive.NewObjectID(), 105, "0", "200", "1300"), // Already correct
+			),
+			mockWrite(1),
+			mockCursor(mt, bson.D{{Key: "block_number", Value: int64(105)}}),
+			mockWrite(1),
+		)
+
+		folded, err := r.foldSupply(context.Background(), stale())
+		if err != nil {
+			mt.Fatalf("foldSupply() error = %v", err)
+		}
+		if folded.Supply != "1300" || folded.LastBlock != 105 || folded.DirtyFrom != nil {
+			mt.Errorf("folded = %s at %d (dirty %v), want 1300 at 105", folded.Supply, folded.LastBlock, folded.DirtyFrom)
+		}
+
+		names, bodies := sentCommands(mt)
+		if fmt.Sprint(names) != "[find find update find update]" {
+			mt.Fatalf("commands = %v", names)
+		}
+		if from := bodies[0].Lookup("filter", "block_number", "$lt").Int64(); from != 101 {
+			mt.Errorf("previous supply read below block %d, want 101", from)
+		}
+		updates := bodies[2].Lookup("updates").Array()
+		if values, _ := updates.Values(); len(values) != 1 {
+			mt.Fatalf("running supply updates = %d, want only the stale block", len(values))
+		}
+		if id := updates.Index(0).Value().Document().Lookup("q", "_id").ObjectID(); id != stale101 {
+			mt.Errorf("updated change %s, want block 101", id.Hex())
+		}
+		if running := updates.Index(0).Value().Document().Lookup("u", "$set", "supply").StringValue(); running != "1500" {
+			mt.Errorf("running supply at block 101 = %s, want 1500", running)
+		}
+		// The fold only lands if no change was recorded meanwhile
+		if version := bodies[4].Lookup("updates", "0", "q", "version").Int64(); version != 7 {
+			mt.Errorf("supply update guarded by version %d, want 7", version)
+		}
+		if _, err := bodies[4].LookupErr("updates", "0", "u", "$unset", "dirty_from"); err != nil {
+			mt.Errorf("dirty marker not cleared: %v", err)
+		}
+	})
+
+	mt.Run("deletes the supply once every change is rolled back", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		mt.AddMockResponses(mockCursor(mt), mockCursor(mt), mockCursor(mt), mockWrite(1))
+
+		folded, err := r.foldSupply(context.Background(), stale())
+		if err != nil {
+			mt.Fatalf("foldSupply() error = %v", err)
+		}
+		if folded != nil {
+			mt.Errorf("folded = %+v, want nil", folded)
+		}
+		names, bodies := sentCommands(mt)
+		if fmt.Sprint(names) != "[find find find delete]" {
+			mt.Fatalf("commands = %v", names)
+		}
+		if version := bodies[3].Lookup("deletes", "0", "q", "version").Int64(); version != 7 {
+			mt.Errorf("supply delete guarded by version %d, want 7", version)
+		}
+	})
+
+	mt.Run("rejects corrupt amounts", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		mt.AddMockResponses(mockCursor(mt), mockCursor(mt, change(primitive.NewObjectID(), 101, "lots", "0", "0")))
+
+		if _, err := r.foldSupply(context.Background(), stale()); err == nil {
+			mt.Errorf("foldSupply() succeeded with a corrupt minted amount")
+		}
+	})
+}
+
+func TestRollbackSupply(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("marks orphaned tokens stale after the fork point", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{"0xa", "0xb"}}),
+			mockWrite(3),
+			mockWrite(1),
+			mockWrite(1),
+		)
+
+		if err := r.rollbackSupply(context.Background(), 200); err != nil {
+			mt.Fatalf("rollbackSupply() error = %v", err)
+		}
+		names, bodies := sentCommands(mt)
+		if fmt.Sprint(names) != "[distinct delete update update]" {
+			mt.Fatalf("commands = %v", names)
+		}
+		if above := bodies[1].Lookup("deletes", "0", "q", "block_number", "$gt").Int64(); above != 200 {
+			mt.Errorf("deleted changes above block %d, want 200", above)
+		}
+		for i, token := range []string{"0xa", "0xb"} {
+			body := bodies[2+i]
+			if id := body.Lookup("updates", "0", "q", "_id").StringValue(); id != "1:"+token {
+				mt.Errorf("marked %s, want 1:%s", id, token)
+			}
+			if dirty := body.Lookup("updates", "0", "u", "$min", "dirty_from").Int64(); dirty != 201 {
+				mt.Errorf("dirty_from = %d, want 201", dirty)
+			}
+		}
+	})
+
+	mt.Run("leaves supplies alone without orphaned changes", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{}}))
+
+		if err := r.rollbackSupply(context.Background(), 200); err != nil {
+			mt.Fatalf("rollbackSupply() error = %v", err)
+		}
+		if names, _ := sentCommands(mt); fmt.Sprint(names) != "[distinct]" {
+			mt.Errorf("commands = %v, want only the distinct lookup", names)
+		}
+	})
+}
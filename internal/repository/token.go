@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pagrin/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TokenMetadataRepository persists resolved token metadata
+type TokenMetadataRepository interface {
+	GetTokenMetadata(ctx context.Context, tokens []string) ([]*models.TokenMetadata, error)
+	SaveTokenMetadata(ctx context.Context, metadata *models.TokenMetadata) error
+	RescaleTransferValues(ctx context.Context, token string, decimals uint8) (int64, error)
+}
+
+// createTokenMetadataIndexes backs the per-chain metadata lookup of transfer aggregates
+func (r *MongoRepository) createTokenMetadataIndexes(ctx context.Context) error {
+	index := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "address", Value: int32(1)},
+			{Key: "chain_id", Value: int32(1)},
+		},
+	}
+
+	_, err := r.tokenMetadataColl.Indexes().CreateOne(ctx, index)
+	return err
+}
+
+// GetTokenMetadata returns the stored metadata of the given lowercase token addresses
+// Tokens without stored metadata are omitted
+func (r *MongoRepository) GetTokenMetadata(ctx context.Context, tokens []string) ([]*models.TokenMetadata, error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(tokens))
+	for i, token := range tokens {
+		ids[i] = r.chainKey(token)
+	}
+
+	cursor, err := r.tokenMetadataColl.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query token metadata: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var metadata []*models.TokenMetadata
+	if err := cursor.All(ctx, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode token metadata: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// SaveTokenMetadata inserts or replaces the metadata of a token
+func (r *MongoRepository) SaveTokenMetadata(ctx context.Context, metadata *models.TokenMetadata) error {
+	metadata.ID = r.chainKey(metadata.Address)
+	metadata.ChainID = r.chainID
+	metadata.UpdatedAt = time.Now()
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := r.tokenMetadataColl.ReplaceOne(ctx, bson.M{"_id": metadata.ID}, metadata, opts); err != nil {
+		return fmt.Errorf("failed to save token metadata: %w", err)
+	}
+	return nil
+}
+
+// RescaleTransferValues recomputes value_decimal of a token's ERC-20 transfers with the given decimals
+// Repairs records written while every token was assumed to have 18 decimals
+func (r *MongoRepository) RescaleTransferValues(ctx context.Context, token string, decimals uint8) (int64, error) {
+	filter := r.scoped(bson.M{
+		"token":    token,
+		"standard": erc20Standard,
+	})
+	update := []bson.M{
+		{
+			"$set": bson.M{
+				"value_decimal": bson.M{
+					"$divide": []interface{}{
+						bson.M{"$toDouble": bson.M{"$ifNull": []interface{}{"$value", "$value_string"}}},
+						bson.M{"$pow": []interface{}{10, int32(decimals)}},
+					},
+				},
+			},
+		},
+	}
+
+	result, err := r.transfersColl.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rescale transfer values: %w", err)
+	}
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"pagrin/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// legacyTokenFilterStateID is the _id of the token filter state saved before multi-chain support
+const legacyTokenFilterStateID = "current"
+
+// TokenFilterRepository persists the token allow/deny lists the indexer last ran with
+type TokenFilterRepository interface {
+	GetTokenFilterState(ctx context.Context) (*models.TokenFilterState, error)
+	SaveTokenFilterState(ctx context.Context, state *models.TokenFilterState) error
+}
+
+// tokenFilterStateID is the _id of this chain's token filter state document
+func (r *MongoRepository) tokenFilterStateID() string {
+	return strconv.FormatUint(r.chainID, 10)
+}
+
+// GetTokenFilterState returns the stored token filter state, or nil if none has been saved yet
+func (r *MongoRepository) GetTokenFilterState(ctx context.Context) (*models.TokenFilterState, error) {
+	var state models.TokenFilterState
+	err := r.tokenFiltersColl.FindOne(ctx, bson.M{"_id": r.tokenFilterStateID()}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token filter state: %w", err)
+	}
+	return &state, nil
+}
+
+// SaveTokenFilterState replaces the stored token filter state
+func (r *MongoRepository) SaveTokenFilterState(ctx context.Context, state *models.TokenFilterState) error {
+	state.ID = r.tokenFilterStateID()
+	state.ChainID = r.chainID
+	state.UpdatedAt = time.Now()
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := r.tokenFiltersColl.ReplaceOne(ctx, bson.M{"_id": state.ID}, state, opts); err != nil {
+		return fmt.Errorf("failed to save token filter state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// txKey marks a context running inside a multi-document transaction
+type txKey struct{}
+
+// txState collects work deferred until the enclosing transaction commits
+type txState struct {
+	afterCommit []func(ctx context.Context)
+}
+
+// supportsTransactions reports whether the deployment is a replica set or sharded cluster
+// Standalone servers reject multi-document transactions
+func supportsTransactions(ctx context.Context, client *mongo.Client) bool {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
+
+// SupportsTransactions reports whether writes passed to WithTransaction commit atomically
+func (r *MongoRepository) SupportsTransactions() bool {
+	return r.transactions
+}
+
+// WithTransaction runs fn in a multi-document transaction and commits it
+// Repository calls made with the context passed to fn join the transaction; the Redis checkpoint
+// cache is only updated once it commits. fn may run more than once on transient errors
+// On a standalone server fn runs without a transaction: its writes are applied one by one and
+// a failure leaves the earlier ones in place, which is safe as long as they are idempotent
+func (r *MongoRepository) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !r.transactions {
+		return fn(ctx)
+	}
+
+	session, err := r.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start MongoDB session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	var state *txState
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// Fresh per attempt so hooks of an aborted attempt never run
+		state = &txState{}
+		return nil, fn(context.WithValue(sessCtx, txKey{}, state))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range state.afterCommit {
+		hook(ctx)
+	}
+	return nil
+}
+
+// inTransaction reports whether ctx belongs to a transaction started by WithTransaction
+func inTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
+
+// afterCommit runs hook once the transaction of ctx commits, or immediately outside a transaction
+func afterCommit(ctx context.Context, hook func(ctx context.Context)) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, hook)
+		return
+	}
+	hook(ctx)
+}
+
+// advanceTo returns a pipeline update that sets fields only when the event at (blockNumber, logIndex)
+// comes after the one the row holds in chain order, creating the row if it is missing
+// Unlike a filter on the stored position, it never turns a stale upsert into a duplicate key,
+// which would abort an enclosing transaction
+func advanceTo(blockNumber uint64, logIndex uint, fields bson.M) mongo.Pipeline {
+	later := bson.M{"$or": bson.A{
+		bson.M{"$lt": bson.A{"$block_number", blockNumber}},
+		bson.M{"$and": bson.A{
+			bson.M{"$eq": bson.A{"$block_number", blockNumber}},
+			bson.M{"$lt": bson.A{"$log_index", logIndex}},
+		}},
+	}}
+
+	set := bson.D{}
+	for field, value := range fields {
+		set = append(set, bson.E{Key: field, Value: bson.M{"$cond": bson.A{later, bson.M{"$literal": value}, "$" + field}}})
+	}
+	return mongo.Pipeline{{{Key: "$set", Value: set}}}
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"pagrin/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// checkpointCache records the checkpoints written to the Redis tier
+type checkpointCache struct {
+	mu     sync.Mutex
+	blocks []uint64
+}
+
+func (c *checkpointCache) GetLastProcessedBlock(ctx context.Context) (uint64, error) {
+	return 0, errors.New("not cached")
+}
+
+func (c *checkpointCache) SetLastProcessedBlock(ctx context.Context, blockNumber uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blocks = append(c.blocks, blockNumber)
+	return nil
+}
+
+func (c *checkpointCache) cached() []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]uint64(nil), c.blocks...)
+}
+
+func TestAfterCommit(t *testing.T) {
+	var ran []string
+	afterCommit(context.Background(), func(context.Context) { ran = append(ran, "outside") })
+	if fmt.Sprint(ran) != "[outside]" {
+		t.Errorf("hook outside a transaction ran %v, want immediately", ran)
+	}
+
+	state := &txState{}
+	ctx := context.WithValue(context.Background(), txKey{}, state)
+	if !inTransaction(ctx) || inTransaction(context.Background()) {
+		t.Errorf("inTransaction() does not follow the transaction context")
+	}
+	afterCommit(ctx, func(context.Context) { ran = append(ran, "first") })
+	afterCommit(ctx, func(context.Context) { ran = append(ran, "second") })
+	if len(ran) != 1 || len(state.afterCommit) != 2 {
+		t.Fatalf("hooks inside a transaction ran %v with %d deferred, want 2 deferred", ran, len(state.afterCommit))
+	}
+	for _, hook := range state.afterCommit {
+		hook(ctx)
+	}
+	if fmt.Sprint(ran) != "[outside first second]" {
+		t.Errorf("deferred hooks ran %v, want in registration order", ran)
+	}
+}
+
+func TestWithTransaction(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("standalone writes without a transaction", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		cache := &checkpointCache{}
+		r.cache = cache
+		mt.AddMockResponses(mockWrite(1))
+
+		err := r.WithTransaction(context.Background(), func(ctx context.Context) error {
+			if inTransaction(ctx) {
+				mt.Errorf("fn runs in a transaction on a standalone server")
+			}
+			return r.SetLastProcessedBlock(ctx, 42, "0xh", "0xp")
+		})
+		if err != nil {
+			mt.Fatalf("WithTransaction() error = %v", err)
+		}
+		if fmt.Sprint(cache.cached()) != "[42]" {
+			mt.Errorf("cached checkpoints = %v, want [42]", cache.cached())
+		}
+		_, bodies := sentCommands(mt)
+		if _, err := bodies[0].LookupErr("startTransaction"); err == nil {
+			mt.Errorf("update starts a transaction")
+		}
+	})
+
+	mt.Run("caches the checkpoint after commit", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		r.client, r.transactions = mt.Client, true
+		cache := &checkpointCache{}
+		r.cache = cache
+		mt.AddMockResponses(mockWrite(1), mtest.CreateSuccessResponse())
+
+		err := r.WithTransaction(context.Background(), func(ctx context.Context) error {
+			if err := r.SetLastProcessedBlock(ctx, 42, "0xh", "0xp"); err != nil {
+				return err
+			}
+			if cached := cache.cached(); len(cached) != 0 {
+				mt.Errorf("checkpoint cached before commit: %v", cached)
+			}
+			return nil
+		})
+		if err != nil {
+			mt.Fatalf("WithTransaction() error = %v", err)
+		}
+		if fmt.Sprint(cache.cached()) != "[42]" {
+			mt.Errorf("cached checkpoints = %v, want [42]", cache.cached())
+		}
+
+		names, bodies := sentCommands(mt)
+		if fmt.Sprint(names) != "[update commitTransaction]" {
+			mt.Fatalf("commands = %v, want update, commitTransaction", names)
+		}
+		if start, err := bodies[0].LookupErr("startTransaction"); err != nil || !start.Boolean() {
+			mt.Errorf("update does not start the transaction")
+		}
+	})
+
+	mt.Run("aborted transaction skips the cache", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		r.client, r.transactions = mt.Client, true
+		cache := &checkpointCache{}
+		r.cache = cache
+		mt.AddMockResponses(mockWrite(1), mtest.CreateSuccessResponse())
+
+		failed := errors.New("insert failed")
+		err := r.WithTransaction(context.Background(), func(ctx context.Context) error {
+			if err := r.SetLastProcessedBlock(ctx, 42, "0xh", "0xp"); err != nil {
+				return err
+			}
+			return failed
+		})
+		if !errors.Is(err, failed) {
+			mt.Fatalf("WithTransaction() error = %v, want %v", err, failed)
+		}
+		if cached := cache.cached(); len(cached) != 0 {
+			mt.Errorf("cached checkpoints = %v, want none after abort", cached)
+		}
+		if names, _ := sentCommands(mt); fmt.Sprint(names) != "[update abortTransaction]" {
+			mt.Errorf("commands = %v, want update, abortTransaction", names)
+		}
+	})
+}
+
+func TestInsertTransfersInTransaction(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("upserts missing transfers", func(mt *mtest.T) {
+		r := newMockRepository(mt)
+		transfers := []*models.Transfer{
+			{TxHash: "0xa", LogIndex: 1, EventSignature: "Transfer"},
+			{TxHash: "0xb", LogIndex: 2, EventSignature: "TransferBatch", BatchIndex: 3},
+		}
+		mt.AddMockResponses(mockWrite(2))
+
+		ctx := context.WithValue(context.Background(), txKey{}, &txState{})
+		if err := r.InsertTransfers(ctx, transfers); err != nil {
+			mt.Fatalf("InsertTransfers() error = %v", err)
+		}
+
+		names, bodies := sentCommands(mt)
+		if fmt.Sprint(names) != "[update]" {
+			mt.Fatalf("commands = %v, want update, not insert", names)
+		}
+		first := bodies[0].Lookup("updates", "0").Document()
+		if upsert := first.Lookup("upsert").Boolean(); !upsert {
+			mt.Errorf("update is not an upsert")
+		}
+		if _, err := first.LookupErr("u", "$setOnInsert"); err != nil {
+			mt.Errorf("existing transfers are overwritten, want $setOnInsert: %v", err)
+		}
+		// batch_index is omitted from single transfers, so it must be absent rather than zero
+		if exists := first.Lookup("q", "batch_index", "$exists").Boolean(); exists {
+			mt.Errorf("batch_index filter = $exists true, want false")
+		}
+		if index := bodies[0].Lookup("updates", "1", "q", "batch_index").AsInt64(); index != 3 {
+			mt.Errorf("batch_index filter = %d, want 3", index)
+		}
+	})
+}
+
+func TestAdvanceTo(t *testing.T) {
+	pipeline := advanceTo(100, 7, bson.M{"owner": "0xbob"})
+	if len(pipeline) != 1 || pipeline[0][0].Key != "$set" {
+		t.Fatalf("advanceTo() = %v, want a single $set stage", pipeline)
+	}
+
+	set := pipeline[0][0].Value.(bson.D)
+	if len(set) != 1 || set[0].Key != "owner" {
+		t.Fatalf("advanceTo() sets %v, want owner", set)
+	}
+	cond := set[0].Value.(bson.M)["$cond"].(bson.A)
+	// The new value is a literal, so values starting with $ are never read as field paths
+	if then := cond[1].(bson.M)["$literal"]; then != "0xbob" {
+		t.Errorf("advanceTo() then = %v, want the literal value", then)
+	}
+	if otherwise := cond[2]; otherwise != "$owner" {
+		t.Errorf("advanceTo() else = %v, want the stored field", otherwise)
+	}
+
+	later := cond[0].(bson.M)["$or"].(bson.A)
+	if fmt.Sprint(later[0]) != "map[$lt:[$block_number 100]]" {
+		t.Errorf("advanceTo() earlier block check = %v", later[0])
+	}
+	if fmt.Sprint(later[1]) != "map[$and:[map[$eq:[$block_number 100]] map[$lt:[$log_index 7]]]]" {
+		t.Errorf("advanceTo() same block check = %v", later[1])
+	}
+}
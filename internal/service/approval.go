@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+)
+
+// ApprovalService answers allowance queries and flags unlimited approvals to risky spenders
+type ApprovalService struct {
+	approvalRepo repository.ApprovalRepository
+	events       *EventStore // Source of the risky spender list
+	metadata     *TokenMetadataService
+	logger       *logger.Logger
+}
+
+func NewApprovalService(approvalRepo repository.ApprovalRepository, events *EventStore, metadata *TokenMetadataService, logger *logger.Logger) *ApprovalService {
+	return &ApprovalService{
+		approvalRepo: approvalRepo,
+		events:       events,
+		metadata:     metadata,
+		logger:       logger,
+	}
+}
+
+// ListOpenApprovals returns the non-zero allowances an address has granted
+func (s *ApprovalService) ListOpenApprovals(ctx context.Context, params models.AllowanceQueryParams) ([]*models.Allowance, int64, error) {
+	owner, err := normalizeAddress(params.Owner)
+	if err != nil {
+		return nil, 0, err
+	}
+	params.Owner = owner
+
+	return s.query(ctx, params)
+}
+
+// ListFlaggedApprovals returns open unlimited allowances granted to risky spenders across all owners
+func (s *ApprovalService) ListFlaggedApprovals(ctx context.Context, params models.AllowanceQueryParams) ([]*models.Allowance, int64, error) {
+	params.Owner = ""
+	params.UnlimitedOnly = true
+	params.Spenders = s.events.RiskySpenders()
+
+	return s.query(ctx, params)
+}
+
+func (s *ApprovalService) query(ctx context.Context, params models.AllowanceQueryParams) ([]*models.Allowance, int64, error) {
+	if params.Token != "" {
+		token, err := normalizeAddress(params.Token)
+		if err != nil {
+			return nil, 0, err
+		}
+		params.Token = token
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = 100
+	}
+	if params.Limit > 1000 {
+		params.Limit = 1000
+	}
+
+	allowances, total, err := s.approvalRepo.GetAllowances(ctx, params)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list allowances: %w", err)
+	}
+
+	for _, allowance := range allowances {
+		allowance.Risky = s.events.IsRiskySpender(allowance.Spender)
+	}
+
+	if err := s.metadata.ApplyToAllowances(ctx, allowances); err != nil {
+		s.logger.Warn("Failed to add token metadata to allowances: %v", err)
+	}
+
+	return allowances, total, nil
+}
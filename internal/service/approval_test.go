@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+)
+
+// metadataRepo serves stored token metadata and counts the tokens looked up
+type metadataRepo struct {
+	repository.TokenMetadataRepository
+	stored  map[string]*models.TokenMetadata
+	lookups int
+}
+
+func (r *metadataRepo) GetTokenMetadata(ctx context.Context, tokens []string) ([]*models.TokenMetadata, error) {
+	r.lookups += len(tokens)
+	found := make([]*models.TokenMetadata, 0, len(tokens))
+	for _, token := range tokens {
+		if metadata, ok := r.stored[token]; ok {
+			found = append(found, metadata)
+		}
+	}
+	return found, nil
+}
+
+// approvalRepo returns fixed allowances and records the last query
+type approvalRepo struct {
+	repository.ApprovalRepository
+	allowances []*models.Allowance
+	params     models.AllowanceQueryParams
+}
+
+func (r *approvalRepo) GetAllowances(ctx context.Context, params models.AllowanceQueryParams) ([]*models.Allowance, int64, error) {
+	r.params = params
+	return r.allowances, int64(len(r.allowances)), nil
+}
+
+const (
+	testUSDC    = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	testOwner   = "0x1111111111111111111111111111111111111111"
+	testDrainer = "0x2222222222222222222222222222222222222222"
+	testRouter  = "0x3333333333333333333333333333333333333333"
+)
+
+func newTestApprovalService(repo *approvalRepo) *ApprovalService {
+	log := logger.New("error", false, "", "text")
+	metadata := NewTokenMetadataService(nil, &metadataRepo{stored: map[string]*models.TokenMetadata{
+		testUSDC: {Address: testUSDC, Symbol: "USDC", Decimals: 6},
+	}}, nil, log, 16)
+	// Invalid configured risky spenders are dropped
+	events := &EventStore{riskySpenders: addressSet([]string{"0x2222222222222222222222222222222222222222", "0xBAD", "not-an-address"})}
+	return NewApprovalService(repo, events, metadata, log)
+}
+
+func TestListOpenApprovals(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    models.AllowanceQueryParams
+		wantQuery models.AllowanceQueryParams
+		wantErr   bool
+	}{
+		{
+			name:      "defaults",
+			params:    models.AllowanceQueryParams{Owner: "0x1111111111111111111111111111111111111111"},
+			wantQuery: models.AllowanceQueryParams{Owner: testOwner, Limit: 100},
+		},
+		{
+			name:      "normalized token and capped limit",
+			params:    models.AllowanceQueryParams{Owner: testOwner, Token: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Limit: 5000, Offset: 20},
+			wantQuery: models.AllowanceQueryParams{Owner: testOwner, Token: testUSDC, Limit: 1000, Offset: 20},
+		},
+		{name: "invalid owner", params: models.AllowanceQueryParams{Owner: "alice"}, wantErr: true},
+		{name: "invalid token", params: models.AllowanceQueryParams{Owner: testOwner, Token: "usdc"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &approvalRepo{}
+			_, _, err := newTestApprovalService(repo).ListOpenApprovals(context.Background(), tt.params)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("ListOpenApprovals() error = %v, want %v", err, ErrInvalidInput)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ListOpenApprovals() error = %v", err)
+			}
+			if !reflect.DeepEqual(repo.params, tt.wantQuery) {
+				t.Errorf("query = %+v, want %+v", repo.params, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestListFlaggedApprovals(t *testing.T) {
+	repo := &approvalRepo{allowances: []*models.Allowance{
+		{Token: testUSDC, Owner: testOwner, Spender: testDrainer, Unlimited: true},
+		{Token: testUSDC, Owner: testOwner, Spender: testRouter, Unlimited: true},
+	}}
+	s := newTestApprovalService(repo)
+
+	allowances, _, err := s.ListFlaggedApprovals(context.Background(), models.AllowanceQueryParams{Owner: testOwner})
+	if err != nil {
+		t.Fatalf("ListFlaggedApprovals() error = %v", err)
+	}
+
+	want := models.AllowanceQueryParams{Spenders: []string{testDrainer}, UnlimitedOnly: true, Limit: 100}
+	if !reflect.DeepEqual(repo.params, want) {
+		t.Errorf("query = %+v, want %+v", repo.params, want)
+	}
+	if !allowances[0].Risky || allowances[1].Risky {
+		t.Errorf("risky = %v, %v, want true, false", allowances[0].Risky, allowances[1].Risky)
+	}
+	for _, allowance := range allowances {
+		if allowance.Symbol != "USDC" || allowance.Decimals == nil || *allowance.Decimals != 6 {
+			t.Errorf("allowance metadata = %s/%v, want USDC/6", allowance.Symbol, allowance.Decimals)
+		}
+	}
+}
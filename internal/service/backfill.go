@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/metrics"
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// backfillScanInterval is how often unfinished jobs created by other replicas are looked for
+const backfillScanInterval = 30 * time.Second
+
+// Bounds of the wait before a failed chunk is released for another attempt, doubling per attempt
+const (
+	backfillRetryMin = time.Second
+	backfillRetryMax = time.Minute
+)
+
+// ErrBackfillNotFound is returned when a backfill job ID does not exist
+var ErrBackfillNotFound = fmt.Errorf("backfill job not found")
+
+// BackfillService ingests historical block ranges with parallel workers
+// Runs alongside IngestionService: it writes transfers but never touches the live checkpoint
+type BackfillService struct {
+	fetcher          *ethereum.Fetcher
+	repo             repository.Repository
+	events           *EventStore
+	backfillRepo     repository.BackfillRepository
+	logger           *logger.Logger
+	chain            string // chain_id metrics label
+	defaultWorkers   int
+	maxWorkers       int
+	defaultChunkSize uint64
+	maxChunkSize     uint64
+	batchSize        uint64 // Blocks per eth_getLogs call within a chunk
+	maxAttempts      int
+	statusFor        func(blockNumber uint64) string // Finality status for ingested transfers
+
+	runCtx  context.Context
+	running map[primitive.ObjectID]bool
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+}
+
+func NewBackfillService(
+	fetcher *ethereum.Fetcher,
+	repo repository.Repository,
+	events *EventStore,
+	backfillRepo repository.BackfillRepository,
+	logger *logger.Logger,
+	defaultWorkers int,
+	maxWorkers int,
+	defaultChunkSize uint64,
+	maxChunkSize uint64,
+	batchSize uint64,
+	maxAttempts int,
+	statusFor func(blockNumber uint64) string,
+) *BackfillService {
+	return &BackfillService{
+		fetcher:          fetcher,
+		repo:             repo,
+		events:           events,
+		backfillRepo:     backfillRepo,
+		logger:           logger,
+		chain:            metrics.ChainLabel(repo.ChainID()),
+		defaultWorkers:   defaultWorkers,
+		maxWorkers:       maxWorkers,
+		defaultChunkSize: defaultChunkSize,
+		maxChunkSize:     maxChunkSize,
+		batchSize:        batchSize,
+		maxAttempts:      maxAttempts,
+		statusFor:        statusFor,
+		running:          make(map[primitive.ObjectID]bool),
+	}
+}
+
+// Start resumes unfinished jobs and blocks until ctx is cancelled
+// Jobs created while this replica was not running backfills (e.g. through a follower's API)
+// are picked up by a periodic scan
+func (s *BackfillService) Start(ctx context.Context) error {
+	s.mu.Lock()
+	s.runCtx = ctx
+	s.mu.Unlock()
+
+	reset, err := s.backfillRepo.ResetRunningBackfillChunks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reset interrupted backfill chunks: %w", err)
+	}
+	if reset > 0 {
+		s.logger.Info("Reset %d interrupted backfill chunks to pending", reset)
+	}
+
+	if err := s.launchUnfinished(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(backfillScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+
+			// Jobs created after this point wait for the next Start
+			s.mu.Lock()
+			s.runCtx = nil
+			s.mu.Unlock()
+
+			s.logger.Info("Backfill service stopped")
+			return nil
+		case <-ticker.C:
+			if err := s.launchUnfinished(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Warn("%v", err)
+			}
+		}
+	}
+}
+
+// launchUnfinished launches every pending or running job that is not running in this process
+func (s *BackfillService) launchUnfinished(ctx context.Context) error {
+	jobs, err := s.backfillRepo.GetUnfinishedBackfillJobs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load unfinished backfill jobs: %w", err)
+	}
+	for _, job := range jobs {
+		if s.isRunning(job.ID) {
+			continue
+		}
+		s.logger.Info("Resuming backfill job %s (blocks %d-%d)", job.ID.Hex(), job.FromBlock, job.ToBlock)
+		s.launch(job)
+	}
+	return nil
+}
+
+// CreateJob validates a request, persists the job with its chunks and starts workers for it
+// Jobs created before Start are picked up when the service starts
+func (s *BackfillService) CreateJob(ctx context.Context, req models.CreateBackfillRequest) (*models.BackfillJob, error) {
+	if req.ToBlock < req.FromBlock {
+		return nil, fmt.Errorf("to_block (%d) must not be below from_block (%d)", req.ToBlock, req.FromBlock)
+	}
+
+	chunkSize := req.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = s.defaultChunkSize
+	}
+	if chunkSize > s.maxChunkSize {
+		return nil, fmt.Errorf("chunk_size (%d) must not exceed %d", chunkSize, s.maxChunkSize)
+	}
+	workers := req.Workers
+	if workers <= 0 {
+		workers = s.defaultWorkers
+	}
+	if workers > s.maxWorkers {
+		return nil, fmt.Errorf("workers (%d) must not exceed %d", workers, s.maxWorkers)
+	}
+
+	tokens := make([]string, 0, len(req.Tokens))
+	for _, token := range req.Tokens {
+		if !common.IsHexAddress(token) {
+			return nil, fmt.Errorf("invalid token address: %s", token)
+		}
+		tokens = append(tokens, strings.ToLower(common.HexToAddress(token).Hex()))
+	}
+
+	job := &models.BackfillJob{
+		FromBlock: req.FromBlock,
+		ToBlock:   req.ToBlock,
+		ChunkSize: chunkSize,
+		Workers:   workers,
+		Tokens:    tokens,
+		Status:    models.BackfillStatusPending,
+	}
+
+	chunks := make([]*models.BackfillChunk, 0, (req.ToBlock-req.FromBlock)/chunkSize+1)
+	for from := req.FromBlock; from <= req.ToBlock; from += chunkSize {
+		to := from + chunkSize - 1
+		if to > req.ToBlock || to < from {
+			to = req.ToBlock
+		}
+		chunks = append(chunks, &models.BackfillChunk{FromBlock: from, ToBlock: to})
+		if to == req.ToBlock {
+			break
+		}
+	}
+
+	if err := s.backfillRepo.CreateBackfillJob(ctx, job, chunks); err != nil {
+		return nil, fmt.Errorf("failed to create backfill job: %w", err)
+	}
+
+	s.logger.Info("Created backfill job %s: blocks %d-%d, %d chunks, %d workers", job.ID.Hex(), job.FromBlock, job.ToBlock, len(chunks), workers)
+	s.launch(job)
+
+	return job, nil
+}
+
+// GetProgress returns a job with its chunk counts and completion percentage
+func (s *BackfillService) GetProgress(ctx context.Context, id primitive.ObjectID) (*models.BackfillProgress, error) {
+	job, err := s.backfillRepo.GetBackfillJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrBackfillNotFound
+	}
+
+	counts, blocksDone, err := s.backfillRepo.GetBackfillChunkStats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &models.BackfillProgress{
+		Job:           job,
+		Chunks:        counts,
+		BlocksTotal:   job.ToBlock - job.FromBlock + 1,
+		BlocksIndexed: blocksDone,
+	}
+	progress.PercentDone = float64(progress.BlocksIndexed) / float64(progress.BlocksTotal) * 100
+
+	return progress, nil
+}
+
+// ListJobs returns the most recent backfill jobs
+func (s *BackfillService) ListJobs(ctx context.Context, limit int) ([]*models.BackfillJob, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	jobs, err := s.backfillRepo.ListBackfillJobs(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backfill jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (s *BackfillService) isRunning(id primitive.ObjectID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running[id]
+}
+
+// launch starts the worker pool for a job unless it is already running in this process
+func (s *BackfillService) launch(job *models.BackfillJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.runCtx == nil || s.running[job.ID] {
+		return
+	}
+	s.running[job.ID] = true
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.runJob(s.runCtx, job)
+
+		s.mu.Lock()
+		delete(s.running, job.ID)
+		s.mu.Unlock()
+	}()
+}
+
+// runJob runs the job's workers until no pending chunks remain, then records the final status
+func (s *BackfillService) runJob(ctx context.Context, job *models.BackfillJob) {
+	if err := s.backfillRepo.SetBackfillJobStatus(ctx, job.ID, models.BackfillStatusRunning); err != nil {
+		s.logger.Error("Failed to mark backfill job %s running: %v", job.ID.Hex(), err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < job.Workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			s.runWorker(ctx, job, worker)
+		}(i)
+	}
+	wg.Wait()
+
+	// Interrupted by shutdown - leave the job running so it resumes on next start
+	if ctx.Err() != nil {
+		return
+	}
+
+	counts, _, err := s.backfillRepo.GetBackfillChunkStats(ctx, job.ID)
+	if err != nil {
+		s.logger.Error("Failed to load backfill job %s stats: %v", job.ID.Hex(), err)
+		return
+	}
+
+	status := models.BackfillStatusCompleted
+	if counts[models.BackfillStatusFailed] > 0 {
+		status = models.BackfillStatusFailed
+	}
+	if err := s.backfillRepo.SetBackfillJobStatus(ctx, job.ID, status); err != nil {
+		s.logger.Error("Failed to mark backfill job %s %s: %v", job.ID.Hex(), status, err)
+		return
+	}
+
+	s.logger.Info("Backfill job %s %s (%d chunks completed, %d failed)", job.ID.Hex(), status, counts[models.BackfillStatusCompleted], counts[models.BackfillStatusFailed])
+}
+
+// runWorker claims and processes chunks until the job has none pending
+func (s *BackfillService) runWorker(ctx context.Context, job *models.BackfillJob, worker int) {
+	for ctx.Err() == nil {
+		chunk, err := s.backfillRepo.ClaimBackfillChunk(ctx, job.ID)
+		if err != nil {
+			s.logger.Error("Backfill worker %d failed to claim chunk: %v", worker, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backfillRetryMin):
+			}
+			continue
+		}
+		if chunk == nil {
+			return
+		}
+
+		transfers, err := s.processChunk(ctx, job, chunk)
+		if err != nil {
+			if ctx.Err() != nil {
+				// Shutdown mid-chunk: chunk stays running and is reset on next start
+				return
+			}
+			metrics.IngestionErrorsTotal.WithLabelValues(s.chain, "backfill").Inc()
+			s.logger.Warn("Backfill chunk %d-%d failed (attempt %d/%d): %v", chunk.FromBlock, chunk.ToBlock, chunk.Attempts, s.maxAttempts, err)
+			// The chunk stays claimed while waiting so no other worker retries it sooner
+			if chunk.Attempts < s.maxAttempts {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backfillRetryDelay(chunk.Attempts)):
+				}
+			}
+			if err := s.backfillRepo.FailBackfillChunk(ctx, chunk, err, s.maxAttempts); err != nil {
+				s.logger.Error("Failed to record backfill chunk failure: %v", err)
+			}
+			continue
+		}
+
+		// Token-restricted chunks only cover some contracts, so they don't count towards block coverage
+		if len(job.Tokens) == 0 {
+			if err := s.repo.AddCoveredRange(ctx, chunk.FromBlock, chunk.ToBlock); err != nil {
+				s.logger.Warn("Failed to record coverage for chunk %d-%d: %v", chunk.FromBlock, chunk.ToBlock, err)
+			}
+		}
+
+		if err := s.backfillRepo.CompleteBackfillChunk(ctx, chunk, transfers); err != nil {
+			s.logger.Error("Failed to record backfill chunk completion: %v", err)
+			continue
+		}
+		s.logger.Debug("Backfill worker %d completed chunk %d-%d (%d transfers)", worker, chunk.FromBlock, chunk.ToBlock, transfers)
+	}
+}
+
+// processChunk ingests a chunk in provider-sized batches and returns the number of events written
+// Inserts are idempotent, so a retried chunk simply skips transfers written by an earlier attempt
+func (s *BackfillService) processChunk(ctx context.Context, job *models.BackfillJob, chunk *models.BackfillChunk) (int, error) {
+	tokens := make([]common.Address, 0, len(job.Tokens))
+	for _, token := range job.Tokens {
+		tokens = append(tokens, common.HexToAddress(token))
+	}
+
+	total := 0
+	for from := chunk.FromBlock; from <= chunk.ToBlock; from += s.batchSize {
+		to := from + s.batchSize - 1
+		if to > chunk.ToBlock || to < from {
+			to = chunk.ToBlock
+		}
+
+		batchCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		var result *ethereum.FetchResult
+		var err error
+		if len(tokens) > 0 {
+			result, err = s.fetcher.FetchEventsForTokens(batchCtx, from, to, tokens)
+		} else {
+			result, err = s.fetcher.FetchEvents(batchCtx, from, to)
+		}
+		if err != nil {
+			cancel()
+			return total, fmt.Errorf("failed to fetch blocks %d-%d: %w", from, to, err)
+		}
+
+		if err := s.events.Store(batchCtx, result, s.statusFor); err != nil {
+			cancel()
+			return total, fmt.Errorf("blocks %d-%d: %w", from, to, err)
+		}
+		cancel()
+
+		total += result.Len()
+		metrics.TransfersProcessedTotal.WithLabelValues(s.chain, "backfill").Add(float64(result.Len()))
+		metrics.BlocksProcessedTotal.WithLabelValues(s.chain).Add(float64(to - from + 1))
+
+		if to == chunk.ToBlock {
+			break
+		}
+	}
+
+	return total, nil
+}
+
+// backfillRetryDelay returns the wait after a chunk's attempt-th failed attempt
+func backfillRetryDelay(attempt int) time.Duration {
+	delay := backfillRetryMin
+	for i := 1; i < attempt && delay < backfillRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, backfillRetryMax)
+}
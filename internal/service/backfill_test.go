@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// backfillRepo records created jobs and serves a single job's chunk stats
+type backfillRepo struct {
+	repository.BackfillRepository
+	job        *models.BackfillJob
+	chunks     []*models.BackfillChunk
+	counts     map[string]int64
+	blocksDone uint64
+}
+
+func (r *backfillRepo) CreateBackfillJob(ctx context.Context, job *models.BackfillJob, chunks []*models.BackfillChunk) error {
+	job.ID = primitive.NewObjectID()
+	r.job, r.chunks = job, chunks
+	return nil
+}
+
+func (r *backfillRepo) GetBackfillJob(ctx context.Context, id primitive.ObjectID) (*models.BackfillJob, error) {
+	if r.job == nil || r.job.ID != id {
+		return nil, nil
+	}
+	return r.job, nil
+}
+
+func (r *backfillRepo) GetBackfillChunkStats(ctx context.Context, jobID primitive.ObjectID) (map[string]int64, uint64, error) {
+	return r.counts, r.blocksDone, nil
+}
+
+func newTestBackfillService(repo repository.BackfillRepository) *BackfillService {
+	return &BackfillService{
+		backfillRepo:     repo,
+		logger:           logger.New("error", false, "", "text"),
+		defaultWorkers:   4,
+		maxWorkers:       16,
+		defaultChunkSize: 1000,
+		maxChunkSize:     10000,
+		running:          make(map[primitive.ObjectID]bool),
+	}
+}
+
+func TestCreateJob(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         models.CreateBackfillRequest
+		wantErr     bool
+		wantWorkers int
+		wantChunks  [][2]uint64
+		wantTokens  []string
+	}{
+		{
+			name:        "defaults",
+			req:         models.CreateBackfillRequest{FromBlock: 100, ToBlock: 2599},
+			wantWorkers: 4,
+			wantChunks:  [][2]uint64{{100, 1099}, {1100, 2099}, {2100, 2599}},
+		},
+		{
+			name:        "single block",
+			req:         models.CreateBackfillRequest{FromBlock: 7, ToBlock: 7, ChunkSize: 50, Workers: 2},
+			wantWorkers: 2,
+			wantChunks:  [][2]uint64{{7, 7}},
+		},
+		{
+			name:        "exact multiple of the chunk size",
+			req:         models.CreateBackfillRequest{FromBlock: 0, ToBlock: 199, ChunkSize: 100},
+			wantWorkers: 4,
+			wantChunks:  [][2]uint64{{0, 99}, {100, 199}},
+		},
+		{
+			name:        "range ending at the last uint64 block",
+			req:         models.CreateBackfillRequest{FromBlock: math.MaxUint64 - 149, ToBlock: math.MaxUint64, ChunkSize: 100},
+			wantWorkers: 4,
+			wantChunks:  [][2]uint64{{math.MaxUint64 - 149, math.MaxUint64 - 50}, {math.MaxUint64 - 49, math.MaxUint64}},
+		},
+		{
+			name:        "tokens normalized",
+			req:         models.CreateBackfillRequest{FromBlock: 1, ToBlock: 10, Tokens: []string{"0xA0B86991C6218B36C1D19D4A2E9EB0CE3606EB48"}},
+			wantWorkers: 4,
+			wantChunks:  [][2]uint64{{1, 10}},
+			wantTokens:  []string{"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"},
+		},
+		{name: "inverted range", req: models.CreateBackfillRequest{FromBlock: 10, ToBlock: 9}, wantErr: true},
+		{name: "chunk size above the maximum", req: models.CreateBackfillRequest{FromBlock: 1, ToBlock: 10, ChunkSize: 10001}, wantErr: true},
+		{name: "workers above the maximum", req: models.CreateBackfillRequest{FromBlock: 1, ToBlock: 10, Workers: 17}, wantErr: true},
+		{name: "invalid token", req: models.CreateBackfillRequest{FromBlock: 1, ToBlock: 10, Tokens: []string{"usdc"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &backfillRepo{}
+			job, err := newTestBackfillService(repo).CreateJob(context.Background(), tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateJob() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if repo.job != nil {
+					t.Errorf("rejected job was stored")
+				}
+				return
+			}
+
+			if job.Workers != tt.wantWorkers {
+				t.Errorf("Workers = %d, want %d", job.Workers, tt.wantWorkers)
+			}
+			if job.Status != models.BackfillStatusPending {
+				t.Errorf("Status = %s, want %s", job.Status, models.BackfillStatusPending)
+			}
+			if len(repo.chunks) != len(tt.wantChunks) {
+				t.Fatalf("got %d chunks, want %d", len(repo.chunks), len(tt.wantChunks))
+			}
+			for i, chunk := range repo.chunks {
+				if got := [2]uint64{chunk.FromBlock, chunk.ToBlock}; got != tt.wantChunks[i] {
+					t.Errorf("chunk %d = %v, want %v", i, got, tt.wantChunks[i])
+				}
+			}
+			if len(job.Tokens) != len(tt.wantTokens) {
+				t.Fatalf("Tokens = %v, want %v", job.Tokens, tt.wantTokens)
+			}
+			for i := range job.Tokens {
+				if job.Tokens[i] != tt.wantTokens[i] {
+					t.Errorf("Tokens = %v, want %v", job.Tokens, tt.wantTokens)
+				}
+			}
+		})
+	}
+}
+
+func TestGetProgress(t *testing.T) {
+	repo := &backfillRepo{
+		counts:     map[string]int64{models.BackfillStatusCompleted: 1, models.BackfillStatusPending: 3},
+		blocksDone: 250,
+	}
+	s := newTestBackfillService(repo)
+	job, err := s.CreateJob(context.Background(), models.CreateBackfillRequest{FromBlock: 1000, ToBlock: 1999, ChunkSize: 250})
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+
+	progress, err := s.GetProgress(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("GetProgress() error = %v", err)
+	}
+	if progress.BlocksTotal != 1000 || progress.BlocksIndexed != 250 || progress.PercentDone != 25 {
+		t.Errorf("GetProgress() = %d/%d blocks, %.1f%%, want 250/1000, 25%%", progress.BlocksIndexed, progress.BlocksTotal, progress.PercentDone)
+	}
+
+	if _, err := s.GetProgress(context.Background(), primitive.NewObjectID()); !errors.Is(err, ErrBackfillNotFound) {
+		t.Errorf("GetProgress() of unknown job error = %v, want %v", err, ErrBackfillNotFound)
+	}
+}
+
+func TestBackfillRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: time.Second},
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 4, want: 8 * time.Second},
+		{attempt: 7, want: time.Minute},
+		{attempt: 1000, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := backfillRetryDelay(tt.attempt); got != tt.want {
+			t.Errorf("backfillRetryDelay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ErrUnknownChain is returned when a request names a chain that is not indexed
+var ErrUnknownChain = fmt.Errorf("chain is not indexed")
+
+// Chain groups the services of one indexed chain
+// Each chain runs its own ingestion pipeline against its own providers and checkpoint
+type Chain struct {
+	ID          uint64
+	Name        string
+	Ingestion   *IngestionService
+	Backfill    *BackfillService
+	Coverage    *CoverageService
+	TokenFilter *TokenFilterService
+	Metadata    *TokenMetadataService
+	Supply      *SupplyService
+	NFT         *NFTService
+	Approval    *ApprovalService
+	DeadLetters *DeadLetterService
+	Control     *ControlService
+}
+
+// Chains is the registry of indexed chains in configuration order
+// The first chain is the default for requests that don't name one
+type Chains struct {
+	ordered []*Chain
+	byID    map[uint64]*Chain
+}
+
+func NewChains(chains []*Chain) (*Chains, error) {
+	if len(chains) == 0 {
+		return nil, fmt.Errorf("no chains configured")
+	}
+
+	byID := make(map[uint64]*Chain, len(chains))
+	for _, chain := range chains {
+		if _, ok := byID[chain.ID]; ok {
+			return nil, fmt.Errorf("chain %d is configured more than once", chain.ID)
+		}
+		byID[chain.ID] = chain
+	}
+
+	return &Chains{ordered: chains, byID: byID}, nil
+}
+
+// All returns every indexed chain in configuration order
+func (c *Chains) All() []*Chain {
+	return c.ordered
+}
+
+// Default returns the first configured chain
+func (c *Chains) Default() *Chain {
+	return c.ordered[0]
+}
+
+// Get returns the chain with the given ID, or nil if it is not indexed
+func (c *Chains) Get(chainID uint64) *Chain {
+	return c.byID[chainID]
+}
+
+// Resolve returns the chain named by a chain_id request parameter
+// An empty value selects the default chain
+func (c *Chains) Resolve(raw string) (*Chain, error) {
+	if raw == "" {
+		return c.Default(), nil
+	}
+
+	chainID, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chain_id: %s", raw)
+	}
+	chain := c.Get(chainID)
+	if chain == nil {
+		return nil, ErrUnknownChain
+	}
+	return chain, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestChainsResolve(t *testing.T) {
+	mainnet, base := &Chain{ID: 1, Name: "mainnet"}, &Chain{ID: 8453, Name: "base"}
+	chains, err := NewChains([]*Chain{mainnet, base})
+	if err != nil {
+		t.Fatalf("NewChains() error = %v", err)
+	}
+
+	tests := []struct {
+		raw     string
+		want    *Chain
+		wantErr error
+	}{
+		{raw: "", want: mainnet},
+		{raw: "8453", want: base},
+		{raw: "10", wantErr: ErrUnknownChain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := chains.Resolve(tt.raw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Resolve(%q) error = %v, want %v", tt.raw, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := chains.Resolve("base"); err == nil || errors.Is(err, ErrUnknownChain) {
+		t.Errorf("Resolve(\"base\") error = %v, want an invalid chain_id error", err)
+	}
+	if _, err := NewChains([]*Chain{mainnet, {ID: 1, Name: "again"}}); err == nil {
+		t.Errorf("NewChains() accepted a chain configured twice")
+	}
+	if _, err := NewChains(nil); err == nil {
+		t.Errorf("NewChains() accepted no chains")
+	}
+}
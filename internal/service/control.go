@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+)
+
+// maxFixedBatchSize caps the batch size set at runtime when adaptive batching is off, like BLOCK_BATCH_SIZE
+const maxFixedBatchSize = 100
+
+// ControlService records admin requests to pause, resume or rewind a chain's ingestion or change its batch size
+// Requests are stored in MongoDB so they reach the replica running ingestion, which applies them before its
+// next batch; each one is audited in the same transaction
+type ControlService struct {
+	repo      repository.Repository
+	control   repository.ControlRepository
+	ingestion *IngestionService
+	logger    *logger.Logger
+}
+
+func NewControlService(repo repository.Repository, control repository.ControlRepository, ingestion *IngestionService, logger *logger.Logger) *ControlService {
+	return &ControlService{
+		repo:      repo,
+		control:   control,
+		ingestion: ingestion,
+		logger:    logger,
+	}
+}
+
+// Get returns the requested ingestion state with the current checkpoint
+func (s *ControlService) Get(ctx context.Context) (*models.IngestionControl, error) {
+	control, err := s.control.GetIngestionControl(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if control == nil {
+		control = &models.IngestionControl{ChainID: s.repo.ChainID()}
+	}
+	return s.withCheckpoint(ctx, control)
+}
+
+// Pause stops ingestion after the batches already in flight are stored
+func (s *ControlService) Pause(ctx context.Context, actor models.Actor, reason string) (*models.IngestionControl, error) {
+	params := map[string]interface{}{"reason": reason}
+	return s.apply(ctx, actor, models.AuditActionPause, params, func(ctx context.Context) (*models.IngestionControl, error) {
+		return s.control.SetIngestionPaused(ctx, true, reason, actor.Name)
+	})
+}
+
+// Resume restarts paused ingestion from its checkpoint
+func (s *ControlService) Resume(ctx context.Context, actor models.Actor) (*models.IngestionControl, error) {
+	return s.apply(ctx, actor, models.AuditActionResume, nil, func(ctx context.Context) (*models.IngestionControl, error) {
+		return s.control.SetIngestionPaused(ctx, false, "", actor.Name)
+	})
+}
+
+// Rewind moves the checkpoint back to toBlock, which must be below the current checkpoint
+// With purge, transfers and derived data above toBlock are deleted before ingestion resumes
+func (s *ControlService) Rewind(ctx context.Context, actor models.Actor, toBlock uint64, purge bool) (*models.IngestionControl, error) {
+	params := map[string]interface{}{"to_block": toBlock, "purge": purge}
+
+	lastBlock, err := s.repo.GetLastProcessedBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last processed block: %w", err)
+	}
+	if toBlock >= lastBlock {
+		err := fmt.Errorf("%w: to_block %d must be below the last processed block %d", ErrInvalidInput, toBlock, lastBlock)
+		s.reject(ctx, actor, models.AuditActionRewind, params, err)
+		return nil, err
+	}
+
+	return s.apply(ctx, actor, models.AuditActionRewind, params, func(ctx context.Context) (*models.IngestionControl, error) {
+		return s.control.RequestIngestionRewind(ctx, &models.IngestionRewind{
+			ToBlock:     toBlock,
+			Purge:       purge,
+			RequestedAt: time.Now(),
+			RequestedBy: actor.Name,
+		})
+	})
+}
+
+// SetBatchSize switches ingestion to batchSize blocks per batch
+// With adaptive batching it must lie within BATCH_MIN_SIZE and BATCH_MAX_SIZE, and adapts from there
+func (s *ControlService) SetBatchSize(ctx context.Context, actor models.Actor, batchSize uint64) (*models.IngestionControl, error) {
+	params := map[string]interface{}{"batch_size": batchSize}
+
+	minSize, maxSize := s.ingestion.BatchSizeLimits()
+	if !s.ingestion.AdaptiveBatch() {
+		minSize, maxSize = 1, maxFixedBatchSize
+	}
+	if batchSize < minSize || batchSize > maxSize {
+		err := fmt.Errorf("%w: batch_size must be between %d and %d", ErrInvalidInput, minSize, maxSize)
+		s.reject(ctx, actor, models.AuditActionBatchSize, params, err)
+		return nil, err
+	}
+
+	return s.apply(ctx, actor, models.AuditActionBatchSize, params, func(ctx context.Context) (*models.IngestionControl, error) {
+		return s.control.SetIngestionBatchSize(ctx, batchSize, actor.Name)
+	})
+}
+
+// ListAudit returns the chain's audit entries newest first
+func (s *ControlService) ListAudit(ctx context.Context, params models.AuditQueryParams) ([]*models.AuditEntry, int64, error) {
+	switch params.Action {
+	case "", models.AuditActionPause, models.AuditActionResume, models.AuditActionRewind, models.AuditActionBatchSize:
+	default:
+		return nil, 0, fmt.Errorf("%w: action %s", ErrInvalidInput, params.Action)
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = 100
+	}
+	if params.Limit > 1000 {
+		params.Limit = 1000
+	}
+
+	entries, total, err := s.control.ListAuditEntries(ctx, params)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return entries, total, nil
+}
+
+// apply runs update and records its audit entry in one transaction
+func (s *ControlService) apply(
+	ctx context.Context,
+	actor models.Actor,
+	action string,
+	params map[string]interface{},
+	update func(ctx context.Context) (*models.IngestionControl, error),
+) (*models.IngestionControl, error) {
+	var control *models.IngestionControl
+	err := s.repo.WithTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		if control, err = update(txCtx); err != nil {
+			return err
+		}
+		return s.control.InsertAuditEntry(txCtx, &models.AuditEntry{
+			Action:     action,
+			Actor:      actor.Name,
+			RemoteAddr: actor.RemoteAddr,
+			Params:     params,
+			Result:     models.AuditResultApplied,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Admin %s requested ingestion %s %v", actor.Name, action, params)
+	return s.withCheckpoint(ctx, control)
+}
+
+// reject records an action refused before it was applied; the audit write is best effort
+func (s *ControlService) reject(ctx context.Context, actor models.Actor, action string, params map[string]interface{}, reason error) {
+	entry := &models.AuditEntry{
+		Action:     action,
+		Actor:      actor.Name,
+		RemoteAddr: actor.RemoteAddr,
+		Params:     params,
+		Result:     models.AuditResultRejected,
+		Error:      reason.Error(),
+	}
+	if err := s.control.InsertAuditEntry(ctx, entry); err != nil {
+		s.logger.Warn("Failed to audit rejected %s by %s: %v", action, actor.Name, err)
+	}
+}
+
+func (s *ControlService) withCheckpoint(ctx context.Context, control *models.IngestionControl) (*models.IngestionControl, error) {
+	lastBlock, err := s.repo.GetLastProcessedBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last processed block: %w", err)
+	}
+	control.LastProcessedBlock = lastBlock
+	return control, nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+)
+
+// txMarker tags contexts passed to fn by auditRepo.WithTransaction
+type txMarker struct{}
+
+// auditRepo serves a fixed checkpoint and runs transactions in place
+type auditRepo struct {
+	repository.Repository
+	lastBlock uint64
+}
+
+func (r *auditRepo) ChainID() uint64 { return 1 }
+
+func (r *auditRepo) GetLastProcessedBlock(ctx context.Context) (uint64, error) {
+	return r.lastBlock, nil
+}
+
+func (r *auditRepo) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, txMarker{}, true))
+}
+
+// auditEntry is an audit entry with whether it was written inside a transaction
+type auditEntry struct {
+	*models.AuditEntry
+	inTx bool
+}
+
+// controlStore serves a fixed admin control document and records what is requested and audited
+type controlStore struct {
+	repository.ControlRepository
+	control   *models.IngestionControl
+	rewind    *models.IngestionRewind
+	batchSize uint64
+	audit     []auditEntry
+	rewoundTo []uint64
+	completed []time.Time
+}
+
+func (s *controlStore) GetIngestionControl(ctx context.Context) (*models.IngestionControl, error) {
+	return s.control, nil
+}
+
+func (s *controlStore) RequestIngestionRewind(ctx context.Context, rewind *models.IngestionRewind) (*models.IngestionControl, error) {
+	s.rewind = rewind
+	return &models.IngestionControl{ChainID: 1, Rewind: rewind}, nil
+}
+
+func (s *controlStore) SetIngestionBatchSize(ctx context.Context, batchSize uint64, actor string) (*models.IngestionControl, error) {
+	s.batchSize = batchSize
+	return &models.IngestionControl{ChainID: 1, BatchSize: batchSize}, nil
+}
+
+func (s *controlStore) InsertAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	s.audit = append(s.audit, auditEntry{AuditEntry: entry, inTx: ctx.Value(txMarker{}) != nil})
+	return nil
+}
+
+func (s *controlStore) RewindToBlock(ctx context.Context, blockNumber uint64, purge bool) (int64, error) {
+	s.rewoundTo = append(s.rewoundTo, blockNumber)
+	return 0, nil
+}
+
+func (s *controlStore) CompleteIngestionRewind(ctx context.Context, requestedAt time.Time) error {
+	s.completed = append(s.completed, requestedAt)
+	return nil
+}
+
+var testAdmin = models.Actor{Name: "ops", RemoteAddr: "10.0.0.7"}
+
+func newTestControlService(store *controlStore, ingestion *IngestionService) *ControlService {
+	return NewControlService(&auditRepo{lastBlock: 100}, store, ingestion, logger.New("error", false, "", "text"))
+}
+
+// checkAudit verifies the single audit entry recorded for an admin action
+func checkAudit(t *testing.T, store *controlStore, action, result string) {
+	t.Helper()
+	if len(store.audit) != 1 {
+		t.Fatalf("audit entries = %d, want 1", len(store.audit))
+	}
+	entry := store.audit[0]
+	if entry.Action != action || entry.Result != result {
+		t.Errorf("audit entry = %s %s, want %s %s", entry.Action, entry.Result, action, result)
+	}
+	if entry.Actor != testAdmin.Name || entry.RemoteAddr != testAdmin.RemoteAddr {
+		t.Errorf("audit actor = %s from %s, want %s from %s", entry.Actor, entry.RemoteAddr, testAdmin.Name, testAdmin.RemoteAddr)
+	}
+	// Applied actions are audited atomically with the change; rejections are best effort
+	if wantTx := result == models.AuditResultApplied; entry.inTx != wantTx {
+		t.Errorf("audit entry in transaction = %v, want %v", entry.inTx, wantTx)
+	}
+}
+
+func TestControlRewind(t *testing.T) {
+	tests := []struct {
+		name    string
+		toBlock uint64
+		wantErr bool
+	}{
+		{name: "below the checkpoint", toBlock: 90},
+		{name: "genesis", toBlock: 0},
+		{name: "at the checkpoint", toBlock: 100, wantErr: true},
+		{name: "ahead of the checkpoint", toBlock: 150, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &controlStore{}
+			control, err := newTestControlService(store, nil).Rewind(context.Background(), testAdmin, tt.toBlock, true)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("Rewind() error = %v, want ErrInvalidInput", err)
+				}
+				if store.rewind != nil {
+					t.Errorf("Rewind() requested %+v, want nothing", store.rewind)
+				}
+				checkAudit(t, store, models.AuditActionRewind, models.AuditResultRejected)
+				return
+			}
+			if err != nil {
+				t.Fatalf("Rewind() error = %v", err)
+			}
+
+			if store.rewind == nil || store.rewind.ToBlock != tt.toBlock || !store.rewind.Purge || store.rewind.RequestedBy != "ops" {
+				t.Errorf("Rewind() requested %+v, want a purge to %d by ops", store.rewind, tt.toBlock)
+			}
+			if control.LastProcessedBlock != 100 {
+				t.Errorf("Rewind() last processed block = %d, want 100", control.LastProcessedBlock)
+			}
+			checkAudit(t, store, models.AuditActionRewind, models.AuditResultApplied)
+		})
+	}
+}
+
+func TestControlSetBatchSize(t *testing.T) {
+	adaptive := &IngestionService{adaptiveBatch: true, batchMinSize: 5, batchMaxSize: 50}
+	fixed := &IngestionService{batchMinSize: 5, batchMaxSize: 50}
+
+	tests := []struct {
+		name      string
+		ingestion *IngestionService
+		batchSize uint64
+		wantErr   bool
+	}{
+		{name: "adaptive minimum", ingestion: adaptive, batchSize: 5},
+		{name: "adaptive maximum", ingestion: adaptive, batchSize: 50},
+		{name: "below adaptive minimum", ingestion: adaptive, batchSize: 4, wantErr: true},
+		{name: "above adaptive maximum", ingestion: adaptive, batchSize: 51, wantErr: true},
+		{name: "fixed ignores adaptive bounds", ingestion: fixed, batchSize: 1},
+		{name: "fixed maximum", ingestion: fixed, batchSize: maxFixedBatchSize},
+		{name: "above fixed maximum", ingestion: fixed, batchSize: maxFixedBatchSize + 1, wantErr: true},
+		{name: "zero", ingestion: fixed, batchSize: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &controlStore{}
+			_, err := newTestControlService(store, tt.ingestion).SetBatchSize(context.Background(), testAdmin, tt.batchSize)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("SetBatchSize() error = %v, want ErrInvalidInput", err)
+				}
+				checkAudit(t, store, models.AuditActionBatchSize, models.AuditResultRejected)
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetBatchSize() error = %v", err)
+			}
+			if store.batchSize != tt.batchSize {
+				t.Errorf("SetBatchSize() stored %d, want %d", store.batchSize, tt.batchSize)
+			}
+			checkAudit(t, store, models.AuditActionBatchSize, models.AuditResultApplied)
+		})
+	}
+}
+
+func TestApplyControl(t *testing.T) {
+	chain, client := newHeaderChain(t, 200)
+	requestedAt := time.Now()
+
+	t.Run("no requests", func(t *testing.T) {
+		s := newPipelineService(client, newCheckpointRepo(), 10)
+		s.controlRepo = &controlStore{}
+		next, paused, err := s.applyControl(context.Background(), 120)
+		if err != nil || next != 120 || paused {
+			t.Errorf("applyControl() = %d, %v, %v, want 120, false, nil", next, paused, err)
+		}
+	})
+
+	t.Run("paused", func(t *testing.T) {
+		s := newPipelineService(client, newCheckpointRepo(), 10)
+		s.controlRepo = &controlStore{control: &models.IngestionControl{Paused: true, PauseReason: "provider migration"}}
+		next, paused, err := s.applyControl(context.Background(), 120)
+		if err != nil || next != 120 || !paused {
+			t.Errorf("applyControl() = %d, %v, %v, want 120, true, nil", next, paused, err)
+		}
+		if !s.paused {
+			t.Errorf("service not marked paused")
+		}
+	})
+
+	t.Run("rewind", func(t *testing.T) {
+		repo := newCheckpointRepo()
+		store := &controlStore{control: &models.IngestionControl{
+			Rewind: &models.IngestionRewind{ToBlock: 90, Purge: true, RequestedAt: requestedAt, RequestedBy: "ops"},
+		}}
+		s := newPipelineService(client, repo, 10)
+		s.controlRepo = store
+
+		next, paused, err := s.applyControl(context.Background(), 120)
+		if err != nil || next != 91 || paused {
+			t.Fatalf("applyControl() = %d, %v, %v, want 91, false, nil", next, paused, err)
+		}
+		if len(store.rewoundTo) != 1 || store.rewoundTo[0] != 90 {
+			t.Errorf("rewound to %v, want 90", store.rewoundTo)
+		}
+		// The checkpoint records the canonical block so the next batch passes the reorg check
+		checkpoint := repo.checkpoints[90]
+		if checkpoint == nil || checkpoint.BlockHash != chain.hash(90) {
+			t.Errorf("checkpoint 90 = %+v, want hash %s", checkpoint, chain.hash(90))
+		}
+		if len(store.completed) != 1 || !store.completed[0].Equal(requestedAt) {
+			t.Errorf("completed rewinds %v, want the one requested at %v", store.completed, requestedAt)
+		}
+	})
+
+	t.Run("batch size applied once per request", func(t *testing.T) {
+		store := &controlStore{control: &models.IngestionControl{BatchSize: 20, BatchSizeSetAt: &requestedAt}}
+		s := newPipelineService(client, newCheckpointRepo(), 10)
+		s.controlRepo = store
+
+		if _, _, err := s.applyControl(context.Background(), 120); err != nil {
+			t.Fatalf("applyControl() error = %v", err)
+		}
+		if size := s.batchSize(); size != 20 {
+			t.Fatalf("batch size = %d, want 20", size)
+		}
+
+		// Adaptive batching moves on from the requested size; the same request is not applied again
+		s.currentBatchSize = 35
+		if s.controlInterrupts(context.Background()) {
+			t.Errorf("controlInterrupts() = true without a pause or rewind")
+		}
+		if size := s.batchSize(); size != 35 {
+			t.Errorf("batch size = %d, want 35 after the request was applied", size)
+		}
+
+		later := requestedAt.Add(time.Minute)
+		store.control = &models.IngestionControl{BatchSize: 20, BatchSizeSetAt: &later}
+		if _, _, err := s.applyControl(context.Background(), 120); err != nil {
+			t.Fatalf("applyControl() error = %v", err)
+		}
+		if size := s.batchSize(); size != 20 {
+			t.Errorf("batch size = %d, want 20 from the new request", size)
+		}
+	})
+}
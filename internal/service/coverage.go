@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pagrin/internal/metrics"
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+)
+
+// repairBackoffMax bounds the wait before a gap whose repair failed is repaired again
+const repairBackoffMax = 24 * time.Hour
+
+// CoverageService reports indexed block ranges and repairs gaps between them
+// Gaps are re-ingested through BackfillService, which records coverage as chunks complete
+type CoverageService struct {
+	repo           repository.Repository
+	backfillRepo   repository.BackfillRepository
+	backfill       *BackfillService
+	logger         *logger.Logger
+	chain          string        // chain_id metrics label
+	startBlock     uint64        // First block coverage is expected from (START_BLOCK)
+	repairInterval time.Duration // 0 disables automatic repair
+	seedMaxBatch   uint64        // Max checkpoint spacing treated as contiguous when seeding legacy coverage
+}
+
+func NewCoverageService(
+	repo repository.Repository,
+	backfillRepo repository.BackfillRepository,
+	backfill *BackfillService,
+	logger *logger.Logger,
+	startBlock uint64,
+	repairInterval time.Duration,
+	seedMaxBatch uint64,
+) *CoverageService {
+	return &CoverageService{
+		repo:           repo,
+		backfillRepo:   backfillRepo,
+		backfill:       backfill,
+		logger:         logger,
+		chain:          metrics.ChainLabel(repo.ChainID()),
+		startBlock:     startBlock,
+		repairInterval: repairInterval,
+		seedMaxBatch:   seedMaxBatch,
+	}
+}
+
+// SeedLegacyCoverage reconstructs coverage from checkpoints when no ranges are stored yet
+// Must run before ingestion starts, otherwise the first live batch would make coverage look non-empty
+func (s *CoverageService) SeedLegacyCoverage(ctx context.Context) error {
+	count, err := s.repo.CountCoveredRanges(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check coverage: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	seeded, err := s.repo.SeedCoverageFromCheckpoints(ctx, s.seedMaxBatch)
+	if err != nil {
+		return fmt.Errorf("failed to seed coverage from checkpoints: %w", err)
+	}
+	if seeded > 0 {
+		s.logger.Info("Seeded %d covered ranges from existing checkpoints", seeded)
+	}
+
+	return nil
+}
+
+// Start repairs coverage gaps periodically until ctx is cancelled
+func (s *CoverageService) Start(ctx context.Context) error {
+	if s.repairInterval <= 0 {
+		s.logger.Info("Coverage gap repair disabled")
+		return nil
+	}
+
+	ticker := time.NewTicker(s.repairInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := s.repairGaps(ctx); err != nil {
+				metrics.IngestionErrorsTotal.WithLabelValues(s.chain, "gap_repair").Inc()
+				s.logger.Error("Failed to repair coverage gaps: %v", err)
+			}
+		}
+	}
+}
+
+// GetCoverage returns covered ranges, the gaps between them and the live checkpoint
+func (s *CoverageService) GetCoverage(ctx context.Context) (*models.CoverageResponse, error) {
+	ranges, err := s.repo.GetCoveredRanges(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get covered ranges: %w", err)
+	}
+
+	lastBlock, err := s.repo.GetLastProcessedBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last processed block: %w", err)
+	}
+
+	gaps := findGaps(s.startBlock, ranges)
+	s.recordGapMetrics(gaps)
+
+	return &models.CoverageResponse{
+		ChainID:            s.repo.ChainID(),
+		Ranges:             ranges,
+		Gaps:               gaps,
+		LastProcessedBlock: lastBlock,
+		Contiguous:         len(gaps) == 0,
+	}, nil
+}
+
+// repairGaps starts a backfill job for each gap not already covered by an unfinished job
+// A gap whose repair failed waits the repair interval doubled per recent failure, up to repairBackoffMax
+func (s *CoverageService) repairGaps(ctx context.Context) error {
+	ranges, err := s.repo.GetCoveredRanges(ctx)
+	if err != nil {
+		return err
+	}
+
+	gaps := findGaps(s.startBlock, ranges)
+	s.recordGapMetrics(gaps)
+	if len(gaps) == 0 {
+		return nil
+	}
+
+	jobs, err := s.backfillRepo.GetUnfinishedBackfillJobs(ctx)
+	if err != nil {
+		return err
+	}
+	failed, err := s.backfillRepo.GetFailedBackfillJobsSince(ctx, time.Now().Add(-repairBackoffMax))
+	if err != nil {
+		return err
+	}
+
+	for _, gap := range gaps {
+		if gapHasJob(gap, jobs) {
+			continue
+		}
+		if failures, last := gapFailures(gap, failed); failures > 0 {
+			if wait := repairBackoff(s.repairInterval, failures); time.Since(last) < wait {
+				s.logger.Debug("Coverage gap at blocks %d-%d failed repair %d times, retrying after %s", gap.FromBlock, gap.ToBlock, failures, last.Add(wait).Format(time.RFC3339))
+				continue
+			}
+		}
+
+		job, err := s.backfill.CreateJob(ctx, models.CreateBackfillRequest{
+			FromBlock: gap.FromBlock,
+			ToBlock:   gap.ToBlock,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to start repair for blocks %d-%d: %w", gap.FromBlock, gap.ToBlock, err)
+		}
+		s.logger.Warn("Coverage gap at blocks %d-%d, started repair backfill %s", gap.FromBlock, gap.ToBlock, job.ID.Hex())
+	}
+
+	return nil
+}
+
+func (s *CoverageService) recordGapMetrics(gaps []models.BlockRange) {
+	var missing uint64
+	for _, gap := range gaps {
+		missing += gap.ToBlock - gap.FromBlock + 1
+	}
+	metrics.CoverageGaps.WithLabelValues(s.chain).Set(float64(len(gaps)))
+	metrics.CoverageMissingBlocks.WithLabelValues(s.chain).Set(float64(missing))
+}
+
+// findGaps returns the holes in sorted, coalesced covered ranges from startBlock to the last range,
+// including the one between startBlock and the first range; blocks below startBlock are ignored
+func findGaps(startBlock uint64, ranges []*models.CoveredRange) []models.BlockRange {
+	gaps := make([]models.BlockRange, 0)
+	next := startBlock // First block not known to be covered
+	for _, covered := range ranges {
+		if covered.FromBlock > next {
+			gaps = append(gaps, models.BlockRange{FromBlock: next, ToBlock: covered.FromBlock - 1})
+		}
+		next = max(next, covered.ToBlock+1)
+	}
+	return gaps
+}
+
+// gapHasJob reports whether an unfinished backfill job already overlaps the gap
+// Token-restricted jobs are ignored since they don't fill block coverage
+func gapHasJob(gap models.BlockRange, jobs []*models.BackfillJob) bool {
+	for _, job := range jobs {
+		if len(job.Tokens) > 0 {
+			continue
+		}
+		if job.FromBlock <= gap.ToBlock && job.ToBlock >= gap.FromBlock {
+			return true
+		}
+	}
+	return false
+}
+
+// gapFailures counts the failed block-wide jobs overlapping the gap and returns when the latest failed
+func gapFailures(gap models.BlockRange, jobs []*models.BackfillJob) (int, time.Time) {
+	var failures int
+	var last time.Time
+	for _, job := range jobs {
+		if len(job.Tokens) > 0 || job.FromBlock > gap.ToBlock || job.ToBlock < gap.FromBlock {
+			continue
+		}
+		failures++
+		if job.CompletedAt != nil && job.CompletedAt.After(last) {
+			last = *job.CompletedAt
+		}
+	}
+	return failures, last
+}
+
+// repairBackoff returns the wait before repairing a gap again after failures failed repairs
+func repairBackoff(interval time.Duration, failures int) time.Duration {
+	wait := interval
+	for i := 0; i < failures && wait < repairBackoffMax; i++ {
+		wait *= 2
+	}
+	return min(wait, repairBackoffMax)
+}
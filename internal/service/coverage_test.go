@@ -0,0 +1,83 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"pagrin/internal/models"
+)
+
+func TestFindGaps(t *testing.T) {
+	covered := func(bounds ...uint64) []*models.CoveredRange {
+		ranges := make([]*models.CoveredRange, 0, len(bounds)/2)
+		for i := 0; i+1 < len(bounds); i += 2 {
+			ranges = append(ranges, &models.CoveredRange{FromBlock: bounds[i], ToBlock: bounds[i+1]})
+		}
+		return ranges
+	}
+
+	tests := []struct {
+		name       string
+		startBlock uint64
+		ranges     []*models.CoveredRange
+		want       []models.BlockRange
+	}{
+		{
+			name: "nothing covered",
+			want: []models.BlockRange{},
+		},
+		{
+			name:       "contiguous from the start block",
+			startBlock: 100,
+			ranges:     covered(100, 200, 201, 300),
+			want:       []models.BlockRange{},
+		},
+		{
+			name:       "hole between ranges",
+			startBlock: 100,
+			ranges:     covered(100, 200, 251, 300),
+			want:       []models.BlockRange{{FromBlock: 201, ToBlock: 250}},
+		},
+		{
+			name:       "hole after the start block",
+			startBlock: 100,
+			ranges:     covered(150, 200),
+			want:       []models.BlockRange{{FromBlock: 100, ToBlock: 149}},
+		},
+		{
+			name:   "hole after genesis",
+			ranges: covered(1, 10),
+			want:   []models.BlockRange{{FromBlock: 0, ToBlock: 0}},
+		},
+		{
+			name:       "every hole",
+			startBlock: 100,
+			ranges:     covered(110, 120, 130, 140, 142, 150),
+			want: []models.BlockRange{
+				{FromBlock: 100, ToBlock: 109},
+				{FromBlock: 121, ToBlock: 129},
+				{FromBlock: 141, ToBlock: 141},
+			},
+		},
+		{
+			name:       "ranges below the start block are ignored",
+			startBlock: 100,
+			ranges:     covered(0, 50, 120, 200),
+			want:       []models.BlockRange{{FromBlock: 100, ToBlock: 119}},
+		},
+		{
+			name:       "range across the start block",
+			startBlock: 100,
+			ranges:     covered(50, 150, 160, 200),
+			want:       []models.BlockRange{{FromBlock: 151, ToBlock: 159}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findGaps(tt.startBlock, tt.ranges); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findGaps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/metrics"
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeadLetterService lists dead letters and re-processes them after parser fixes
+type DeadLetterService struct {
+	deadLetters repository.DeadLetterRepository
+	fetcher     *ethereum.Fetcher
+	events      *EventStore
+	logger      *logger.Logger
+	chain       string                          // chain_id metrics label
+	statusFor   func(blockNumber uint64) string // Finality status for re-processed transfers
+}
+
+func NewDeadLetterService(
+	deadLetters repository.DeadLetterRepository,
+	fetcher *ethereum.Fetcher,
+	events *EventStore,
+	logger *logger.Logger,
+	chainID uint64,
+	statusFor func(blockNumber uint64) string,
+) *DeadLetterService {
+	return &DeadLetterService{
+		deadLetters: deadLetters,
+		fetcher:     fetcher,
+		events:      events,
+		logger:      logger,
+		chain:       metrics.ChainLabel(chainID),
+		statusFor:   statusFor,
+	}
+}
+
+// List returns dead letters matching params, oldest block first
+func (s *DeadLetterService) List(ctx context.Context, params models.DeadLetterQueryParams) ([]*models.DeadLetter, int64, error) {
+	switch params.Status {
+	case "", models.DeadLetterStatusOpen, models.DeadLetterStatusResolved:
+	default:
+		return nil, 0, fmt.Errorf("%w: status %s", ErrInvalidInput, params.Status)
+	}
+	switch params.Kind {
+	case "", models.DeadLetterRejected, models.DeadLetterDegraded:
+	default:
+		return nil, 0, fmt.Errorf("%w: kind %s", ErrInvalidInput, params.Kind)
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = 100
+	}
+	if params.Limit > 1000 {
+		params.Limit = 1000
+	}
+
+	letters, total, err := s.deadLetters.ListDeadLetters(ctx, params)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return letters, total, nil
+}
+
+// Reprocess decodes dead letters again with the current parsers and stores the events that now decode cleanly
+// Without ids the oldest open dead letters are re-processed, up to limit; resolved ones are skipped
+// Degraded transfers already stored for a log are replaced by the re-processed ones
+func (s *DeadLetterService) Reprocess(ctx context.Context, ids []string, limit int) (*models.ReprocessDeadLettersResponse, error) {
+	letters, err := s.selectForReprocess(ctx, ids, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.ReprocessDeadLettersResponse{Open: make([]*models.DeadLetter, 0)}
+	for _, letter := range letters {
+		if letter.Status != models.DeadLetterStatusOpen {
+			continue
+		}
+
+		result, err := s.fetcher.ParseDeadLetter(letter)
+		if err != nil {
+			return response, err
+		}
+
+		if len(result.DeadLetters) > 0 {
+			// Still rejected or degraded - keep it open with the current reason
+			again := result.DeadLetters[0]
+			if err := s.deadLetters.FailDeadLetter(ctx, letter.ID, again.Kind, again.Reason); err != nil {
+				return response, err
+			}
+			letter.Kind, letter.Reason = again.Kind, again.Reason
+			letter.Attempts++
+			response.Failed++
+			response.Open = append(response.Open, letter)
+			metrics.DeadLettersReprocessedTotal.WithLabelValues(s.chain, "failed").Inc()
+			continue
+		}
+
+		if letter.Kind == models.DeadLetterDegraded {
+			if err := s.deadLetters.DeleteLogTransfers(ctx, letter.TxHash, letter.LogIndex); err != nil {
+				return response, err
+			}
+		}
+		if err := s.events.Store(ctx, result, s.statusFor); err != nil {
+			return response, fmt.Errorf("failed to store re-processed log %s:%d: %w", letter.TxHash, letter.LogIndex, err)
+		}
+		if err := s.deadLetters.ResolveDeadLetter(ctx, letter.ID); err != nil {
+			return response, err
+		}
+		response.Resolved++
+		metrics.DeadLettersReprocessedTotal.WithLabelValues(s.chain, "resolved").Inc()
+	}
+
+	if response.Resolved > 0 {
+		s.logger.Info("Re-processed %d dead letters (%d still open)", response.Resolved, response.Failed)
+	}
+	return response, nil
+}
+
+func (s *DeadLetterService) selectForReprocess(ctx context.Context, ids []string, limit int) ([]*models.DeadLetter, error) {
+	if len(ids) == 0 {
+		letters, _, err := s.List(ctx, models.DeadLetterQueryParams{Status: models.DeadLetterStatusOpen, Limit: limit})
+		return letters, err
+	}
+
+	objectIDs := make([]primitive.ObjectID, len(ids))
+	for i, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: dead letter id %s", ErrInvalidInput, id)
+		}
+		objectIDs[i] = objectID
+	}
+
+	letters, err := s.deadLetters.GetDeadLetters(ctx, objectIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letters: %w", err)
+	}
+	return letters, nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// writeLog records dead letter and event writes in the order they happen, by log index
+type writeLog struct {
+	calls []string
+}
+
+func (l *writeLog) add(format string, args ...any) {
+	l.calls = append(l.calls, fmt.Sprintf(format, args...))
+}
+
+// deadLetterRepo serves fixed dead letters and logs the updates made to them
+type deadLetterRepo struct {
+	repository.DeadLetterRepository
+	writes  *writeLog
+	letters []*models.DeadLetter
+	params  models.DeadLetterQueryParams
+	ids     []primitive.ObjectID
+}
+
+func (r *deadLetterRepo) ListDeadLetters(ctx context.Context, params models.DeadLetterQueryParams) ([]*models.DeadLetter, int64, error) {
+	r.params = params
+	return r.letters, int64(len(r.letters)), nil
+}
+
+func (r *deadLetterRepo) GetDeadLetters(ctx context.Context, ids []primitive.ObjectID) ([]*models.DeadLetter, error) {
+	r.ids = ids
+	return r.letters, nil
+}
+
+func (r *deadLetterRepo) ResolveDeadLetter(ctx context.Context, id primitive.ObjectID) error {
+	r.writes.add("resolve %d", id[11])
+	return nil
+}
+
+func (r *deadLetterRepo) FailDeadLetter(ctx context.Context, id primitive.ObjectID, kind, reason string) error {
+	r.writes.add("fail %d %s", id[11], kind)
+	return nil
+}
+
+func (r *deadLetterRepo) DeleteLogTransfers(ctx context.Context, txHash string, logIndex uint) error {
+	r.writes.add("delete %d", logIndex)
+	return nil
+}
+
+func (r *deadLetterRepo) SaveDeadLetters(ctx context.Context, letters []*models.DeadLetter) error {
+	return nil
+}
+
+// transferRepo logs the transfers written by the event store
+type transferRepo struct {
+	repository.Repository
+	writes *writeLog
+}
+
+func (r *transferRepo) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *transferRepo) InsertTransfers(ctx context.Context, transfers []*models.Transfer) error {
+	for _, transfer := range transfers {
+		r.writes.add("insert %d", transfer.LogIndex)
+	}
+	return nil
+}
+
+// ignoreSupply accepts supply changes without tracking them
+type ignoreSupply struct {
+	repository.SupplyRepository
+}
+
+func (ignoreSupply) RecordSupplyChanges(ctx context.Context, transfers []*models.Transfer) error {
+	return nil
+}
+
+// testDeadLetter builds a USDC Transfer dead letter identified by i, with the first topics of the event
+func testDeadLetter(i int, topics int, kind, status string) *models.DeadLetter {
+	id, _ := primitive.ObjectIDFromHex(fmt.Sprintf("%024x", i))
+	all := []string{
+		ethereum.ERC20TransferEventSignature.Hex(),
+		common.BytesToHash(common.HexToAddress(testOwner).Bytes()).Hex(),
+		common.BytesToHash(common.HexToAddress(testRouter).Bytes()).Hex(),
+	}
+	return &models.DeadLetter{
+		ID:          id,
+		Kind:        kind,
+		Event:       ethereum.EventSignatureTransfer,
+		Address:     testUSDC,
+		Topics:      all[:topics],
+		Data:        hexutil.Encode(common.LeftPadBytes(big.NewInt(1_000_000).Bytes(), 32)),
+		BlockNumber: 100,
+		TxHash:      fmt.Sprintf("0x%064x", i),
+		LogIndex:    uint(i),
+		Timestamp:   time.Unix(1_700_000_000, 0),
+		Status:      status,
+	}
+}
+
+func newTestDeadLetterService(repo *deadLetterRepo) *DeadLetterService {
+	log := logger.New("error", false, "", "text")
+	metadata := NewTokenMetadataService(nil, &metadataRepo{stored: map[string]*models.TokenMetadata{
+		testUSDC: {Address: testUSDC, Symbol: "USDC", Decimals: 6},
+	}}, nil, log, 16)
+	events := &EventStore{
+		repo:         &transferRepo{writes: repo.writes},
+		nftRepo:      discardEvents{},
+		approvalRepo: discardEvents{},
+		supplyRepo:   ignoreSupply{},
+		deadLetters:  repo,
+		metadata:     metadata,
+		logger:       log,
+		chain:        "1",
+	}
+	fetcher := ethereum.NewFetcher(nil, nil, nil, false, false)
+	statusFor := func(uint64) string { return models.TransferStatusPending }
+	return NewDeadLetterService(repo, fetcher, events, log, 1, statusFor)
+}
+
+func TestListDeadLetters(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    models.DeadLetterQueryParams
+		wantErr   bool
+		wantLimit int
+	}{
+		{name: "defaults", params: models.DeadLetterQueryParams{}, wantLimit: 100},
+		{name: "open rejected", params: models.DeadLetterQueryParams{Status: models.DeadLetterStatusOpen, Kind: models.DeadLetterRejected, Limit: 20}, wantLimit: 20},
+		{name: "limit capped", params: models.DeadLetterQueryParams{Limit: 5000}, wantLimit: 1000},
+		{name: "unknown status", params: models.DeadLetterQueryParams{Status: "closed"}, wantErr: true},
+		{name: "unknown kind", params: models.DeadLetterQueryParams{Kind: "broken"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &deadLetterRepo{writes: &writeLog{}}
+			_, _, err := newTestDeadLetterService(repo).List(context.Background(), tt.params)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Errorf("List() error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if repo.params.Limit != tt.wantLimit {
+				t.Errorf("List() limit = %d, want %d", repo.params.Limit, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestReprocessDeadLetters(t *testing.T) {
+	repo := &deadLetterRepo{
+		writes: &writeLog{},
+		letters: []*models.DeadLetter{
+			testDeadLetter(1, 3, models.DeadLetterRejected, models.DeadLetterStatusOpen),     // Decodes after a parser fix
+			testDeadLetter(2, 2, models.DeadLetterRejected, models.DeadLetterStatusOpen),     // Still malformed
+			testDeadLetter(3, 3, models.DeadLetterRejected, models.DeadLetterStatusResolved), // Already handled
+			testDeadLetter(4, 3, models.DeadLetterDegraded, models.DeadLetterStatusOpen),     // Replaces its stored transfer
+		},
+	}
+	service := newTestDeadLetterService(repo)
+
+	response, err := service.Reprocess(context.Background(), nil, 10)
+	if err != nil {
+		t.Fatalf("Reprocess() error = %v", err)
+	}
+	if repo.params.Status != models.DeadLetterStatusOpen || repo.params.Limit != 10 {
+		t.Errorf("Reprocess() listed %+v, want open dead letters up to 10", repo.params)
+	}
+	if response.Resolved != 2 || response.Failed != 1 {
+		t.Errorf("Reprocess() = %d resolved, %d failed, want 2, 1", response.Resolved, response.Failed)
+	}
+	if len(response.Open) != 1 || response.Open[0].LogIndex != 2 || response.Open[0].Attempts != 1 {
+		t.Errorf("Reprocess() open = %+v, want dead letter 2 after one more attempt", response.Open)
+	}
+
+	// A degraded log's old transfers are deleted before the new ones are stored, and resolved last
+	want := []string{"insert 1", "resolve 1", "fail 2 rejected", "delete 4", "insert 4", "resolve 4"}
+	if !reflect.DeepEqual(repo.writes.calls, want) {
+		t.Errorf("Reprocess() writes = %v, want %v", repo.writes.calls, want)
+	}
+}
+
+func TestReprocessDeadLettersByID(t *testing.T) {
+	repo := &deadLetterRepo{writes: &writeLog{}}
+	service := newTestDeadLetterService(repo)
+
+	id := primitive.NewObjectID()
+	if _, err := service.Reprocess(context.Background(), []string{id.Hex()}, 0); err != nil {
+		t.Fatalf("Reprocess() error = %v", err)
+	}
+	if !reflect.DeepEqual(repo.ids, []primitive.ObjectID{id}) {
+		t.Errorf("Reprocess() looked up %v, want %v", repo.ids, id)
+	}
+
+	if _, err := service.Reprocess(context.Background(), []string{"not-an-id"}, 0); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Reprocess() error = %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/metrics"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+)
+
+// EventStore writes decoded events to their collections and keeps derived state current
+// Shared by live ingestion and backfills so both paths store events identically
+type EventStore struct {
+	repo          repository.Repository
+	nftRepo       repository.NFTRepository
+	approvalRepo  repository.ApprovalRepository
+	supplyRepo    repository.SupplyRepository
+	deadLetters   repository.DeadLetterRepository
+	metadata      *TokenMetadataService
+	logger        *logger.Logger
+	chain         string          // chain_id metrics label
+	riskySpenders map[string]bool // Lowercase spender addresses flagged by the security team
+}
+
+func NewEventStore(
+	repo repository.Repository,
+	nftRepo repository.NFTRepository,
+	approvalRepo repository.ApprovalRepository,
+	supplyRepo repository.SupplyRepository,
+	deadLetters repository.DeadLetterRepository,
+	metadata *TokenMetadataService,
+	logger *logger.Logger,
+	riskySpenders []string,
+) *EventStore {
+	return &EventStore{
+		repo:          repo,
+		nftRepo:       nftRepo,
+		approvalRepo:  approvalRepo,
+		supplyRepo:    supplyRepo,
+		deadLetters:   deadLetters,
+		metadata:      metadata,
+		logger:        logger,
+		chain:         metrics.ChainLabel(repo.ChainID()),
+		riskySpenders: addressSet(riskySpenders),
+	}
+}
+
+// Store stamps every event in result with its finality status and writes it to its collection
+// Every write is idempotent so a failed batch is safely retryable
+// Per-block supply changes are summed from the stored mints and burns after the transfers are written
+// Logs the parsers rejected or degraded are written to dead_letters
+// Transfer amounts are scaled by their token's decimals; a token whose metadata cannot be read keeps
+// the default scale and is rescaled once its metadata is resolved
+func (s *EventStore) Store(ctx context.Context, result *ethereum.FetchResult, statusFor func(blockNumber uint64) string) error {
+	return s.StoreAndCommit(ctx, result, statusFor, nil)
+}
+
+// StoreAndCommit stores result like Store and runs commit in the same transaction as every write,
+// so a checkpoint written by commit never gets ahead of the events of its batch
+// Without transaction support (standalone MongoDB) the same writes run in order without atomicity
+func (s *EventStore) StoreAndCommit(ctx context.Context, result *ethereum.FetchResult, statusFor func(blockNumber uint64) string, commit func(ctx context.Context) error) error {
+	if err := s.metadata.ApplyToTransfers(ctx, result.Transfers); err != nil {
+		return fmt.Errorf("failed to resolve token metadata: %w", err)
+	}
+
+	for _, transfer := range result.Transfers {
+		transfer.Status = statusFor(transfer.BlockNumber)
+	}
+	for _, transfer := range result.NFTTransfers {
+		transfer.Status = statusFor(transfer.BlockNumber)
+	}
+	for _, approval := range result.Approvals {
+		approval.Status = statusFor(approval.BlockNumber)
+	}
+
+	err := s.repo.WithTransaction(ctx, func(txCtx context.Context) error {
+		if len(result.Transfers) > 0 {
+			if err := s.repo.InsertTransfers(txCtx, result.Transfers); err != nil {
+				return fmt.Errorf("failed to insert transfers: %w", err)
+			}
+			if err := s.supplyRepo.RecordSupplyChanges(txCtx, result.Transfers); err != nil {
+				return fmt.Errorf("failed to update token supply: %w", err)
+			}
+		}
+		if err := s.nftRepo.InsertNFTTransfers(txCtx, result.NFTTransfers); err != nil {
+			return fmt.Errorf("failed to insert NFT transfers: %w", err)
+		}
+		if err := s.approvalRepo.InsertApprovals(txCtx, result.Approvals); err != nil {
+			return fmt.Errorf("failed to insert approvals: %w", err)
+		}
+		if err := s.deadLetters.SaveDeadLetters(txCtx, result.DeadLetters); err != nil {
+			return fmt.Errorf("failed to save dead letters: %w", err)
+		}
+		if commit != nil {
+			return commit(txCtx)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, letter := range result.DeadLetters {
+		metrics.DeadLettersTotal.WithLabelValues(s.chain, letter.Kind, letter.Event).Inc()
+		s.logger.Warn("Dead letter for %s log %s:%d in block %d (%s): %s", letter.Event, letter.TxHash, letter.LogIndex, letter.BlockNumber, letter.Kind, letter.Reason)
+	}
+
+	for _, approval := range result.Approvals {
+		if approval.Unlimited && s.IsRiskySpender(approval.Spender) {
+			metrics.RiskyApprovalsTotal.WithLabelValues(s.chain).Inc()
+			s.logger.WithFields("warn", "Unlimited approval to risky spender", map[string]interface{}{
+				"token":   approval.Token,
+				"owner":   approval.Owner,
+				"spender": approval.Spender,
+				"tx_hash": approval.TxHash,
+				"block":   approval.BlockNumber,
+			})
+		}
+	}
+
+	return nil
+}
+
+// IsRiskySpender reports whether a lowercase spender address is on the risky list
+func (s *EventStore) IsRiskySpender(spender string) bool {
+	return s.riskySpenders[spender]
+}
+
+// RiskySpenders returns the configured risky spender addresses
+func (s *EventStore) RiskySpenders() []string {
+	spenders := make([]string, 0, len(s.riskySpenders))
+	for spender := range s.riskySpenders {
+		spenders = append(spenders, spender)
+	}
+	return spenders
+}
+
+// checkBatchHash verifies that every event in blockNumber carries the expected block hash
+// A reorg between the header and log requests would otherwise mix forks in one batch
+func checkBatchHash(result *ethereum.FetchResult, blockNumber uint64, blockHash string) error {
+	hashes := make([]string, 0, result.Len())
+	for _, transfer := range result.Transfers {
+		if transfer.BlockNumber == blockNumber {
+			hashes = append(hashes, transfer.BlockHash)
+		}
+	}
+	for _, transfer := range result.NFTTransfers {
+		if transfer.BlockNumber == blockNumber {
+			hashes = append(hashes, transfer.BlockHash)
+		}
+	}
+	for _, approval := range result.Approvals {
+		if approval.BlockNumber == blockNumber {
+			hashes = append(hashes, approval.BlockHash)
+		}
+	}
+
+	for _, hash := range hashes {
+		if hash != blockHash {
+			return fmt.Errorf("block %d changed while fetching batch (header %s, logs %s)", blockNumber, blockHash, hash)
+		}
+	}
+	return nil
+}
+
+// addressSet lowercases and indexes a list of hex addresses
+func addressSet(addresses []string) map[string]bool {
+	set := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		if normalized, err := normalizeAddress(address); err == nil {
+			set[normalized] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+)
+
+// batchRepo logs every write of a batch, and where its transaction begins and ends
+type batchRepo struct {
+	repository.Repository
+	writes    *writeLog
+	failWrite string // Write that fails, empty for none
+}
+
+func (r *batchRepo) ChainID() uint64 { return 1 }
+
+func (r *batchRepo) write(name string) error {
+	r.writes.add("%s", name)
+	if name == r.failWrite {
+		return errors.New("write conflict")
+	}
+	return nil
+}
+
+func (r *batchRepo) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	r.writes.add("begin")
+	err := fn(ctx)
+	r.writes.add("end")
+	return err
+}
+
+func (r *batchRepo) InsertTransfers(ctx context.Context, transfers []*models.Transfer) error {
+	return r.write("transfers")
+}
+
+// batchEvents logs the derived writes of a batch through the same log as batchRepo
+type batchEvents struct {
+	repository.NFTRepository
+	repository.ApprovalRepository
+	repository.SupplyRepository
+	repository.DeadLetterRepository
+	repo *batchRepo
+}
+
+func (e batchEvents) RecordSupplyChanges(ctx context.Context, transfers []*models.Transfer) error {
+	return e.repo.write("supply")
+}
+
+func (e batchEvents) InsertNFTTransfers(ctx context.Context, transfers []*models.NFTTransfer) error {
+	return e.repo.write("nft_transfers")
+}
+
+func (e batchEvents) InsertApprovals(ctx context.Context, approvals []*models.Approval) error {
+	return e.repo.write("approvals")
+}
+
+func (e batchEvents) SaveDeadLetters(ctx context.Context, letters []*models.DeadLetter) error {
+	return e.repo.write("dead_letters")
+}
+
+func testBatch() *ethereum.FetchResult {
+	return &ethereum.FetchResult{
+		Transfers:    []*models.Transfer{{Token: testUSDC, Standard: models.StandardERC20, ValueString: "1000000", BlockNumber: 10}},
+		NFTTransfers: []*models.NFTTransfer{{BlockNumber: 11}},
+		Approvals:    []*models.Approval{{Token: testUSDC, BlockNumber: 12}},
+		DeadLetters:  []*models.DeadLetter{{Kind: models.DeadLetterRejected, BlockNumber: 12}},
+	}
+}
+
+func TestStoreAndCommit(t *testing.T) {
+	allWrites := []string{"begin", "transfers", "supply", "nft_transfers", "approvals", "dead_letters", "checkpoint", "end"}
+
+	tests := []struct {
+		name      string
+		failWrite string
+		wantErr   bool
+		want      []string
+	}{
+		{name: "checkpoint commits last in the transaction", want: allWrites},
+		{
+			name:      "failed insert skips the checkpoint",
+			failWrite: "nft_transfers",
+			wantErr:   true,
+			want:      []string{"begin", "transfers", "supply", "nft_transfers", "end"},
+		},
+		{name: "failed checkpoint fails the batch", failWrite: "checkpoint", wantErr: true, want: allWrites},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logger.New("error", false, "", "text")
+			repo := &batchRepo{writes: &writeLog{}, failWrite: tt.failWrite}
+			events := batchEvents{repo: repo}
+			metadata := NewTokenMetadataService(nil, &metadataRepo{stored: map[string]*models.TokenMetadata{
+				testUSDC: {Address: testUSDC, Symbol: "USDC", Decimals: 6},
+			}}, nil, log, 16)
+			store := NewEventStore(repo, events, events, events, events, metadata, log, nil)
+
+			// Status is stamped from the block of each event
+			statusFor := func(blockNumber uint64) string {
+				if blockNumber <= 10 {
+					return models.TransferStatusFinalized
+				}
+				return models.TransferStatusPending
+			}
+			result := testBatch()
+			err := store.StoreAndCommit(context.Background(), result, statusFor, func(ctx context.Context) error {
+				return repo.write("checkpoint")
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StoreAndCommit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(repo.writes.calls, tt.want) {
+				t.Errorf("StoreAndCommit() writes = %v, want %v", repo.writes.calls, tt.want)
+			}
+
+			if status := result.Transfers[0].Status; status != models.TransferStatusFinalized {
+				t.Errorf("transfer status = %s, want finalized", status)
+			}
+			if status := result.Approvals[0].Status; status != models.TransferStatusPending {
+				t.Errorf("approval status = %s, want pending", status)
+			}
+			if result.Transfers[0].Symbol != "USDC" {
+				t.Errorf("transfer symbol = %q, want metadata applied before the insert", result.Transfers[0].Symbol)
+			}
+		})
+	}
+}
+
+func TestStoreWithoutTransfers(t *testing.T) {
+	log := logger.New("error", false, "", "text")
+	repo := &batchRepo{writes: &writeLog{}}
+	events := batchEvents{repo: repo}
+	store := NewEventStore(repo, events, events, events, events, NewTokenMetadataService(nil, &metadataRepo{}, nil, log, 16), log, nil)
+
+	if err := store.Store(context.Background(), &ethereum.FetchResult{}, func(uint64) string { return models.TransferStatusPending }); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	// Supply only changes with transfers; the other writes skip empty slices themselves
+	want := []string{"begin", "nft_transfers", "approvals", "dead_letters", "end"}
+	if !reflect.DeepEqual(repo.writes.calls, want) {
+		t.Errorf("Store() writes = %v, want %v", repo.writes.calls, want)
+	}
+}
+
+func TestCheckBatchHash(t *testing.T) {
+	result := &ethereum.FetchResult{
+		Transfers:    []*models.Transfer{{BlockNumber: 9, BlockHash: "0xold"}, {BlockNumber: 10, BlockHash: "0xa"}},
+		NFTTransfers: []*models.NFTTransfer{{BlockNumber: 10, BlockHash: "0xa"}},
+		Approvals:    []*models.Approval{{BlockNumber: 10, BlockHash: "0xa"}},
+	}
+	if err := checkBatchHash(result, 10, "0xa"); err != nil {
+		t.Errorf("checkBatchHash() error = %v, want nil", err)
+	}
+	if err := checkBatchHash(result, 10, "0xb"); err == nil {
+		t.Errorf("checkBatchHash() error = nil, want a changed block")
+	}
+	result.Approvals[0].BlockHash = "0xb"
+	if err := checkBatchHash(result, 10, "0xa"); err == nil {
+		t.Errorf("checkBatchHash() error = nil, want the approval from another branch rejected")
+	}
+	if err := checkBatchHash(&ethereum.FetchResult{}, 10, "0xa"); err != nil {
+		t.Errorf("checkBatchHash() of an empty batch error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/metrics"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// headStallTimeout is how long a subscription may stay silent before it is treated as dead
+	// Some providers keep the socket open but stop pushing heads
+	headStallTimeout = 2 * time.Minute
+
+	headResubscribeMin = 1 * time.Second
+	headResubscribeMax = 1 * time.Minute
+)
+
+// followHeads wakes ingestion whenever a websocket provider pushes a new head
+// Broken or stalled subscriptions are retried with backoff, failing over across the pool;
+// while no subscription is active the poll ticker drives ingestion instead
+func (s *IngestionService) followHeads(ctx context.Context, wake chan<- struct{}) {
+	backoff := headResubscribeMin
+	polling := false
+	defer s.setFollowingHeads(false)
+
+	for {
+		headers := make(chan *types.Header, 16)
+		sub, err := s.ethereumClient.SubscribeNewHead(ctx, headers)
+		if err != nil {
+			s.setFollowingHeads(false)
+			if !polling {
+				polling = true
+				s.logger.Warn("No new heads subscription, polling every %s: %v", s.pollInterval, err)
+			} else if !errors.Is(err, ethereum.ErrNoWebsocketProvider) {
+				s.logger.Debug("Failed to subscribe to new heads: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, headResubscribeMax)
+			continue
+		}
+
+		s.setFollowingHeads(true)
+		polling = false
+		s.logger.Info("Following new heads pushed by provider %s", sub.Provider)
+
+		received, err := s.receiveHeads(ctx, sub, headers, wake)
+		if err == nil {
+			return
+		}
+		sub.Fail(err)
+		s.logger.Warn("New heads subscription on provider %s ended: %v", sub.Provider, err)
+
+		// Only a subscription that delivered heads resets the backoff, so one that
+		// breaks right after subscribing is not retried in a tight loop
+		if received {
+			backoff = headResubscribeMin
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, headResubscribeMax)
+	}
+}
+
+// receiveHeads forwards heads from one subscription until it fails, stalls or ctx is cancelled
+// Reports whether any head was received; the error is nil only when ctx is cancelled
+func (s *IngestionService) receiveHeads(ctx context.Context, sub *ethereum.HeadSubscription, headers <-chan *types.Header, wake chan<- struct{}) (bool, error) {
+	stall := time.NewTimer(headStallTimeout)
+	defer stall.Stop()
+
+	received := false
+	for {
+		select {
+		case <-ctx.Done():
+			sub.Unsubscribe()
+			return received, nil
+		case err := <-sub.Err():
+			if err == nil {
+				err = fmt.Errorf("subscription closed")
+			}
+			return received, err
+		case <-stall.C:
+			return received, fmt.Errorf("no new head for %s", headStallTimeout)
+		case <-headers:
+			received = true
+			stall.Reset(headStallTimeout)
+			// A pending wake-up already covers this head
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// setFollowingHeads records whether pushed heads drive ingestion
+func (s *IngestionService) setFollowingHeads(following bool) {
+	s.followingHeads.Store(following)
+	value := 0.0
+	if following {
+		value = 1
+	}
+	metrics.HeadSubscriptionActive.WithLabelValues(s.chain).Set(value)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"pagrin/internal/ethereum"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeSubscription is a head subscription whose failure the test controls
+type fakeSubscription struct {
+	errs         chan error
+	unsubscribed bool
+}
+
+func (s *fakeSubscription) Err() <-chan error { return s.errs }
+func (s *fakeSubscription) Unsubscribe()      { s.unsubscribed = true }
+
+func TestReceiveHeads(t *testing.T) {
+	head := &types.Header{Number: big.NewInt(1)}
+	service := &IngestionService{}
+
+	t.Run("coalesces wake-ups until the subscription fails", func(t *testing.T) {
+		sub := &fakeSubscription{errs: make(chan error, 1)}
+		headers := make(chan *types.Header)
+		wake := make(chan struct{}, 1)
+
+		done := make(chan struct{})
+		var received bool
+		var err error
+		go func() {
+			defer close(done)
+			received, err = service.receiveHeads(context.Background(), &ethereum.HeadSubscription{Subscription: sub}, headers, wake)
+		}()
+
+		for i := 0; i < 3; i++ {
+			headers <- head
+		}
+		sub.errs <- errors.New("connection reset")
+		<-done
+
+		if !received || err == nil {
+			t.Errorf("receiveHeads() = %v, %v, want received heads and the subscription error", received, err)
+		}
+		if len(wake) != 1 {
+			t.Errorf("%d pending wake-ups, want 1", len(wake))
+		}
+	})
+
+	t.Run("closed subscription", func(t *testing.T) {
+		sub := &fakeSubscription{errs: make(chan error, 1)}
+		close(sub.errs)
+
+		received, err := service.receiveHeads(context.Background(), &ethereum.HeadSubscription{Subscription: sub}, make(chan *types.Header), make(chan struct{}, 1))
+		if received || err == nil {
+			t.Errorf("receiveHeads() = %v, %v, want no heads and an error", received, err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		sub := &fakeSubscription{errs: make(chan error)}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		received, err := service.receiveHeads(ctx, &ethereum.HeadSubscription{Subscription: sub}, make(chan *types.Header), make(chan struct{}, 1))
+		if received || err != nil {
+			t.Errorf("receiveHeads() = %v, %v, want no heads and no error", received, err)
+		}
+		if !sub.unsubscribed {
+			t.Errorf("subscription left open after cancellation")
+		}
+	})
+}
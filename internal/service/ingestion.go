@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"pagrin/internal/ethereum"
 	"pagrin/internal/metrics"
+	"pagrin/internal/models"
 	"pagrin/internal/repository"
 	"pagrin/pkg/logger"
 )
@@ -15,18 +17,59 @@ import (
 // StreamPublisher interface for publishing events to stream
 type StreamPublisher interface {
 	Publish(transfer interface{})
+	PublishRemoved(transfer interface{})
 }
 
+// Ingestion modes, exposed as the mode label of eth_ingestion_mode
+const (
+	IngestionModeCatchingUp = "catching_up" // Behind head: batches run back to back
+	IngestionModeAtTip      = "at_tip"      // At head: a batch runs per new block
+)
+
+// Adaptive batch size strategies, selected by BATCH_STRATEGY
+const (
+	BatchStrategyStreak = "streak" // Double after a streak of successes, divide on failure
+	BatchStrategyAIMD   = "aimd"   // Grow additively while batches meet the duration and log targets, shrink multiplicatively
+)
+
+// promotionInterval is how often the safe and finalized heads are checked to promote stored transfers
+const promotionInterval = 12 * time.Second
+
 type IngestionService struct {
 	ethereumClient  *ethereum.Client
 	fetcher         *ethereum.Fetcher
 	repo            repository.Repository
+	controlRepo     repository.ControlRepository
+	events          *EventStore
 	logger          *logger.Logger
+	chain           string // chain_id metrics label
 	pollInterval    time.Duration
 	startBlock      uint64
 	blockBatchSize  uint64
 	resetStartBlock bool
+	reorgMaxDepth   uint64          // Maximum blocks to walk back when searching for a common ancestor
 	stream          StreamPublisher // Optional stream for real-time events
+	pipelineDepth   int             // Batches queued between pipeline stages
+	followingHeads  atomic.Bool     // Pushed new heads drive ingestion; polling is the fallback
+	running         atomic.Bool     // Start is running on this replica
+
+	// Catch-up state, guarded by mu
+	headBlock     uint64    // Indexing head seen by the last batch
+	mode          string    // IngestionModeCatchingUp or IngestionModeAtTip
+	lastBlock     uint64    // Block at the checkpoint this replica last moved
+	lastBlockTime time.Time // Timestamp of lastBlock
+
+	// Admin control state, guarded by mu
+	paused         bool
+	batchSizeSetAt time.Time // When the last applied admin batch size was requested
+
+	// Finality state
+	confirmationDepth uint64            // Blocks to stay behind the indexing head
+	indexingMode      ethereum.BlockTag // Head tag that bounds ingestion (latest, safe, finalized)
+	safeBlock         uint64            // Highest block promoted to safe
+	finalizedBlock    uint64            // Highest block promoted to finalized
+	safeFrom          uint64            // First block of the last safe promotion
+	finalizedFrom     uint64            // First block of the last finalized promotion
 
 	// Adaptive batch size state
 	adaptiveBatch       bool
@@ -34,6 +77,8 @@ type IngestionService struct {
 	batchMaxSize        uint64
 	batchSuccessStreak  int
 	batchFailureBackoff int
+	batchStrategy       string              // BatchStrategyStreak or BatchStrategyAIMD
+	aimd                ethereum.AIMDConfig // Targets and steps of BatchStrategyAIMD
 	currentBatchSize    uint64
 	successCount        int
 	failureCount        int
@@ -44,6 +89,8 @@ func NewIngestionService(
 	ethereumClient *ethereum.Client,
 	fetcher *ethereum.Fetcher,
 	repo repository.Repository,
+	controlRepo repository.ControlRepository,
+	events *EventStore,
 	logger *logger.Logger,
 	pollInterval time.Duration,
 	startBlock uint64,
@@ -54,6 +101,12 @@ func NewIngestionService(
 	batchMaxSize uint64,
 	batchSuccessStreak int,
 	batchFailureBackoff int,
+	batchStrategy string,
+	aimd ethereum.AIMDConfig,
+	reorgMaxDepth uint64,
+	confirmationDepth uint64,
+	indexingMode ethereum.BlockTag,
+	pipelineDepth int,
 	stream StreamPublisher,
 ) *IngestionService {
 	// Initialize current batch size to configured starting size
@@ -66,17 +119,26 @@ func NewIngestionService(
 		ethereumClient:      ethereumClient,
 		fetcher:             fetcher,
 		repo:                repo,
+		controlRepo:         controlRepo,
+		events:              events,
 		logger:              logger,
+		chain:               metrics.ChainLabel(repo.ChainID()),
 		pollInterval:        pollInterval,
 		startBlock:          startBlock,
 		blockBatchSize:      blockBatchSize,
 		resetStartBlock:     resetStartBlock,
+		reorgMaxDepth:       reorgMaxDepth,
+		confirmationDepth:   confirmationDepth,
+		indexingMode:        indexingMode,
 		stream:              stream,
+		pipelineDepth:       pipelineDepth,
 		adaptiveBatch:       adaptiveBatch,
 		batchMinSize:        batchMinSize,
 		batchMaxSize:        batchMaxSize,
 		batchSuccessStreak:  batchSuccessStreak,
 		batchFailureBackoff: batchFailureBackoff,
+		batchStrategy:       batchStrategy,
+		aimd:                aimd,
 		currentBatchSize:    currentSize,
 		successCount:        0,
 		failureCount:        0,
@@ -89,6 +151,8 @@ func (s *IngestionService) Start(ctx context.Context) error {
 	if s.resetStartBlock {
 		s.logger.Info("RESET_START_BLOCK enabled, starting from configured START_BLOCK: %d", s.startBlock)
 		currentBlock = s.startBlock
+		// Only the first start resets; a replica re-elected as leader resumes from the checkpoint
+		s.resetStartBlock = false
 	} else {
 		lastBlock, err := s.repo.GetLastProcessedBlock(ctx)
 		if err != nil {
@@ -105,6 +169,21 @@ func (s *IngestionService) Start(ctx context.Context) error {
 	}
 
 	s.logger.Info("Starting ingestion from block %d", currentBlock)
+	s.running.Store(true)
+	defer s.running.Store(false)
+	metrics.IngestionBatchSize.WithLabelValues(s.chain).Set(float64(s.batchSize()))
+
+	// Statuses of new transfers follow the promoted heads, so promote once before the first batch
+	s.promoteTransfers(ctx)
+	go s.runPromotions(ctx)
+
+	// Websocket providers push new heads; without one, ingestion polls
+	wake := make(chan struct{}, 1)
+	if s.ethereumClient.SupportsHeadSubscription() {
+		go s.followHeads(ctx, wake)
+	} else {
+		s.logger.Info("No websocket provider, polling for new blocks every %s", s.pollInterval)
+	}
 
 	ticker := time.NewTicker(s.pollInterval)
 	defer ticker.Stop()
@@ -114,113 +193,469 @@ func (s *IngestionService) Start(ctx context.Context) error {
 		case <-ctx.Done():
 			s.logger.Info("Ingestion stopped")
 			return nil
+		case <-wake:
 		case <-ticker.C:
-			nextBlock, err := s.processBlocks(ctx, currentBlock)
+			if s.followingHeads.Load() {
+				continue
+			}
+		}
+
+		// While catching up, the pipeline runs batches back to back until the checkpoint reaches the head
+		// A failed batch (provider error or rate limit) waits for the next wake-up instead
+		// Admin requests are applied between pipeline runs; a paused chain waits for the next wake-up
+		for ctx.Err() == nil {
+			nextBlock, paused, err := s.applyControl(ctx, currentBlock)
 			if err != nil {
-				metrics.IngestionErrorsTotal.WithLabelValues("processing").Inc()
+				metrics.IngestionErrorsTotal.WithLabelValues(s.chain, "control").Inc()
+				s.logger.Error("Failed to apply admin request: %v", err)
+				break
+			}
+			currentBlock = nextBlock
+			if paused {
+				break
+			}
+
+			nextBlock, err = s.runPipeline(ctx, currentBlock)
+			if err != nil {
+				metrics.IngestionErrorsTotal.WithLabelValues(s.chain, "processing").Inc()
 				s.logger.Error("Failed to process blocks: %v", err)
-				continue
+				break
 			}
 			currentBlock = nextBlock
+
+			if !s.recordHeadLag(currentBlock) {
+				break
+			}
 		}
 	}
 }
 
-func (s *IngestionService) processBlocks(ctx context.Context, fromBlock uint64) (uint64, error) {
-	start := time.Now()
-	defer func() {
-		metrics.TransfersProcessingDuration.WithLabelValues("block_processing").Observe(time.Since(start).Seconds())
-	}()
+// recordHeadLag updates the mode and lag metrics after a batch and reports whether ingestion is catching up
+// Ingestion is at tip once the checkpoint has reached the last seen indexing head
+func (s *IngestionService) recordHeadLag(nextBlock uint64) bool {
+	s.mu.Lock()
+	var lag uint64
+	if s.headBlock >= nextBlock {
+		lag = s.headBlock - nextBlock + 1
+	}
+	mode := IngestionModeAtTip
+	if lag > 0 {
+		mode = IngestionModeCatchingUp
+	}
+	changed := mode != s.mode
+	s.mode = mode
+	lastBlockTime := s.lastBlockTime
+	s.mu.Unlock()
+
+	metrics.HeadLagBlocks.WithLabelValues(s.chain).Set(float64(lag))
+	if !lastBlockTime.IsZero() {
+		metrics.HeadLagSeconds.WithLabelValues(s.chain).Set(time.Since(lastBlockTime).Seconds())
+	}
+	if changed {
+		for _, m := range []string{IngestionModeCatchingUp, IngestionModeAtTip} {
+			value := 0.0
+			if m == mode {
+				value = 1
+			}
+			metrics.IngestionMode.WithLabelValues(s.chain, m).Set(value)
+		}
+		if mode == IngestionModeCatchingUp {
+			s.logger.Info("Catching up: %d blocks behind head, ingesting batches back to back", lag)
+		} else {
+			s.logger.Info("Reached head at block %d, following new blocks", nextBlock-1)
+		}
+	}
+
+	return mode == IngestionModeCatchingUp
+}
+
+// recordCheckpoint remembers the block the checkpoint moved to for the lag in seconds
+func (s *IngestionService) recordCheckpoint(block uint64, blockTime time.Time) {
+	s.mu.Lock()
+	s.lastBlock = block
+	s.lastBlockTime = blockTime
+	s.mu.Unlock()
+
+	metrics.LastProcessedBlock.WithLabelValues(s.chain).Set(float64(block))
+}
+
+// batchSize returns the number of blocks to plan the next batch with (may be adjusted by adaptive logic)
+func (s *IngestionService) batchSize() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.currentBatchSize > 0 {
+		return s.currentBatchSize
+	}
+	if s.blockBatchSize > 0 {
+		return s.blockBatchSize
+	}
+	return 10
+}
+
+// BatchSizeLimits returns the bounds adaptive batching keeps the batch size within
+func (s *IngestionService) BatchSizeLimits() (uint64, uint64) {
+	return s.batchMinSize, s.batchMaxSize
+}
+
+// AdaptiveBatch reports whether the batch size adapts to successes and failures
+func (s *IngestionService) AdaptiveBatch() bool {
+	return s.adaptiveBatch
+}
+
+// BatchStrategy returns how the batch size adapts, empty when it is fixed
+func (s *IngestionService) BatchStrategy() string {
+	if !s.adaptiveBatch {
+		return ""
+	}
+	return s.batchStrategy
+}
+
+// applyControl applies the chain's admin requests before a pipeline run
+// A pending rewind moves the checkpoint and returns the block after it; paused reports whether to wait
+func (s *IngestionService) applyControl(ctx context.Context, currentBlock uint64) (uint64, bool, error) {
+	control, err := s.controlRepo.GetIngestionControl(ctx)
+	if err != nil {
+		return currentBlock, false, err
+	}
+	if control == nil {
+		return currentBlock, false, nil
+	}
+
+	s.applyBatchSize(control)
+
+	if control.Rewind != nil {
+		if err := s.rewind(ctx, control.Rewind); err != nil {
+			return currentBlock, false, err
+		}
+		currentBlock = control.Rewind.ToBlock + 1
+	}
 
-	// Add timeout to prevent hanging
-	processCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
-	defer cancel()
+	s.setPaused(control.Paused, control.PauseReason)
+	return currentBlock, control.Paused, nil
+}
 
-	latestBlock, err := s.ethereumClient.GetLatestBlockNumber(processCtx)
+// controlInterrupts applies a new admin batch size and reports whether a pause or rewind is pending
+// Checked between batches so a long catch-up stops for them after the batches in flight are stored
+func (s *IngestionService) controlInterrupts(ctx context.Context) bool {
+	control, err := s.controlRepo.GetIngestionControl(ctx)
 	if err != nil {
-		return fromBlock, fmt.Errorf("failed to get latest block: %w", err)
+		s.logger.Warn("Failed to check admin requests: %v", err)
+		return false
 	}
+	if control == nil {
+		return false
+	}
+
+	s.applyBatchSize(control)
+	return control.Paused || control.Rewind != nil
+}
 
-	if fromBlock > latestBlock {
-		return fromBlock, nil
+// applyBatchSize switches to an admin batch size the first time it is seen
+func (s *IngestionService) applyBatchSize(control *models.IngestionControl) {
+	if control.BatchSize == 0 || control.BatchSizeSetAt == nil {
+		return
 	}
 
-	// Get current batch size (may be adjusted by adaptive logic)
 	s.mu.Lock()
-	batchSize := s.currentBatchSize
-	if batchSize == 0 {
-		batchSize = s.blockBatchSize
-		if batchSize == 0 {
-			batchSize = 10
-		}
+	defer s.mu.Unlock()
+
+	if control.BatchSizeSetAt.Equal(s.batchSizeSetAt) {
+		return
+	}
+	s.batchSizeSetAt = *control.BatchSizeSetAt
+	if control.BatchSize == s.currentBatchSize {
+		return
 	}
+
+	s.logger.Info("Changing batch size from %d to %d as requested by %s", s.currentBatchSize, control.BatchSize, control.UpdatedBy)
+	s.currentBatchSize = control.BatchSize
+	s.successCount = 0
+	s.failureCount = 0
+	metrics.IngestionBatchSize.WithLabelValues(s.chain).Set(float64(s.currentBatchSize))
+}
+
+// rewind moves the checkpoint back as requested, recording the canonical hash of the target block
+// Safe to repeat: the request is only cleared once the checkpoint has moved
+func (s *IngestionService) rewind(ctx context.Context, rewind *models.IngestionRewind) error {
+	header, err := s.ethereumClient.GetBlockHeader(ctx, rewind.ToBlock)
+	if err != nil {
+		return fmt.Errorf("failed to get header of rewind block %d: %w", rewind.ToBlock, err)
+	}
+
+	purged, err := s.controlRepo.RewindToBlock(ctx, rewind.ToBlock, rewind.Purge)
+	if err != nil {
+		return fmt.Errorf("failed to rewind to block %d: %w", rewind.ToBlock, err)
+	}
+	if err := s.repo.SetLastProcessedBlock(ctx, rewind.ToBlock, header.Hash().Hex(), header.ParentHash.Hex()); err != nil {
+		return fmt.Errorf("failed to set last processed block: %w", err)
+	}
+	if err := s.controlRepo.CompleteIngestionRewind(ctx, rewind.RequestedAt); err != nil {
+		return err
+	}
+	s.recordCheckpoint(rewind.ToBlock, time.Unix(int64(header.Time), 0))
+
+	s.logger.WithFields("warn", "Rewound checkpoint", map[string]interface{}{
+		"to_block":     rewind.ToBlock,
+		"purge":        rewind.Purge,
+		"purged":       purged,
+		"requested_by": rewind.RequestedBy,
+	})
+	s.logger.Warn("Rewound checkpoint to block %d as requested by %s (purged transfers: %d)", rewind.ToBlock, rewind.RequestedBy, purged)
+	return nil
+}
+
+// setPaused records whether ingestion is paused, logging changes
+func (s *IngestionService) setPaused(paused bool, reason string) {
+	s.mu.Lock()
+	changed := paused != s.paused
+	s.paused = paused
 	s.mu.Unlock()
 
-	toBlock := fromBlock + batchSize - 1
-	if toBlock > latestBlock {
-		toBlock = latestBlock
+	if paused {
+		metrics.IngestionPaused.WithLabelValues(s.chain).Set(1)
+	} else {
+		metrics.IngestionPaused.WithLabelValues(s.chain).Set(0)
+	}
+	if !changed {
+		return
+	}
+	if paused {
+		s.logger.Warn("Ingestion paused by admin request (reason: %q)", reason)
+	} else {
+		s.logger.Info("Ingestion resumed by admin request")
 	}
+}
 
-	s.logger.Debug("Fetching transfers from blocks %d-%d (batch size: %d)", fromBlock, toBlock, batchSize)
-	transfers, err := s.fetcher.FetchTransferLogs(processCtx, fromBlock, toBlock)
+// indexingHead returns the highest block the ingestion loop may index
+// Resolves the configured head tag and applies the confirmation depth on top of it
+func (s *IngestionService) indexingHead(ctx context.Context) (uint64, error) {
+	head, err := s.ethereumClient.GetBlockNumberByTag(ctx, s.indexingMode)
 	if err != nil {
-		// Record failure and adjust batch size if adaptive mode is enabled
-		if s.adaptiveBatch {
-			s.adjustBatchSizeOnFailure()
+		return 0, err
+	}
+
+	if head < s.confirmationDepth {
+		return 0, nil
+	}
+	return head - s.confirmationDepth, nil
+}
+
+// runPromotions promotes transfers every promotionInterval until ctx is cancelled
+// The safe and finalized heads only move once per epoch, so they are not queried for every batch
+func (s *IngestionService) runPromotions(ctx context.Context) {
+	ticker := time.NewTicker(promotionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.promoteTransfers(ctx)
 		}
-		return fromBlock, fmt.Errorf("failed to fetch transfer logs: %w", err)
+	}
+}
+
+// promoteTransfers moves stored transfers to safe/finalized as the chain's safe and finalized heads advance
+// Transfers whose status changed are re-published once the update succeeds, so stream clients filtering
+// on status receive them; a failed update is retried on the next tick without publishing twice
+// Providers without tag support simply never promote
+func (s *IngestionService) promoteTransfers(ctx context.Context) {
+	promotions := []struct {
+		tag    ethereum.BlockTag
+		status string
+		block  *uint64
+		from   *uint64
+	}{
+		{ethereum.BlockTagSafe, models.TransferStatusSafe, &s.safeBlock, &s.safeFrom},
+		{ethereum.BlockTagFinalized, models.TransferStatusFinalized, &s.finalizedBlock, &s.finalizedFrom},
 	}
 
-	if len(transfers) > 0 {
-		if err := s.repo.InsertTransfers(ctx, transfers); err != nil {
-			// Insert failure - adjust batch size if adaptive
-			if s.adaptiveBatch {
-				s.adjustBatchSizeOnFailure()
+	for _, p := range promotions {
+		head, err := s.ethereumClient.GetBlockNumberByTag(ctx, p.tag)
+		if err != nil {
+			s.logger.Debug("Skipping %s promotion: %v", p.status, err)
+			continue
+		}
+
+		// The head is published before the update so transfers stamped from now on carry the new status;
+		// it is lowered again if the promotion fails and retried on the next tick
+		s.mu.Lock()
+		previous := *p.block
+		if head <= previous {
+			s.mu.Unlock()
+			continue
+		}
+		*p.block = head
+		// A transfer stamped just before the head moved may be inserted after the update below,
+		// so each promotion also re-covers the range of the one before it
+		fromBlock := previous + 1
+		if *p.from > 0 {
+			fromBlock = min(*p.from, fromBlock)
+		}
+		s.mu.Unlock()
+
+		rollback := func() {
+			s.mu.Lock()
+			if *p.block == head {
+				*p.block = previous
 			}
-			return fromBlock, fmt.Errorf("failed to insert transfers: %w", err)
+			s.mu.Unlock()
 		}
-		metrics.TransfersProcessedTotal.WithLabelValues("success").Add(float64(len(transfers)))
-		// Log with structured fields for better observability
-		s.logger.WithFields("info", "Processed transfers", map[string]interface{}{
-			"transfers":  len(transfers),
-			"from_block": fromBlock,
-			"to_block":   toBlock,
-			"batch_size": batchSize,
-			"elapsed_ms": time.Since(start).Milliseconds(),
-		})
-		s.logger.Info("Processed %d transfers from blocks %d-%d (batch: %d)", len(transfers), fromBlock, toBlock, batchSize)
-
-		// Publish transfers to stream if enabled
-		// Check if stream interface is properly initialized (not nil)
-		if s.stream != nil {
+
+		// The first promotion after startup catches up on history without replaying it to the stream
+		var promoted []*models.Transfer
+		if s.stream != nil && previous > 0 {
+			transfers, err := s.repo.GetTransfersByBlockRange(ctx, fromBlock, head)
+			if err != nil {
+				s.logger.Warn("Failed to load transfers for %s promotion: %v", p.status, err)
+				rollback()
+				continue
+			}
 			for _, transfer := range transfers {
-				if transfer != nil {
-					s.stream.Publish(transfer)
+				if transfer.Status == models.TransferStatusFinalized || transfer.Status == p.status {
+					continue
 				}
+				transfer.Status = p.status
+				promoted = append(promoted, transfer)
 			}
 		}
-	} else {
-		s.logger.Debug("No transfers found in blocks %d-%d", fromBlock, toBlock)
+
+		updated, err := s.repo.SetTransferStatus(ctx, p.status, fromBlock, head)
+		if err != nil {
+			s.logger.Warn("Failed to promote transfers to %s: %v", p.status, err)
+			rollback()
+			continue
+		}
+		for _, transfer := range promoted {
+			s.stream.Publish(transfer)
+		}
+
+		// The startup catch-up runs before ingestion, so nothing of its range needs re-covering
+		s.mu.Lock()
+		*p.from = previous + 1
+		if previous == 0 {
+			*p.from = head + 1
+		}
+		s.mu.Unlock()
+
+		// Headers past finality cannot change, so the header cache keeps them indefinitely
+		if p.tag == ethereum.BlockTagFinalized {
+			s.fetcher.SetFinalizedBlock(head)
+		}
+
+		if updated > 0 {
+			metrics.TransfersProcessedTotal.WithLabelValues(s.chain, p.status).Add(float64(updated))
+			s.logger.Debug("Promoted %d transfers to %s up to block %d", updated, p.status, head)
+		}
 	}
+}
+
+// StatusFor returns the finality status a newly ingested transfer at blockNumber should carry
+func (s *IngestionService) StatusFor(blockNumber uint64) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if err := s.repo.SetLastProcessedBlock(ctx, toBlock); err != nil {
-		return fromBlock, fmt.Errorf("failed to set last processed block: %w", err)
+	if blockNumber <= s.finalizedBlock {
+		return models.TransferStatusFinalized
+	}
+	if blockNumber <= s.safeBlock {
+		return models.TransferStatusSafe
 	}
+	return models.TransferStatusPending
+}
 
-	metrics.BlocksProcessedTotal.Add(float64(toBlock - fromBlock + 1))
+// checkReorg compares the parent hash of fromBlock against the hash recorded for fromBlock-1
+// On mismatch it rolls back to the common ancestor and returns it with reorged=true
+// Checkpoints without a stored hash (legacy records or fresh starts) are trusted as-is
+func (s *IngestionService) checkReorg(ctx context.Context, fromBlock uint64) (uint64, bool, error) {
+	if fromBlock == 0 {
+		return 0, false, nil
+	}
 
-	// Record success and adjust batch size if adaptive mode is enabled
-	if s.adaptiveBatch {
-		s.adjustBatchSizeOnSuccess()
+	parent, err := s.repo.GetProcessedBlock(ctx, fromBlock-1)
+	if err != nil {
+		return 0, false, err
+	}
+	if parent == nil || parent.BlockHash == "" {
+		return 0, false, nil
+	}
+
+	header, err := s.ethereumClient.GetBlockHeader(ctx, fromBlock)
+	if err != nil {
+		return 0, false, err
+	}
+	if header.ParentHash.Hex() == parent.BlockHash {
+		return 0, false, nil
+	}
+
+	s.logger.Warn("Reorg detected at block %d: expected parent %s, got %s", fromBlock, parent.BlockHash, header.ParentHash.Hex())
+
+	ancestor, err := s.findCommonAncestor(ctx, fromBlock-1)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to find common ancestor: %w", err)
+	}
+
+	removed, err := s.repo.RollbackToBlock(ctx, ancestor)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to roll back to block %d: %w", ancestor, err)
+	}
+	s.fetcher.InvalidateBlocks(ctx, ancestor+1, fromBlock-1)
+
+	depth := fromBlock - 1 - ancestor
+	metrics.ReorgsDetectedTotal.WithLabelValues(s.chain).Inc()
+	metrics.ReorgDepth.WithLabelValues(s.chain).Observe(float64(depth))
+	metrics.TransfersProcessedTotal.WithLabelValues(s.chain, "rolled_back").Add(float64(len(removed)))
+
+	s.logger.WithFields("warn", "Rolled back reorged blocks", map[string]interface{}{
+		"ancestor":  ancestor,
+		"depth":     depth,
+		"transfers": len(removed),
+	})
+	s.logger.Warn("Rolled back to common ancestor %d (depth: %d, removed transfers: %d)", ancestor, depth, len(removed))
+
+	if s.stream != nil {
+		for _, transfer := range removed {
+			if transfer != nil {
+				s.stream.PublishRemoved(transfer)
+			}
+		}
+	}
+
+	return ancestor, true, nil
+}
+
+// findCommonAncestor walks back through recorded checkpoints until one matches the canonical chain
+// Falls back to rewinding the full reorg depth if no recorded hash matches
+func (s *IngestionService) findCommonAncestor(ctx context.Context, forkBlock uint64) (uint64, error) {
+	var lowest uint64
+	if forkBlock > s.reorgMaxDepth {
+		lowest = forkBlock - s.reorgMaxDepth
+	}
+
+	checkpoints, err := s.repo.GetProcessedBlocks(ctx, lowest, forkBlock)
+	if err != nil {
+		return 0, err
 	}
 
-	// Check if processing took longer than poll interval (back-pressure indicator)
-	processingTime := time.Since(start)
-	if processingTime > s.pollInterval {
-		s.logger.Warn("Batch processing took %v (longer than poll interval %v) - consider reducing batch size", processingTime, s.pollInterval)
+	for _, checkpoint := range checkpoints {
+		if checkpoint.BlockHash == "" {
+			// Legacy record without a hash - cannot verify, treat as ancestor
+			return checkpoint.BlockNumber, nil
+		}
+
+		header, err := s.ethereumClient.GetBlockHeader(ctx, checkpoint.BlockNumber)
+		if err != nil {
+			return 0, err
+		}
+		if header.Hash().Hex() == checkpoint.BlockHash {
+			return checkpoint.BlockNumber, nil
+		}
 	}
 
-	return toBlock + 1, nil
+	s.logger.Warn("No common ancestor found within %d blocks of %d, rewinding to %d", s.reorgMaxDepth, forkBlock, lowest)
+	return lowest, nil
 }
 
 // adjustBatchSizeOnSuccess increases batch size after successful streaks
@@ -242,6 +677,7 @@ func (s *IngestionService) adjustBatchSizeOnSuccess() {
 			s.logger.Info("Increasing batch size from %d to %d (success streak: %d)", s.currentBatchSize, newSize, s.successCount)
 			s.currentBatchSize = newSize
 			s.successCount = 0 // Reset counter after adjustment
+			metrics.IngestionBatchSize.WithLabelValues(s.chain).Set(float64(newSize))
 		}
 	}
 }
@@ -257,6 +693,9 @@ func (s *IngestionService) adjustBatchSizeOnFailure() {
 
 	// Decrease batch size on failure
 	newSize := s.currentBatchSize / uint64(s.batchFailureBackoff)
+	if s.batchStrategy == BatchStrategyAIMD {
+		newSize = uint64(float64(s.currentBatchSize) * s.aimd.Decrease)
+	}
 	if newSize < s.batchMinSize {
 		newSize = s.batchMinSize
 	}
@@ -264,5 +703,44 @@ func (s *IngestionService) adjustBatchSizeOnFailure() {
 		s.logger.Warn("Decreasing batch size from %d to %d (failure count: %d)", s.currentBatchSize, newSize, s.failureCount)
 		s.currentBatchSize = newSize
 		s.failureCount = 0 // Reset counter after adjustment
+		metrics.IngestionBatchSize.WithLabelValues(s.chain).Set(float64(newSize))
+	}
+}
+
+// adjustBatchSizeAIMD sizes the next batch from how long this one took to fetch and how many logs it returned
+// A batch that filled the current size within both targets adds the configured increase; one over either
+// target shrinks by the decrease factor. The size stays within the widest range a healthy provider has
+// learned to accept, so a batch normally goes out as a single eth_getLogs request
+func (s *IngestionService) adjustBatchSizeAIMD(blocks uint64, logs int, duration time.Duration) {
+	var widest uint64
+	if pool := s.ethereumClient.GetPool(); pool != nil {
+		widest = pool.LogRangeLimit()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	newSize := s.currentBatchSize
+	over := duration > s.aimd.TargetDuration || logs > s.aimd.TargetLogs
+	switch {
+	case over:
+		newSize = uint64(float64(min(blocks, s.currentBatchSize)) * s.aimd.Decrease)
+	case blocks >= s.currentBatchSize:
+		newSize += s.aimd.Increase
+	}
+	if widest > 0 {
+		newSize = min(newSize, widest)
+	}
+	newSize = max(min(newSize, s.batchMaxSize), s.batchMinSize)
+	if newSize == s.currentBatchSize {
+		return
+	}
+
+	if over {
+		s.logger.Info("Decreasing batch size from %d to %d (%d logs in %s)", s.currentBatchSize, newSize, logs, duration)
+	} else {
+		s.logger.Debug("Changing batch size from %d to %d (%d logs in %s)", s.currentBatchSize, newSize, logs, duration)
 	}
+	s.currentBatchSize = newSize
+	metrics.IngestionBatchSize.WithLabelValues(s.chain).Set(float64(newSize))
 }
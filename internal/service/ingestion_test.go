@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/metrics"
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// fakeChain answers eth_getBlockByNumber for tagged heads; unknown tags fail like unsupported providers
+type fakeChain struct {
+	mu    sync.Mutex
+	heads map[string]uint64
+}
+
+func (c *fakeChain) setHead(tag string, number uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.heads[tag] = number
+}
+
+// newFakeChain starts a JSON-RPC server and returns a client connected to it
+func newFakeChain(t *testing.T) (*fakeChain, *ethereum.Client) {
+	t.Helper()
+	chain := &fakeChain{heads: make(map[string]uint64)}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		response := map[string]any{"jsonrpc": "2.0", "id": request.ID}
+		var tag string
+		if request.Method == "eth_getBlockByNumber" && len(request.Params) > 0 {
+			_ = json.Unmarshal(request.Params[0], &tag)
+		}
+		chain.mu.Lock()
+		number, ok := chain.heads[tag]
+		chain.mu.Unlock()
+		if ok {
+			response["result"] = &types.Header{Number: new(big.Int).SetUint64(number), Difficulty: new(big.Int)}
+		} else {
+			response["error"] = map[string]any{"code": -32601, "message": "unsupported block tag"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := ethereum.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return chain, client
+}
+
+// promotionRepo records promoted ranges and the status new transfers get while an update runs
+type promotionRepo struct {
+	repository.Repository
+	service *IngestionService
+	ranges  [][2]uint64
+	during  string // StatusFor of the range's last block, seen from inside the update
+	err     error
+}
+
+func (r *promotionRepo) ChainID() uint64 { return 1 }
+
+func (r *promotionRepo) SetTransferStatus(ctx context.Context, status string, fromBlock, toBlock uint64) (int64, error) {
+	r.ranges = append(r.ranges, [2]uint64{fromBlock, toBlock})
+	r.during = r.service.StatusFor(toBlock)
+	return 0, r.err
+}
+
+func TestPromoteTransfers(t *testing.T) {
+	chain, client := newFakeChain(t)
+	repo := &promotionRepo{}
+	s := &IngestionService{
+		ethereumClient: client,
+		repo:           repo,
+		logger:         logger.New("error", false, "", "text"),
+		chain:          "1",
+	}
+	repo.service = s
+	ctx := context.Background()
+
+	steps := []struct {
+		name       string
+		safe       uint64
+		err        error
+		wantRange  [2]uint64 // Zero when no update should run
+		wantStatus map[uint64]string
+	}{
+		{
+			name:       "startup catch-up",
+			safe:       100,
+			wantRange:  [2]uint64{1, 100},
+			wantStatus: map[uint64]string{100: models.TransferStatusSafe, 101: models.TransferStatusPending},
+		},
+		{
+			name:       "first tick does not re-cover the catch-up",
+			safe:       110,
+			wantRange:  [2]uint64{101, 110},
+			wantStatus: map[uint64]string{110: models.TransferStatusSafe},
+		},
+		{
+			name:       "next tick re-covers the previous range",
+			safe:       120,
+			wantRange:  [2]uint64{101, 120},
+			wantStatus: map[uint64]string{120: models.TransferStatusSafe},
+		},
+		{
+			name:       "failed update lowers the head again",
+			safe:       130,
+			err:        errors.New("write conflict"),
+			wantRange:  [2]uint64{111, 130},
+			wantStatus: map[uint64]string{120: models.TransferStatusSafe, 125: models.TransferStatusPending},
+		},
+		{
+			name:       "retry after failure",
+			safe:       130,
+			wantRange:  [2]uint64{111, 130},
+			wantStatus: map[uint64]string{130: models.TransferStatusSafe},
+		},
+		{
+			name:       "unchanged head",
+			safe:       130,
+			wantStatus: map[uint64]string{130: models.TransferStatusSafe, 131: models.TransferStatusPending},
+		},
+	}
+
+	for _, step := range steps {
+		chain.setHead("safe", step.safe)
+		repo.ranges = nil
+		repo.err = step.err
+
+		s.promoteTransfers(ctx)
+
+		if step.wantRange == ([2]uint64{}) {
+			if len(repo.ranges) != 0 {
+				t.Fatalf("%s: promoted %v, want no update", step.name, repo.ranges)
+			}
+		} else {
+			if len(repo.ranges) != 1 || repo.ranges[0] != step.wantRange {
+				t.Fatalf("%s: promoted %v, want [%v]", step.name, repo.ranges, step.wantRange)
+			}
+			if repo.during != models.TransferStatusSafe {
+				t.Errorf("%s: StatusFor(%d) during the update = %s, want safe", step.name, step.safe, repo.during)
+			}
+		}
+		for block, want := range step.wantStatus {
+			if got := s.StatusFor(block); got != want {
+				t.Errorf("%s: StatusFor(%d) = %s, want %s", step.name, block, got, want)
+			}
+		}
+	}
+}
+
+func TestIndexingHead(t *testing.T) {
+	chain, client := newFakeChain(t)
+	chain.setHead("latest", 120)
+	chain.setHead("safe", 100)
+	chain.setHead("finalized", 80)
+	_, preMerge := newFakeChain(t) // Knows no tagged heads
+
+	tests := []struct {
+		name     string
+		mode     ethereum.BlockTag
+		depth    uint64
+		preMerge bool
+		want     uint64
+		wantErr  bool
+	}{
+		{name: "latest", mode: ethereum.BlockTagLatest, want: 120},
+		{name: "latest with confirmations", mode: ethereum.BlockTagLatest, depth: 12, want: 108},
+		{name: "safe", mode: ethereum.BlockTagSafe, want: 100},
+		{name: "finalized with confirmations", mode: ethereum.BlockTagFinalized, depth: 5, want: 75},
+		{name: "depth beyond the head", mode: ethereum.BlockTagFinalized, depth: 81, want: 0},
+		{name: "provider without the tag", mode: ethereum.BlockTagSafe, preMerge: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &IngestionService{ethereumClient: client, indexingMode: tt.mode, confirmationDepth: tt.depth}
+			if tt.preMerge {
+				s.ethereumClient = preMerge
+			}
+			got, err := s.indexingHead(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("indexingHead() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("indexingHead() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// gaugeValue reads the current value of a gauge
+func gaugeValue(t *testing.T, gauge prometheus.Gauge) float64 {
+	t.Helper()
+	var metric dto.Metric
+	if err := gauge.Write(&metric); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	return metric.GetGauge().GetValue()
+}
+
+func TestRecordHeadLag(t *testing.T) {
+	service := &IngestionService{chain: "head-lag-test", logger: logger.New("error", false, "", "text")}
+
+	// Each step is a batch ending before next with the indexing head at head
+	steps := []struct {
+		head, next uint64
+		wantLag    float64
+		wantMode   string
+	}{
+		{head: 5000, next: 101, wantLag: 4900, wantMode: IngestionModeCatchingUp},
+		{head: 5000, next: 4001, wantLag: 1000, wantMode: IngestionModeCatchingUp},
+		{head: 5000, next: 5001, wantLag: 0, wantMode: IngestionModeAtTip},
+		{head: 5002, next: 5001, wantLag: 2, wantMode: IngestionModeCatchingUp},
+		{head: 5002, next: 5003, wantLag: 0, wantMode: IngestionModeAtTip},
+		{head: 5002, next: 5003, wantLag: 0, wantMode: IngestionModeAtTip}, // Head not advanced
+	}
+
+	for i, step := range steps {
+		service.mu.Lock()
+		service.headBlock = step.head
+		service.mu.Unlock()
+
+		catchingUp := service.recordHeadLag(step.next)
+		if catchingUp != (step.wantMode == IngestionModeCatchingUp) {
+			t.Errorf("step %d: recordHeadLag() = %v, want mode %s", i, catchingUp, step.wantMode)
+		}
+		if lag := gaugeValue(t, metrics.HeadLagBlocks.WithLabelValues(service.chain)); lag != step.wantLag {
+			t.Errorf("step %d: head lag = %v, want %v", i, lag, step.wantLag)
+		}
+		for _, mode := range []string{IngestionModeCatchingUp, IngestionModeAtTip} {
+			want := 0.0
+			if mode == step.wantMode {
+				want = 1
+			}
+			if got := gaugeValue(t, metrics.IngestionMode.WithLabelValues(service.chain, mode)); got != want {
+				t.Errorf("step %d: mode %s = %v, want %v", i, mode, got, want)
+			}
+		}
+	}
+}
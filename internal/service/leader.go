@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"pagrin/internal/metrics"
+	"pagrin/internal/models"
+	"pagrin/pkg/logger"
+)
+
+// leaderLease names the lease held by the replica that runs ingestion
+const leaderLease = "ingestion"
+
+// LeaseStore grants a named lease to one holder at a time until it expires
+// Implemented by the MongoDB repository and the Redis cache
+type LeaseStore interface {
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+// LeaderElector lets exactly one replica run ingestion by holding a lease
+// Other replicas keep serving the API and take over once the leader's lease expires
+type LeaderElector struct {
+	store    LeaseStore // nil disables election: this replica always leads
+	election string     // Lease backend reported on /health
+	instance string
+	ttl      time.Duration
+	logger   *logger.Logger
+
+	mu     sync.RWMutex
+	leader bool
+	since  time.Time
+}
+
+func NewLeaderElector(store LeaseStore, election, instance string, ttl time.Duration, logger *logger.Logger) *LeaderElector {
+	return &LeaderElector{
+		store:    store,
+		election: election,
+		instance: instance,
+		ttl:      ttl,
+		logger:   logger,
+	}
+}
+
+// Run calls lead while this replica holds the lease, cancelling its context when the lease is lost
+// The lease is renewed every third of its TTL; leadership is given up once renewals have failed for
+// two thirds of it, leaving the last third for lead to stop before another replica can take over
+// If lead fails, the lease is released and taken again on a later attempt
+// Without a lease store lead runs once and its error is returned
+func (e *LeaderElector) Run(ctx context.Context, lead func(ctx context.Context) error) error {
+	if e.store == nil {
+		e.setLeader(true)
+		return lead(ctx)
+	}
+
+	e.logger.Info("Leader election via %s as %s (lease TTL %s)", e.election, e.instance, e.ttl)
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		acquired, err := e.store.AcquireLease(ctx, leaderLease, e.instance, e.ttl)
+		if err != nil && ctx.Err() == nil {
+			e.logger.Warn("Failed to acquire leader lease: %v", err)
+		}
+		if acquired {
+			e.lead(ctx, ticker, lead)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead runs lead and renews the lease until either stops, then releases the lease
+func (e *LeaderElector) lead(ctx context.Context, ticker *time.Ticker, lead func(ctx context.Context) error) {
+	e.setLeader(true)
+	e.logger.Info("Acquired leader lease, starting ingestion")
+
+	leadCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- lead(leadCtx)
+	}()
+
+	renewed := time.Now()
+	stopped := false
+	for !stopped {
+		select {
+		case <-ctx.Done():
+			stopped = true
+		case err := <-done:
+			if err != nil {
+				e.logger.Error("Ingestion failed while leader: %v", err)
+			}
+			done <- err // Put back for the wait below
+			stopped = true
+		case <-ticker.C:
+			held, err := e.store.AcquireLease(ctx, leaderLease, e.instance, e.ttl)
+			switch {
+			case err == nil && held:
+				renewed = time.Now()
+			case err == nil:
+				e.logger.Warn("Leader lease was taken by another replica")
+				stopped = true
+			case time.Since(renewed) >= e.ttl-e.ttl/3:
+				e.logger.Warn("Could not renew leader lease before it expires: %v", err)
+				stopped = true
+			default:
+				e.logger.Warn("Failed to renew leader lease: %v", err)
+			}
+		}
+	}
+
+	cancel()
+	<-done
+	e.setLeader(false)
+	e.logger.Info("Stopped ingestion, no longer leader")
+
+	releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer releaseCancel()
+	if err := e.store.ReleaseLease(releaseCtx, leaderLease, e.instance); err != nil {
+		e.logger.Warn("Failed to release leader lease: %v", err)
+	}
+}
+
+// Status returns this replica's leadership for /health
+func (e *LeaderElector) Status() models.LeaderStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	status := models.LeaderStatus{
+		Election: e.election,
+		Instance: e.instance,
+		Leader:   e.leader,
+	}
+	if !e.since.IsZero() {
+		since := e.since
+		status.Since = &since
+	}
+	return status
+}
+
+func (e *LeaderElector) setLeader(leader bool) {
+	e.mu.Lock()
+	e.leader = leader
+	e.since = time.Now()
+	e.mu.Unlock()
+
+	if leader {
+		metrics.LeaderElected.Set(1)
+	} else {
+		metrics.LeaderElected.Set(0)
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"pagrin/pkg/logger"
+)
+
+// leaseAnswer is the outcome of one AcquireLease call
+type leaseAnswer struct {
+	held bool
+	err  error
+}
+
+// leaseStore answers AcquireLease calls in order, repeating the last answer, and records releases
+type leaseStore struct {
+	mu       sync.Mutex
+	answers  []leaseAnswer
+	acquires int
+	released []string
+}
+
+func (s *leaseStore) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	answer := s.answers[min(s.acquires, len(s.answers)-1)]
+	s.acquires++
+	return answer.held, answer.err
+}
+
+func (s *leaseStore) ReleaseLease(ctx context.Context, name, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.released = append(s.released, name+"/"+holder)
+	return nil
+}
+
+func (s *leaseStore) calls() (int, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.acquires, append([]string(nil), s.released...)
+}
+
+func TestLeaderWithoutStore(t *testing.T) {
+	elector := NewLeaderElector(nil, "none", "replica-a", time.Second, logger.New("error", false, "", "text"))
+	failed := errors.New("ingestion failed")
+
+	err := elector.Run(context.Background(), func(ctx context.Context) error {
+		if !elector.Status().Leader {
+			t.Errorf("Status().Leader = false while leading")
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Errorf("Run() error = %v, want %v", err, failed)
+	}
+}
+
+func TestLeaderElection(t *testing.T) {
+	lost := errors.New("connection refused")
+
+	tests := []struct {
+		name    string
+		answers []leaseAnswer
+		// Minimum AcquireLease calls before leadership ends; renewals that fail
+		// within two thirds of the TTL do not end it
+		minAcquires int
+	}{
+		{
+			name:        "lease taken by another replica",
+			answers:     []leaseAnswer{{held: true}, {held: false}},
+			minAcquires: 2,
+		},
+		{
+			name:        "renewals fail until the lease is about to expire",
+			answers:     []leaseAnswer{{held: true}, {err: lost}},
+			minAcquires: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &leaseStore{answers: tt.answers}
+			elector := NewLeaderElector(store, "mongodb", "replica-a", 300*time.Millisecond, logger.New("error", false, "", "text"))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stopped := make(chan int, 1)
+			done := make(chan error, 1)
+			go func() {
+				done <- elector.Run(ctx, func(leadCtx context.Context) error {
+					if !elector.Status().Leader {
+						t.Errorf("Status().Leader = false while leading")
+					}
+					<-leadCtx.Done()
+					acquires, _ := store.calls()
+					stopped <- acquires
+					return nil
+				})
+			}()
+
+			select {
+			case acquires := <-stopped:
+				if acquires < tt.minAcquires {
+					t.Errorf("leadership ended after %d lease calls, want at least %d", acquires, tt.minAcquires)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("leadership was never given up")
+			}
+
+			// The lease is released once the stopped ingestion returns
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				if _, released := store.calls(); len(released) > 0 {
+					if !reflect.DeepEqual(released, []string{"ingestion/replica-a"}) {
+						t.Errorf("released = %v, want the ingestion lease of replica-a", released)
+					}
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("lease was never released")
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			if elector.Status().Leader {
+				t.Errorf("Status().Leader = true after losing the lease")
+			}
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Errorf("Run() error = %v, want nil after cancel", err)
+			}
+		})
+	}
+}
+
+func TestLeaderRestartsFailedIngestion(t *testing.T) {
+	store := &leaseStore{answers: []leaseAnswer{{held: true}}}
+	elector := NewLeaderElector(store, "redis", "replica-a", 30*time.Millisecond, logger.New("error", false, "", "text"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs := 0
+	err := elector.Run(ctx, func(leadCtx context.Context) error {
+		runs++
+		if runs == 1 {
+			return errors.New("ingestion failed")
+		}
+		// The lease was released after the failure and taken again for this run
+		if _, released := store.calls(); len(released) != 1 {
+			t.Errorf("released %v before the second run, want once", released)
+		}
+		cancel()
+		<-leadCtx.Done()
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Run() error = %v, want nil after cancel", err)
+	}
+	if runs != 2 {
+		t.Errorf("ingestion ran %d times, want 2", runs)
+	}
+}
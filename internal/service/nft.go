@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrNFTNotFound is returned when a token has no recorded transfers
+var ErrNFTNotFound = fmt.Errorf("NFT not found")
+
+// ErrInvalidInput wraps validation errors on user-supplied query values
+var ErrInvalidInput = fmt.Errorf("invalid input")
+
+// NFTService answers ERC-721 ownership queries from the derived owner table
+type NFTService struct {
+	nftRepo repository.NFTRepository
+	logger  *logger.Logger
+}
+
+func NewNFTService(nftRepo repository.NFTRepository, logger *logger.Logger) *NFTService {
+	return &NFTService{
+		nftRepo: nftRepo,
+		logger:  logger,
+	}
+}
+
+// GetOwner returns the current owner of tokenID in a collection
+// tokenID may be decimal or 0x-prefixed hex
+func (s *NFTService) GetOwner(ctx context.Context, token, tokenID string) (*models.NFTOwner, error) {
+	token, err := normalizeAddress(token)
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := new(big.Int).SetString(tokenID, 0)
+	if !ok || id.Sign() < 0 {
+		return nil, fmt.Errorf("%w: token id %s", ErrInvalidInput, tokenID)
+	}
+
+	owner, err := s.nftRepo.GetNFTOwner(ctx, token, id.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NFT owner: %w", err)
+	}
+	if owner == nil {
+		return nil, ErrNFTNotFound
+	}
+	owner.Burned = ethereum.IsZeroAddress(owner.Owner)
+
+	return owner, nil
+}
+
+// ListByOwner returns the NFTs currently held by an address
+func (s *NFTService) ListByOwner(ctx context.Context, params models.NFTQueryParams) ([]*models.NFTOwner, int64, error) {
+	owner, err := normalizeAddress(params.Owner)
+	if err != nil {
+		return nil, 0, err
+	}
+	params.Owner = owner
+
+	if params.Token != "" {
+		if params.Token, err = normalizeAddress(params.Token); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = 100
+	}
+	if params.Limit > 1000 {
+		params.Limit = 1000
+	}
+
+	nfts, total, err := s.nftRepo.GetNFTsByOwner(ctx, params)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list NFTs: %w", err)
+	}
+
+	return nfts, total, nil
+}
+
+// normalizeAddress validates a hex address and returns it lowercased, as stored
+func normalizeAddress(address string) (string, error) {
+	if !common.IsHexAddress(address) {
+		return "", fmt.Errorf("%w: address %s", ErrInvalidInput, address)
+	}
+	return strings.ToLower(common.HexToAddress(address).Hex()), nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+)
+
+// nftRepo serves owners keyed by token and decimal token id
+type nftRepo struct {
+	repository.NFTRepository
+	owners map[[2]string]*models.NFTOwner
+}
+
+func (r *nftRepo) GetNFTOwner(ctx context.Context, token, tokenID string) (*models.NFTOwner, error) {
+	owner, ok := r.owners[[2]string{token, tokenID}]
+	if !ok {
+		return nil, nil
+	}
+	copied := *owner
+	return &copied, nil
+}
+
+func TestGetOwner(t *testing.T) {
+	const (
+		collection = "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d"
+		holder     = "0x1111111111111111111111111111111111111111"
+		zero       = "0x0000000000000000000000000000000000000000"
+	)
+	repo := &nftRepo{owners: map[[2]string]*models.NFTOwner{
+		{collection, "255"}: {Token: collection, TokenID: "255", Owner: holder},
+		{collection, "1"}:   {Token: collection, TokenID: "1", Owner: zero},
+	}}
+	s := NewNFTService(repo, logger.New("error", false, "", "text"))
+
+	tests := []struct {
+		name       string
+		token      string
+		tokenID    string
+		wantOwner  string
+		wantBurned bool
+		wantErr    error
+	}{
+		{name: "decimal id", token: collection, tokenID: "255", wantOwner: holder},
+		{name: "hex id", token: collection, tokenID: "0xff", wantOwner: holder},
+		{name: "checksummed collection", token: "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D", tokenID: "255", wantOwner: holder},
+		{name: "burned", token: collection, tokenID: "1", wantOwner: zero, wantBurned: true},
+		{name: "unknown id", token: collection, tokenID: "2", wantErr: ErrNFTNotFound},
+		{name: "negative id", token: collection, tokenID: "-1", wantErr: ErrInvalidInput},
+		{name: "malformed id", token: collection, tokenID: "abc", wantErr: ErrInvalidInput},
+		{name: "malformed collection", token: "bayc", tokenID: "1", wantErr: ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, err := s.GetOwner(context.Background(), tt.token, tt.tokenID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetOwner() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if owner.Owner != tt.wantOwner || owner.Burned != tt.wantBurned {
+				t.Errorf("GetOwner() = %s (burned %v), want %s (burned %v)", owner.Owner, owner.Burned, tt.wantOwner, tt.wantBurned)
+			}
+		})
+	}
+}
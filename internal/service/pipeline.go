@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/metrics"
+)
+
+// Ingestion pipeline stages, exposed as the stage label of the pipeline metrics
+const (
+	StageFetch = "fetch" // Plans a batch and fetches its logs and block timestamps
+	StageParse = "parse" // Decodes events and extracts native and internal transfers
+	StageStore = "store" // Writes events, publishes them and moves the checkpoint
+)
+
+// stageTimeout bounds the work of one stage on one batch
+const stageTimeout = 60 * time.Second
+
+// pipelineBatch is a block range moving through the ingestion pipeline
+type pipelineBatch struct {
+	fromBlock    uint64
+	toBlock      uint64
+	batchSize    uint64
+	toHash       string // Hash of toBlock, recorded with the checkpoint
+	toParentHash string
+	toTime       time.Time // Timestamp of toBlock
+	raw          *ethereum.RawEvents
+	result       *ethereum.FetchResult
+	started      time.Time
+}
+
+// runPipeline ingests batches from fromBlock until the checkpoint reaches the indexing head
+// Fetch, parse and store run concurrently, connected by queues of pipelineDepth batches, so batch N+1
+// is fetched while batch N is written. Every stage handles one batch at a time in block order,
+// so checkpoints only move forward in order
+// A failed batch stops the pipeline: batches behind it are still stored, batches ahead of it are dropped
+// Returns the block after the last checkpoint
+func (s *IngestionService) runPipeline(ctx context.Context, fromBlock uint64) (uint64, error) {
+	// Cancelling a stage's context stops it and every stage upstream of it
+	parseCtx, stopParse := context.WithCancel(ctx)
+	defer stopParse()
+	fetchCtx, stopFetch := context.WithCancel(parseCtx)
+	defer stopFetch()
+
+	toParse := make(chan *pipelineBatch, s.pipelineDepth)
+	toStore := make(chan *pipelineBatch, s.pipelineDepth)
+
+	var wg sync.WaitGroup
+	var startBlock uint64
+	var fetchErr, parseErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		startBlock, fetchErr = s.fetchStage(fetchCtx, fromBlock, toParse)
+	}()
+	go func() {
+		defer wg.Done()
+		parseErr = s.parseStage(parseCtx, toParse, toStore, stopFetch)
+	}()
+
+	lastBlock, stored, storeErr := s.storeStage(ctx, toStore, stopParse)
+	wg.Wait()
+
+	nextBlock := startBlock
+	if stored {
+		nextBlock = lastBlock + 1
+	}
+
+	// Downstream errors belong to earlier batches, and upstream stages only fail after being stopped
+	for _, err := range []error{storeErr, parseErr, fetchErr} {
+		if err != nil {
+			return nextBlock, err
+		}
+	}
+	return nextBlock, nil
+}
+
+// fetchStage plans batches from fromBlock and fetches their logs until a batch reaches the indexing head
+// Returns the first block it queued, which moves back when a reorg is rolled back before the first batch
+func (s *IngestionService) fetchStage(ctx context.Context, fromBlock uint64, out chan<- *pipelineBatch) (uint64, error) {
+	defer close(out)
+
+	startBlock := fromBlock
+	nextBlock := fromBlock
+	var parentHash string // Hash of nextBlock-1 from the previous batch, empty before the first one
+	for {
+		started := time.Now()
+		stageCtx, cancel := context.WithTimeout(ctx, stageTimeout)
+		batch, resumeBlock, head, err := s.fetchBatch(stageCtx, nextBlock, parentHash)
+		cancel()
+		if err != nil {
+			return startBlock, err
+		}
+
+		if batch == nil {
+			if resumeBlock == nextBlock {
+				return startBlock, nil
+			}
+			// Rolled back before anything was queued - continue from the common ancestor
+			startBlock, nextBlock = resumeBlock, resumeBlock
+			continue
+		}
+
+		batch.started = started
+		metrics.IngestionStageDuration.WithLabelValues(s.chain, StageFetch).Observe(time.Since(started).Seconds())
+
+		select {
+		case out <- batch:
+		case <-ctx.Done():
+			return startBlock, ctx.Err()
+		}
+		metrics.IngestionStageQueueDepth.WithLabelValues(s.chain, StageParse).Set(float64(len(out)))
+
+		// At tip the next batch waits for a new head instead of polling for one here
+		if batch.toBlock >= head {
+			return startBlock, nil
+		}
+		nextBlock, parentHash = batch.toBlock+1, batch.toHash
+
+		// A pause or rewind stops planning; the batches already queued are still stored
+		if s.controlInterrupts(ctx) {
+			return startBlock, nil
+		}
+	}
+}
+
+// fetchBatch plans the batch starting at fromBlock and fetches its raw events
+// Returns a nil batch once fromBlock is past the indexing head, or when the chain no longer extends parentHash;
+// resumeBlock is then fromBlock, or the block after the common ancestor if a reorg was rolled back
+func (s *IngestionService) fetchBatch(ctx context.Context, fromBlock uint64, parentHash string) (*pipelineBatch, uint64, uint64, error) {
+	latestBlock, err := s.indexingHead(ctx)
+	if err != nil {
+		return nil, fromBlock, 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	s.mu.Lock()
+	s.headBlock = latestBlock
+	s.mu.Unlock()
+	metrics.HeadBlock.WithLabelValues(s.chain).Set(float64(latestBlock))
+
+	if fromBlock > latestBlock {
+		return nil, fromBlock, latestBlock, nil
+	}
+
+	if parentHash == "" {
+		// Verify the first batch builds on the last recorded block before ingesting it
+		if ancestor, reorged, err := s.checkReorg(ctx, fromBlock); err != nil {
+			return nil, fromBlock, latestBlock, fmt.Errorf("failed to check for reorg: %w", err)
+		} else if reorged {
+			return nil, ancestor + 1, latestBlock, nil
+		}
+	} else {
+		// Later batches extend batches that are not checkpointed yet; on a mismatch the pipeline drains
+		// and the next run rolls back from the recorded checkpoints
+		header, err := s.ethereumClient.GetBlockHeader(ctx, fromBlock)
+		if err != nil {
+			return nil, fromBlock, latestBlock, fmt.Errorf("failed to check for reorg: %w", err)
+		}
+		if header.ParentHash.Hex() != parentHash {
+			s.logger.Warn("Chain changed under in-flight batches at block %d, restarting after they are stored", fromBlock)
+			return nil, fromBlock, latestBlock, nil
+		}
+	}
+
+	batchSize := s.batchSize()
+	toBlock := fromBlock + batchSize - 1
+	if toBlock > latestBlock {
+		toBlock = latestBlock
+	}
+
+	// Header of the last block in the batch is recorded with the checkpoint
+	// so the next batch can verify it still extends the same chain
+	toHeader, err := s.ethereumClient.GetBlockHeader(ctx, toBlock)
+	if err != nil {
+		return nil, fromBlock, latestBlock, fmt.Errorf("failed to get checkpoint header: %w", err)
+	}
+
+	s.logger.Debug("Fetching transfers from blocks %d-%d (batch size: %d)", fromBlock, toBlock, batchSize)
+	fetchStart := time.Now()
+	raw, err := s.fetcher.FetchRawEvents(ctx, fromBlock, toBlock)
+	if err != nil {
+		// Record failure and adjust batch size if adaptive mode is enabled
+		if s.adaptiveBatch {
+			s.adjustBatchSizeOnFailure()
+		}
+		return nil, fromBlock, latestBlock, fmt.Errorf("failed to fetch transfer logs: %w", err)
+	}
+	// AIMD sizes the next batch as soon as this one is fetched, before it is planned
+	if s.adaptiveBatch && s.batchStrategy == BatchStrategyAIMD {
+		s.adjustBatchSizeAIMD(toBlock-fromBlock+1, raw.LogCount(), time.Since(fetchStart))
+	}
+
+	return &pipelineBatch{
+		fromBlock:    fromBlock,
+		toBlock:      toBlock,
+		batchSize:    batchSize,
+		toHash:       toHeader.Hash().Hex(),
+		toParentHash: toHeader.ParentHash.Hex(),
+		toTime:       time.Unix(int64(toHeader.Time), 0),
+		raw:          raw,
+	}, fromBlock, latestBlock, nil
+}
+
+// parseStage decodes the events of each fetched batch
+// On failure it stops the fetch stage and drops the batches still queued
+func (s *IngestionService) parseStage(ctx context.Context, in <-chan *pipelineBatch, out chan<- *pipelineBatch, stopFetch context.CancelFunc) error {
+	defer close(out)
+
+	for batch := range in {
+		metrics.IngestionStageQueueDepth.WithLabelValues(s.chain, StageParse).Set(float64(len(in)))
+
+		started := time.Now()
+		stageCtx, cancel := context.WithTimeout(ctx, stageTimeout)
+		result, err := s.fetcher.ParseEvents(stageCtx, batch.raw)
+		cancel()
+		if err == nil {
+			err = checkBatchHash(result, batch.toBlock, batch.toHash)
+		}
+		if err != nil {
+			stopFetch()
+			drainBatches(in)
+			return fmt.Errorf("failed to parse blocks %d-%d: %w", batch.fromBlock, batch.toBlock, err)
+		}
+		metrics.IngestionStageDuration.WithLabelValues(s.chain, StageParse).Observe(time.Since(started).Seconds())
+
+		batch.result = result
+		batch.raw = nil
+		out <- batch
+		metrics.IngestionStageQueueDepth.WithLabelValues(s.chain, StageStore).Set(float64(len(out)))
+	}
+	return nil
+}
+
+// storeStage writes each parsed batch, publishes it to the stream and then records its checkpoint
+// Returns the last checkpointed block and whether any batch was stored
+// On failure it stops the upstream stages and drops the batches still queued
+func (s *IngestionService) storeStage(ctx context.Context, in <-chan *pipelineBatch, stopParse context.CancelFunc) (uint64, bool, error) {
+	var lastBlock uint64
+	var stored bool
+	for batch := range in {
+		metrics.IngestionStageQueueDepth.WithLabelValues(s.chain, StageStore).Set(float64(len(in)))
+
+		started := time.Now()
+		stageCtx, cancel := context.WithTimeout(ctx, stageTimeout)
+		err := s.storeBatch(stageCtx, batch)
+		cancel()
+		if err != nil {
+			stopParse()
+			drainBatches(in)
+			return lastBlock, stored, err
+		}
+		metrics.IngestionStageDuration.WithLabelValues(s.chain, StageStore).Observe(time.Since(started).Seconds())
+
+		lastBlock, stored = batch.toBlock, true
+		s.recordCheckpoint(lastBlock, batch.toTime)
+		s.recordHeadLag(lastBlock + 1)
+	}
+	return lastBlock, stored, nil
+}
+
+// storeBatch writes a batch's events, publishes its transfers and moves the checkpoint to its last block
+func (s *IngestionService) storeBatch(ctx context.Context, batch *pipelineBatch) error {
+	result := batch.result
+	transfers := result.Transfers
+
+	// The checkpoint commits in the same transaction as the batch's transfers
+	checkpoint := func(ctx context.Context) error {
+		if err := s.repo.SetLastProcessedBlock(ctx, batch.toBlock, batch.toHash, batch.toParentHash); err != nil {
+			return fmt.Errorf("failed to set last processed block: %w", err)
+		}
+		return nil
+	}
+	if err := s.events.StoreAndCommit(ctx, result, s.StatusFor, checkpoint); err != nil {
+		// Insert failure - adjust batch size if adaptive
+		if s.adaptiveBatch {
+			s.adjustBatchSizeOnFailure()
+		}
+		return err
+	}
+
+	if result.Len() > 0 || len(result.DeadLetters) > 0 {
+		metrics.TransfersProcessedTotal.WithLabelValues(s.chain, "success").Add(float64(len(transfers)))
+		metrics.TransfersProcessedTotal.WithLabelValues(s.chain, "nft").Add(float64(len(result.NFTTransfers)))
+		metrics.TransfersProcessedTotal.WithLabelValues(s.chain, "approval").Add(float64(len(result.Approvals)))
+		// Log with structured fields for better observability
+		s.logger.WithFields("info", "Processed transfers", map[string]interface{}{
+			"transfers":     len(transfers),
+			"nft_transfers": len(result.NFTTransfers),
+			"approvals":     len(result.Approvals),
+			"from_block":    batch.fromBlock,
+			"to_block":      batch.toBlock,
+			"batch_size":    batch.batchSize,
+			"elapsed_ms":    time.Since(batch.started).Milliseconds(),
+		})
+		s.logger.Info("Processed %d transfers, %d NFT transfers and %d approvals from blocks %d-%d (batch: %d)", len(transfers), len(result.NFTTransfers), len(result.Approvals), batch.fromBlock, batch.toBlock, batch.batchSize)
+
+		// Publish transfers to stream if enabled, only once they are committed
+		if s.stream != nil {
+			for _, transfer := range transfers {
+				if transfer != nil {
+					s.stream.Publish(transfer)
+				}
+			}
+		}
+	} else {
+		s.logger.Debug("No transfers found in blocks %d-%d", batch.fromBlock, batch.toBlock)
+	}
+
+	// Coverage is recorded after the checkpoint; if this fails the gap repair job re-ingests the range
+	if err := s.repo.AddCoveredRange(ctx, batch.fromBlock, batch.toBlock); err != nil {
+		s.logger.Warn("Failed to record coverage for blocks %d-%d: %v", batch.fromBlock, batch.toBlock, err)
+	}
+
+	metrics.BlocksProcessedTotal.WithLabelValues(s.chain).Add(float64(batch.toBlock - batch.fromBlock + 1))
+
+	// Record success and adjust batch size if the streak strategy is enabled
+	if s.adaptiveBatch && s.batchStrategy == BatchStrategyStreak {
+		s.adjustBatchSizeOnSuccess()
+	}
+
+	// Check if the batch took longer than poll interval end to end (back-pressure indicator)
+	processingTime := time.Since(batch.started)
+	metrics.TransfersProcessingDuration.WithLabelValues("block_processing").Observe(processingTime.Seconds())
+	if processingTime > s.pollInterval {
+		s.logger.Warn("Batch processing took %v (longer than poll interval %v) - consider reducing batch size", processingTime, s.pollInterval)
+	}
+
+	return nil
+}
+
+// drainBatches discards queued batches so upstream stages can finish and close their queues
+func drainBatches(in <-chan *pipelineBatch) {
+	for range in {
+	}
+}
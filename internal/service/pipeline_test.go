@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// headerChain serves headers linked by parent hash and no logs
+// Blocks from forkBlock on carry forkExtra, so setting it switches them to a competing branch
+type headerChain struct {
+	mu        sync.Mutex
+	head      uint64
+	forkBlock uint64
+	forkExtra []byte
+}
+
+func (c *headerChain) fork(block uint64, extra string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forkBlock, c.forkExtra = block, []byte(extra)
+}
+
+func (c *headerChain) hash(number uint64) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.header(number).Hash().Hex()
+}
+
+// header builds block number on the current branch; the caller must hold c.mu
+func (c *headerChain) header(number uint64) *types.Header {
+	header := &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		Difficulty: new(big.Int),
+		Time:       1_700_000_000 + number,
+	}
+	if number > 0 {
+		header.ParentHash = c.header(number - 1).Hash()
+	}
+	if c.forkExtra != nil && number >= c.forkBlock {
+		header.Extra = c.forkExtra
+	}
+	return header
+}
+
+// newHeaderChain starts a JSON-RPC server for a chain at head and returns a client connected to it
+func newHeaderChain(t *testing.T, head uint64) (*headerChain, *ethereum.Client) {
+	t.Helper()
+	chain := &headerChain{head: head}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		response := map[string]any{"jsonrpc": "2.0", "id": request.ID}
+		switch request.Method {
+		case "eth_getBlockByNumber":
+			var arg string
+			_ = json.Unmarshal(request.Params[0], &arg)
+			chain.mu.Lock()
+			number := chain.head
+			if arg != "latest" {
+				n, err := hexutil.DecodeUint64(arg)
+				if err != nil {
+					chain.mu.Unlock()
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				number = n
+			}
+			if number <= chain.head {
+				response["result"] = chain.header(number)
+			} else {
+				response["result"] = nil
+			}
+			chain.mu.Unlock()
+		case "eth_getLogs":
+			response["result"] = []types.Log{}
+		default:
+			response["error"] = map[string]any{"code": -32601, "message": "method not found"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := ethereum.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return chain, client
+}
+
+// checkpointRepo keeps checkpoints in memory and records the order they are committed in
+type checkpointRepo struct {
+	repository.Repository
+	mu          sync.Mutex
+	checkpoints map[uint64]*models.ProcessedBlock
+	committed   []uint64
+	covered     [][2]uint64
+	rolledBack  []uint64
+	failAt      uint64 // Checkpoint that fails to commit, 0 for none
+}
+
+func newCheckpointRepo() *checkpointRepo {
+	return &checkpointRepo{checkpoints: make(map[uint64]*models.ProcessedBlock)}
+}
+
+func (r *checkpointRepo) ChainID() uint64 { return 1 }
+
+func (r *checkpointRepo) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *checkpointRepo) SetLastProcessedBlock(ctx context.Context, blockNumber uint64, blockHash, parentHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if blockNumber == r.failAt {
+		return errors.New("write conflict")
+	}
+	r.checkpoints[blockNumber] = &models.ProcessedBlock{BlockNumber: blockNumber, BlockHash: blockHash, ParentHash: parentHash}
+	r.committed = append(r.committed, blockNumber)
+	return nil
+}
+
+func (r *checkpointRepo) GetProcessedBlock(ctx context.Context, blockNumber uint64) (*models.ProcessedBlock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.checkpoints[blockNumber], nil
+}
+
+func (r *checkpointRepo) GetProcessedBlocks(ctx context.Context, fromBlock, toBlock uint64) ([]*models.ProcessedBlock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var blocks []*models.ProcessedBlock
+	for number, checkpoint := range r.checkpoints {
+		if number >= fromBlock && number <= toBlock {
+			blocks = append(blocks, checkpoint)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].BlockNumber > blocks[j].BlockNumber })
+	return blocks, nil
+}
+
+func (r *checkpointRepo) RollbackToBlock(ctx context.Context, blockNumber uint64) ([]*models.Transfer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rolledBack = append(r.rolledBack, blockNumber)
+	for number := range r.checkpoints {
+		if number > blockNumber {
+			delete(r.checkpoints, number)
+		}
+	}
+	return nil, nil
+}
+
+func (r *checkpointRepo) AddCoveredRange(ctx context.Context, fromBlock, toBlock uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.covered = append(r.covered, [2]uint64{fromBlock, toBlock})
+	return nil
+}
+
+// discardEvents accepts the NFT transfers, approvals and dead letters of a batch without storing them
+type discardEvents struct {
+	repository.NFTRepository
+	repository.ApprovalRepository
+	repository.DeadLetterRepository
+}
+
+func (discardEvents) InsertNFTTransfers(ctx context.Context, transfers []*models.NFTTransfer) error {
+	return nil
+}
+
+func (discardEvents) InsertApprovals(ctx context.Context, approvals []*models.Approval) error {
+	return nil
+}
+
+func (discardEvents) SaveDeadLetters(ctx context.Context, letters []*models.DeadLetter) error {
+	return nil
+}
+
+// idleControl never pauses or rewinds ingestion
+type idleControl struct {
+	repository.ControlRepository
+}
+
+func (idleControl) GetIngestionControl(ctx context.Context) (*models.IngestionControl, error) {
+	return nil, nil
+}
+
+func newPipelineService(client *ethereum.Client, repo *checkpointRepo, batchSize uint64) *IngestionService {
+	log := logger.New("error", false, "", "text")
+	cache := ethereum.NewBlockHeaderCache(100, time.Minute, nil, "1")
+	return &IngestionService{
+		ethereumClient: client,
+		fetcher:        ethereum.NewFetcher(client, cache, nil, false, false),
+		repo:           repo,
+		controlRepo:    idleControl{},
+		events: &EventStore{
+			repo:         repo,
+			nftRepo:      discardEvents{},
+			approvalRepo: discardEvents{},
+			deadLetters:  discardEvents{},
+			logger:       log,
+			chain:        "1",
+		},
+		logger:           log,
+		chain:            "1",
+		pollInterval:     time.Minute,
+		blockBatchSize:   batchSize,
+		currentBatchSize: batchSize,
+		reorgMaxDepth:    64,
+		pipelineDepth:    2,
+	}
+}
+
+func TestRunPipeline(t *testing.T) {
+	tests := []struct {
+		name          string
+		head          uint64
+		fromBlock     uint64
+		recorded      []uint64 // Checkpoints already stored before the run
+		forkBlock     uint64   // First block replaced by a competing branch before the run, 0 for none
+		failAt        uint64
+		wantNext      uint64
+		wantErr       bool
+		wantCommitted []uint64
+		wantCovered   [][2]uint64
+		wantRollback  []uint64
+	}{
+		{
+			name:          "ingests to head in order",
+			head:          23,
+			fromBlock:     0,
+			wantNext:      24,
+			wantCommitted: []uint64{4, 9, 14, 19, 23},
+			wantCovered:   [][2]uint64{{0, 4}, {5, 9}, {10, 14}, {15, 19}, {20, 23}},
+		},
+		{
+			name:          "resumes after the recorded checkpoint",
+			head:          19,
+			fromBlock:     10,
+			recorded:      []uint64{9},
+			wantNext:      20,
+			wantCommitted: []uint64{14, 19},
+			wantCovered:   [][2]uint64{{10, 14}, {15, 19}},
+		},
+		{
+			name:          "failed store keeps earlier batches only",
+			head:          23,
+			fromBlock:     0,
+			failAt:        14,
+			wantNext:      10,
+			wantErr:       true,
+			wantCommitted: []uint64{4, 9},
+			wantCovered:   [][2]uint64{{0, 4}, {5, 9}},
+		},
+		{
+			name:          "first batch fails",
+			head:          23,
+			fromBlock:     0,
+			failAt:        4,
+			wantNext:      0,
+			wantErr:       true,
+			wantCommitted: nil,
+			wantCovered:   nil,
+		},
+		{
+			name:          "rolls back a reorg to the common ancestor",
+			head:          19,
+			fromBlock:     10,
+			recorded:      []uint64{5, 6, 7, 8, 9},
+			forkBlock:     8,
+			wantNext:      20,
+			wantCommitted: []uint64{12, 17, 19},
+			wantCovered:   [][2]uint64{{8, 12}, {13, 17}, {18, 19}},
+			wantRollback:  []uint64{7},
+		},
+		{
+			name:      "nothing past the head",
+			head:      9,
+			fromBlock: 10,
+			recorded:  []uint64{9},
+			wantNext:  10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, client := newHeaderChain(t, tt.head)
+			repo := newCheckpointRepo()
+			for _, number := range tt.recorded {
+				repo.checkpoints[number] = &models.ProcessedBlock{BlockNumber: number, BlockHash: chain.hash(number)}
+			}
+			if tt.forkBlock > 0 {
+				chain.fork(tt.forkBlock, "competing branch")
+			}
+			repo.failAt = tt.failAt
+
+			s := newPipelineService(client, repo, 5)
+			next, err := s.runPipeline(context.Background(), tt.fromBlock)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runPipeline() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if next != tt.wantNext {
+				t.Errorf("runPipeline() = %d, want %d", next, tt.wantNext)
+			}
+			if !reflect.DeepEqual(repo.committed, tt.wantCommitted) {
+				t.Errorf("committed checkpoints = %v, want %v", repo.committed, tt.wantCommitted)
+			}
+			if !reflect.DeepEqual(repo.covered, tt.wantCovered) {
+				t.Errorf("covered ranges = %v, want %v", repo.covered, tt.wantCovered)
+			}
+			if !reflect.DeepEqual(repo.rolledBack, tt.wantRollback) {
+				t.Errorf("rolled back to %v, want %v", repo.rolledBack, tt.wantRollback)
+			}
+
+			// Every checkpoint records the block of the current branch it was fetched from
+			for _, number := range repo.committed {
+				checkpoint := repo.checkpoints[number]
+				if want := chain.hash(number); checkpoint.BlockHash != want {
+					t.Errorf("checkpoint %d hash = %s, want %s", number, checkpoint.BlockHash, want)
+				}
+				if want := chain.hash(number - 1); checkpoint.ParentHash != want {
+					t.Errorf("checkpoint %d parent hash = %s, want %s", number, checkpoint.ParentHash, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreStageDrainsAfterFailure(t *testing.T) {
+	_, client := newHeaderChain(t, 0)
+	repo := newCheckpointRepo()
+	repo.failAt = 9
+	s := newPipelineService(client, repo, 5)
+
+	// Every batch is queued up front, so a failure must drain the rest for the sender to finish
+	in := make(chan *pipelineBatch)
+	go func() {
+		defer close(in)
+		for from := uint64(0); from < 30; from += 5 {
+			in <- &pipelineBatch{fromBlock: from, toBlock: from + 4, result: &ethereum.FetchResult{}, started: time.Now()}
+		}
+	}()
+
+	stopped := false
+	lastBlock, stored, err := s.storeStage(context.Background(), in, func() { stopped = true })
+	if err == nil {
+		t.Fatal("storeStage() error = nil, want the checkpoint failure")
+	}
+	if lastBlock != 4 || !stored {
+		t.Errorf("storeStage() = %d, %v, want 4, true", lastBlock, stored)
+	}
+	if !stopped {
+		t.Error("storeStage() did not stop the parse stage")
+	}
+	if want := []uint64{4}; !reflect.DeepEqual(repo.committed, want) {
+		t.Errorf("committed checkpoints = %v, want %v", repo.committed, want)
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pagrin/internal/models"
+)
+
+// Status reports the chain's head, checkpoint, lag, batch size and providers
+// The replica running ingestion reports the head seen by its last batch; other replicas query it
+// RPC failures are reported in Error so the status stays available while providers are down
+func (s *IngestionService) Status(ctx context.Context) (*models.ChainStatus, error) {
+	lastBlock, err := s.repo.GetLastProcessedBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last processed block: %w", err)
+	}
+	control, err := s.controlRepo.GetIngestionControl(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.ChainStatus{
+		ChainID:            s.repo.ChainID(),
+		Ingesting:          s.running.Load(),
+		Paused:             control != nil && control.Paused,
+		LastProcessedBlock: lastBlock,
+		BatchSize:          s.batchSize(),
+		AdaptiveBatch:      s.adaptiveBatch,
+		BatchStrategy:      s.BatchStrategy(),
+		Providers:          make([]models.ProviderStatus, 0),
+	}
+	if pool := s.ethereumClient.GetPool(); pool != nil {
+		status.Providers = pool.Status()
+	}
+
+	s.mu.Lock()
+	head := s.headBlock
+	mode := s.mode
+	checkpoint, checkpointTime := s.lastBlock, s.lastBlockTime
+	s.mu.Unlock()
+
+	if status.Ingesting {
+		status.Mode = mode
+	}
+	if !status.Ingesting || head == 0 {
+		if head, err = s.indexingHead(ctx); err != nil {
+			status.Error = fmt.Sprintf("failed to get indexing head: %v", err)
+			return status, nil
+		}
+	}
+	status.HeadBlock = head
+	if head > lastBlock {
+		status.LagBlocks = head - lastBlock
+	}
+
+	if lastBlock == 0 {
+		return status, nil
+	}
+	// The checkpoint may have been moved by another replica since this one last did
+	if checkpoint != lastBlock || checkpointTime.IsZero() {
+		header, err := s.ethereumClient.GetBlockHeader(ctx, lastBlock)
+		if err != nil {
+			status.Error = fmt.Sprintf("failed to get header of block %d: %v", lastBlock, err)
+			return status, nil
+		}
+		checkpointTime = time.Unix(int64(header.Time), 0)
+	}
+	status.LagSeconds = time.Since(checkpointTime).Seconds()
+
+	return status, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pagrin/internal/models"
+)
+
+func TestIngestionStatus(t *testing.T) {
+	_, client := newHeaderChain(t, 200)
+	_, down := newFakeChain(t) // No heads, so every head lookup fails
+
+	t.Run("follower queries the head", func(t *testing.T) {
+		s := newPipelineService(client, nil, 25)
+		s.repo = &auditRepo{lastBlock: 150}
+		s.controlRepo = &controlStore{control: &models.IngestionControl{Paused: true}}
+
+		status, err := s.Status(context.Background())
+		if err != nil {
+			t.Fatalf("Status() error = %v", err)
+		}
+		if status.Ingesting || !status.Paused || status.Mode != "" {
+			t.Errorf("Status() = ingesting %v, paused %v, mode %q, want a paused follower", status.Ingesting, status.Paused, status.Mode)
+		}
+		if status.HeadBlock != 200 || status.LagBlocks != 50 || status.BatchSize != 25 {
+			t.Errorf("Status() = head %d, lag %d, batch %d, want 200, 50, 25", status.HeadBlock, status.LagBlocks, status.BatchSize)
+		}
+		// The checkpoint's age comes from its block header
+		wantLag := time.Since(time.Unix(1_700_000_150, 0)).Seconds()
+		if status.LagSeconds < wantLag-5 || status.LagSeconds > wantLag+5 {
+			t.Errorf("Status().LagSeconds = %v, want about %v", status.LagSeconds, wantLag)
+		}
+		if status.Error != "" {
+			t.Errorf("Status().Error = %q, want none", status.Error)
+		}
+	})
+
+	t.Run("leader reports its last batch", func(t *testing.T) {
+		s := newPipelineService(down, nil, 25)
+		s.repo = &auditRepo{lastBlock: 150}
+		s.controlRepo = &controlStore{}
+		s.running.Store(true)
+		s.headBlock, s.mode = 180, IngestionModeCatchingUp
+		s.lastBlock, s.lastBlockTime = 150, time.Now().Add(-30*time.Second)
+
+		status, err := s.Status(context.Background())
+		if err != nil {
+			t.Fatalf("Status() error = %v", err)
+		}
+		if !status.Ingesting || status.Paused || status.Mode != IngestionModeCatchingUp {
+			t.Errorf("Status() = ingesting %v, paused %v, mode %q, want an ingesting leader", status.Ingesting, status.Paused, status.Mode)
+		}
+		if status.HeadBlock != 180 || status.LagBlocks != 30 {
+			t.Errorf("Status() = head %d, lag %d, want 180, 30 without querying the provider", status.HeadBlock, status.LagBlocks)
+		}
+		if status.LagSeconds < 29 || status.LagSeconds > 35 {
+			t.Errorf("Status().LagSeconds = %v, want about 30", status.LagSeconds)
+		}
+		if status.Error != "" {
+			t.Errorf("Status().Error = %q, want none", status.Error)
+		}
+	})
+
+	t.Run("provider down", func(t *testing.T) {
+		s := newPipelineService(down, nil, 25)
+		s.repo = &auditRepo{lastBlock: 150}
+		s.controlRepo = &controlStore{}
+
+		status, err := s.Status(context.Background())
+		if err != nil {
+			t.Fatalf("Status() error = %v, want the failure reported in the status", err)
+		}
+		if status.Error == "" || status.LastProcessedBlock != 150 {
+			t.Errorf("Status() = %+v, want the checkpoint with an error", status)
+		}
+	})
+
+	t.Run("nothing ingested", func(t *testing.T) {
+		s := newPipelineService(client, nil, 25)
+		s.repo = &auditRepo{}
+		s.controlRepo = &controlStore{}
+
+		status, err := s.Status(context.Background())
+		if err != nil {
+			t.Fatalf("Status() error = %v", err)
+		}
+		if status.LagBlocks != 200 || status.LagSeconds != 0 {
+			t.Errorf("Status() = lag %d blocks, %v seconds, want 200 blocks and no age", status.LagBlocks, status.LagSeconds)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+)
+
+// ErrSupplyNotFound is returned when no mint or burn of a token has been indexed
+var ErrSupplyNotFound = fmt.Errorf("token supply not found")
+
+// SupplyService serves ERC-20 circulating supply derived from indexed mints and burns
+type SupplyService struct {
+	supplyRepo repository.SupplyRepository
+	metadata   *TokenMetadataService
+	logger     *logger.Logger
+}
+
+func NewSupplyService(supplyRepo repository.SupplyRepository, metadata *TokenMetadataService, logger *logger.Logger) *SupplyService {
+	return &SupplyService{
+		supplyRepo: supplyRepo,
+		metadata:   metadata,
+		logger:     logger,
+	}
+}
+
+// ClassifyLegacyTransfers classifies transfers stored before mint/burn tracking and builds their supply
+// Must run before ingestion starts; a no-op once every transfer has a kind
+func (s *SupplyService) ClassifyLegacyTransfers(ctx context.Context) error {
+	classified, err := s.supplyRepo.ClassifyLegacyTransfers(ctx)
+	if err != nil {
+		return err
+	}
+	if classified == 0 {
+		return nil
+	}
+	s.logger.Info("Classified %d existing transfers as mint, burn or transfer", classified)
+
+	tokens, err := s.supplyRepo.RebuildSupply(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild token supply: %w", err)
+	}
+	s.logger.Info("Rebuilt supply history of %d tokens", tokens)
+
+	return nil
+}
+
+// GetSupply returns a token's current supply and a page of its supply history
+func (s *SupplyService) GetSupply(ctx context.Context, params models.SupplyQueryParams) (*models.SupplyResponse, error) {
+	token, err := normalizeAddress(params.Token)
+	if err != nil {
+		return nil, err
+	}
+	params.Token = token
+
+	if params.Limit <= 0 {
+		params.Limit = 100
+	}
+	if params.Limit > 1000 {
+		params.Limit = 1000
+	}
+
+	supply, err := s.supplyRepo.GetTokenSupply(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token supply: %w", err)
+	}
+	if supply == nil {
+		return nil, ErrSupplyNotFound
+	}
+
+	history, total, err := s.supplyRepo.GetSupplyHistory(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get supply history: %w", err)
+	}
+
+	response := &models.SupplyResponse{
+		ChainID:   supply.ChainID,
+		Token:     token,
+		Supply:    supply.Supply,
+		LastBlock: supply.LastBlock,
+		History:   history,
+		Total:     total,
+		Limit:     params.Limit,
+		Offset:    params.Offset,
+	}
+
+	decimals := ethereum.DefaultTokenDecimals
+	if metadata, err := s.metadata.Get(ctx, token); err != nil {
+		s.logger.Warn("Failed to add token metadata to supply: %v", err)
+	} else if metadata != nil {
+		response.Symbol = metadata.Symbol
+		response.Decimals = &metadata.Decimals
+		decimals = metadata.Decimals
+	}
+	if value, ok := new(big.Int).SetString(supply.Supply, 10); ok {
+		response.SupplyDecimal = ethereum.ScaleValue(value, decimals)
+	}
+
+	return response, nil
+}
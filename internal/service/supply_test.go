@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+)
+
+// supplyRepo serves a fixed current supply and records the history query
+type supplyRepo struct {
+	repository.SupplyRepository
+	supply *models.TokenSupply
+	params models.SupplyQueryParams
+}
+
+func (r *supplyRepo) GetTokenSupply(ctx context.Context, token string) (*models.TokenSupply, error) {
+	return r.supply, nil
+}
+
+func (r *supplyRepo) GetSupplyHistory(ctx context.Context, params models.SupplyQueryParams) ([]*models.SupplyChange, int64, error) {
+	r.params = params
+	return []*models.SupplyChange{}, 0, nil
+}
+
+func TestGetSupply(t *testing.T) {
+	log := logger.New("error", false, "", "text")
+	metadata := NewTokenMetadataService(nil, &metadataRepo{stored: map[string]*models.TokenMetadata{
+		testUSDC: {Address: testUSDC, Symbol: "USDC", Decimals: 6},
+	}}, nil, log, 16)
+
+	tests := []struct {
+		name        string
+		token       string
+		supply      *models.TokenSupply
+		limit       int
+		wantErr     error
+		wantLimit   int
+		wantDecimal float64
+	}{
+		{
+			name:        "scaled by token decimals",
+			token:       "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+			supply:      &models.TokenSupply{Token: testUSDC, Supply: "2500000", LastBlock: 9},
+			wantLimit:   100,
+			wantDecimal: 2.5,
+		},
+		{
+			name:        "limit capped",
+			token:       testUSDC,
+			supply:      &models.TokenSupply{Token: testUSDC, Supply: "0"},
+			limit:       5000,
+			wantLimit:   1000,
+			wantDecimal: 0,
+		},
+		{
+			name:    "no mints or burns",
+			token:   testUSDC,
+			wantErr: ErrSupplyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &supplyRepo{supply: tt.supply}
+			service := NewSupplyService(repo, metadata, log)
+
+			response, err := service.GetSupply(context.Background(), models.SupplyQueryParams{Token: tt.token, Limit: tt.limit})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetSupply() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if repo.params.Token != testUSDC || repo.params.Limit != tt.wantLimit {
+				t.Errorf("history queried for %s with limit %d, want %s with %d", repo.params.Token, repo.params.Limit, testUSDC, tt.wantLimit)
+			}
+			if response.SupplyDecimal != tt.wantDecimal || response.Symbol != "USDC" {
+				t.Errorf("supply = %v %s, want %v USDC", response.SupplyDecimal, response.Symbol, tt.wantDecimal)
+			}
+		})
+	}
+
+	if _, err := NewSupplyService(&supplyRepo{}, metadata, log).GetSupply(context.Background(), models.SupplyQueryParams{Token: "usdc"}); err == nil {
+		t.Errorf("GetSupply() of an invalid address succeeded")
+	}
+}
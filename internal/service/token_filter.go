@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+)
+
+// TokenFilterService reconciles token allow/deny list changes with already indexed history
+// Tokens that became indexable below the live checkpoint are backfilled instead of requiring a reset
+type TokenFilterService struct {
+	repo       repository.Repository
+	filterRepo repository.TokenFilterRepository
+	backfill   *BackfillService
+	logger     *logger.Logger
+	allow      []ethereum.TokenRule
+	deny       []ethereum.TokenRule
+}
+
+func NewTokenFilterService(
+	repo repository.Repository,
+	filterRepo repository.TokenFilterRepository,
+	backfill *BackfillService,
+	logger *logger.Logger,
+	allow []ethereum.TokenRule,
+	deny []ethereum.TokenRule,
+) *TokenFilterService {
+	return &TokenFilterService{
+		repo:       repo,
+		filterRepo: filterRepo,
+		backfill:   backfill,
+		logger:     logger,
+		allow:      allow,
+		deny:       deny,
+	}
+}
+
+// Sync compares the configured lists with the ones stored by the previous run and
+// creates token-restricted backfill jobs for blocks that were skipped under the old lists
+// Must run before ingestion starts so the checkpoint marks the end of the old configuration
+func (s *TokenFilterService) Sync(ctx context.Context) error {
+	current := &models.TokenFilterState{
+		Allow: toFilterRules(s.allow),
+		Deny:  toFilterRules(s.deny),
+	}
+
+	previous, err := s.filterRepo.GetTokenFilterState(ctx)
+	if err != nil {
+		return err
+	}
+
+	lastBlock, err := s.repo.GetLastProcessedBlock(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get last processed block: %w", err)
+	}
+
+	// Without a stored configuration or checkpoint nothing was skipped - just record the current lists
+	if previous != nil && lastBlock > 0 {
+		if len(previous.Allow) > 0 && len(current.Allow) == 0 {
+			s.logger.Warn("Token allowlist removed: transfers of previously excluded tokens below block %d are not backfilled automatically", lastBlock)
+		}
+
+		for rng, tokens := range tokenBackfillRanges(previous, current, lastBlock) {
+			job, err := s.backfill.CreateJob(ctx, models.CreateBackfillRequest{
+				FromBlock: rng.FromBlock,
+				ToBlock:   rng.ToBlock,
+				Tokens:    tokens,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to backfill tokens for blocks %d-%d: %w", rng.FromBlock, rng.ToBlock, err)
+			}
+			s.logger.Info("Token filter changed: backfill %s for %d tokens over blocks %d-%d", job.ID.Hex(), len(tokens), rng.FromBlock, rng.ToBlock)
+		}
+	}
+
+	return s.filterRepo.SaveTokenFilterState(ctx, current)
+}
+
+// tokenBackfillRanges returns, grouped by block range, the tokens whose transfers up to lastBlock
+// were skipped under the previous lists but are indexed under the current ones
+func tokenBackfillRanges(previous, current *models.TokenFilterState, lastBlock uint64) map[models.BlockRange][]string {
+	prevAllow := filterRuleMap(previous.Allow)
+	curAllow := filterRuleMap(current.Allow)
+	prevDeny := filterRuleMap(previous.Deny)
+	curDeny := filterRuleMap(current.Deny)
+
+	ranges := make(map[models.BlockRange][]string)
+	add := func(token string, from, to uint64) {
+		// Tokens outside the current allowlist stay unindexed
+		if len(curAllow) > 0 {
+			start, allowed := curAllow[token]
+			if !allowed {
+				return
+			}
+			if from < start {
+				from = start
+			}
+		}
+		if to > lastBlock {
+			to = lastBlock
+		}
+		if from > to {
+			return
+		}
+		rng := models.BlockRange{FromBlock: from, ToBlock: to}
+		ranges[rng] = append(ranges[rng], token)
+	}
+
+	// Allowlist additions only matter if the previous run was restricted to an allowlist
+	if len(prevAllow) > 0 {
+		for token, start := range curAllow {
+			oldStart, existed := prevAllow[token]
+			switch {
+			case !existed:
+				add(token, start, lastBlock)
+			case start < oldStart:
+				add(token, start, oldStart-1)
+			}
+		}
+	}
+
+	for token, oldStart := range prevDeny {
+		newStart, denied := curDeny[token]
+		switch {
+		case !denied:
+			add(token, oldStart, lastBlock)
+		case newStart > oldStart:
+			add(token, oldStart, newStart-1)
+		}
+	}
+
+	for rng := range ranges {
+		sort.Strings(ranges[rng])
+	}
+	return ranges
+}
+
+func toFilterRules(rules []ethereum.TokenRule) []models.TokenFilterRule {
+	result := make([]models.TokenFilterRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, models.TokenFilterRule{
+			Address:    strings.ToLower(rule.Address.Hex()),
+			StartBlock: rule.StartBlock,
+		})
+	}
+	return result
+}
+
+func filterRuleMap(rules []models.TokenFilterRule) map[string]uint64 {
+	result := make(map[string]uint64, len(rules))
+	for _, rule := range rules {
+		result[rule.Address] = rule.StartBlock
+	}
+	return result
+}
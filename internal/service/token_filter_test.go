@@ -0,0 +1,91 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"pagrin/internal/models"
+)
+
+func TestTokenBackfillRanges(t *testing.T) {
+	const (
+		usdc = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+		weth = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+		dai  = "0x6b175474e89094c44da98b954eedeac495271d0f"
+	)
+	rules := func(entries ...models.TokenFilterRule) []models.TokenFilterRule { return entries }
+	rule := func(address string, start uint64) models.TokenFilterRule {
+		return models.TokenFilterRule{Address: address, StartBlock: start}
+	}
+
+	tests := []struct {
+		name     string
+		previous models.TokenFilterState
+		current  models.TokenFilterState
+		want     map[models.BlockRange][]string
+	}{
+		{
+			name:     "unchanged lists",
+			previous: models.TokenFilterState{Allow: rules(rule(usdc, 0))},
+			current:  models.TokenFilterState{Allow: rules(rule(usdc, 0))},
+			want:     map[models.BlockRange][]string{},
+		},
+		{
+			name:     "token added to the allowlist",
+			previous: models.TokenFilterState{Allow: rules(rule(usdc, 0))},
+			current:  models.TokenFilterState{Allow: rules(rule(usdc, 0), rule(weth, 100), rule(dai, 100))},
+			want:     map[models.BlockRange][]string{{FromBlock: 100, ToBlock: 1000}: {dai, weth}},
+		},
+		{
+			name:     "allow start moved earlier",
+			previous: models.TokenFilterState{Allow: rules(rule(usdc, 500))},
+			current:  models.TokenFilterState{Allow: rules(rule(usdc, 200))},
+			want:     map[models.BlockRange][]string{{FromBlock: 200, ToBlock: 499}: {usdc}},
+		},
+		{
+			name:     "allowlist introduced after indexing everything",
+			previous: models.TokenFilterState{},
+			current:  models.TokenFilterState{Allow: rules(rule(usdc, 0))},
+			want:     map[models.BlockRange][]string{},
+		},
+		{
+			name:     "token removed from the denylist",
+			previous: models.TokenFilterState{Deny: rules(rule(dai, 300))},
+			current:  models.TokenFilterState{},
+			want:     map[models.BlockRange][]string{{FromBlock: 300, ToBlock: 1000}: {dai}},
+		},
+		{
+			name:     "deny start moved later",
+			previous: models.TokenFilterState{Deny: rules(rule(dai, 300))},
+			current:  models.TokenFilterState{Deny: rules(rule(dai, 600))},
+			want:     map[models.BlockRange][]string{{FromBlock: 300, ToBlock: 599}: {dai}},
+		},
+		{
+			name:     "undenied token outside the current allowlist",
+			previous: models.TokenFilterState{Allow: rules(rule(usdc, 0)), Deny: rules(rule(dai, 0))},
+			current:  models.TokenFilterState{Allow: rules(rule(usdc, 0))},
+			want:     map[models.BlockRange][]string{},
+		},
+		{
+			name:     "undenied token clipped to its allow start",
+			previous: models.TokenFilterState{Allow: rules(rule(dai, 400)), Deny: rules(rule(dai, 0))},
+			current:  models.TokenFilterState{Allow: rules(rule(dai, 400))},
+			want:     map[models.BlockRange][]string{{FromBlock: 400, ToBlock: 1000}: {dai}},
+		},
+		{
+			name:     "change above the last indexed block",
+			previous: models.TokenFilterState{Allow: rules(rule(usdc, 0))},
+			current:  models.TokenFilterState{Allow: rules(rule(usdc, 0), rule(weth, 2000))},
+			want:     map[models.BlockRange][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tokenBackfillRanges(&tt.previous, &tt.current, 1000)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenBackfillRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/models"
+	"pagrin/internal/repository"
+	"pagrin/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenMetadataCache is the optional Redis tier of the metadata resolver
+type TokenMetadataCache interface {
+	GetTokenMetadata(ctx context.Context, token string) (*models.TokenMetadata, error)
+	SetTokenMetadata(ctx context.Context, metadata *models.TokenMetadata) error
+}
+
+// metadataFetchConcurrency bounds the tokens whose metadata is read from the chain at once
+const metadataFetchConcurrency = 8
+
+// TokenMetadataService resolves token decimals, symbol and name
+// Lookups go through a size-bounded memory LRU, Redis, MongoDB and finally eth_call;
+// resolved metadata is written back to every tier
+type TokenMetadataService struct {
+	client *ethereum.Client
+	repo   repository.TokenMetadataRepository
+	cache  TokenMetadataCache // Optional, nil without Redis
+	logger *logger.Logger
+	native *models.TokenMetadata // Never evicted
+
+	mu     sync.Mutex
+	memory map[string]*list.Element
+	order  *list.List // Most recently used at the front
+	size   int
+}
+
+// NewTokenMetadataService creates a resolver keeping at most cacheSize tokens in memory
+func NewTokenMetadataService(
+	client *ethereum.Client,
+	repo repository.TokenMetadataRepository,
+	cache TokenMetadataCache,
+	logger *logger.Logger,
+	cacheSize int,
+) *TokenMetadataService {
+	return &TokenMetadataService{
+		client: client,
+		repo:   repo,
+		cache:  cache,
+		logger: logger,
+		native: ethereum.NativeTokenMetadata(),
+		memory: make(map[string]*list.Element, cacheSize),
+		order:  list.New(),
+		size:   cacheSize,
+	}
+}
+
+// Get resolves the metadata of a single token address
+func (s *TokenMetadataService) Get(ctx context.Context, token string) (*models.TokenMetadata, error) {
+	token, err := normalizeAddress(token)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, failed, err := s.resolve(ctx, []string{token})
+	if err != nil {
+		return nil, err
+	}
+	if err := failed[token]; err != nil {
+		return nil, err
+	}
+	return resolved[token], nil
+}
+
+// Resolve returns the metadata of every given lowercase token address
+// Tokens whose RPC calls failed are logged and left nil, so one unreachable contract does not fail
+// the batch; they are looked up again the next time they are seen. Fails only on storage errors
+func (s *TokenMetadataService) Resolve(ctx context.Context, tokens []string) (map[string]*models.TokenMetadata, error) {
+	resolved, failed, err := s.resolve(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+	for token, err := range failed {
+		s.logger.Warn("Token %s left without metadata: %v", token, err)
+	}
+	return resolved, nil
+}
+
+// resolve looks tokens up tier by tier, returning the RPC error of every token that could not be fetched
+func (s *TokenMetadataService) resolve(ctx context.Context, tokens []string) (map[string]*models.TokenMetadata, map[string]error, error) {
+	resolved := make(map[string]*models.TokenMetadata, len(tokens))
+	missing := make([]string, 0)
+
+	s.mu.Lock()
+	for _, token := range tokens {
+		if metadata, ok := s.lookupLocked(token); ok {
+			resolved[token] = metadata
+		} else if _, seen := resolved[token]; !seen {
+			resolved[token] = nil
+			missing = append(missing, token)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(missing) == 0 {
+		return resolved, nil, nil
+	}
+
+	// Redis tier (best effort)
+	if s.cache != nil {
+		remaining := missing[:0]
+		for _, token := range missing {
+			if metadata, err := s.cache.GetTokenMetadata(ctx, token); err == nil && metadata != nil {
+				s.remember(metadata)
+				resolved[token] = metadata
+			} else {
+				remaining = append(remaining, token)
+			}
+		}
+		missing = remaining
+	}
+
+	// MongoDB tier
+	if len(missing) > 0 {
+		stored, err := s.repo.GetTokenMetadata(ctx, missing)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, metadata := range stored {
+			s.remember(metadata)
+			s.cacheMetadata(ctx, metadata)
+			resolved[metadata.Address] = metadata
+		}
+	}
+
+	// Chain, a few tokens at a time; results are merged once every fetch is done
+	toFetch := make([]string, 0, len(missing))
+	for _, token := range missing {
+		if resolved[token] == nil {
+			toFetch = append(toFetch, token)
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var storeErr error
+	fetched := make(map[string]*models.TokenMetadata, len(toFetch))
+	failed := make(map[string]error)
+	slots := make(chan struct{}, metadataFetchConcurrency)
+	for _, token := range toFetch {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(token string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			metadata, rpcErr, err := s.fetch(ctx, token)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				storeErr = err
+			case rpcErr != nil:
+				failed[token] = rpcErr
+			default:
+				fetched[token] = metadata
+			}
+		}(token)
+	}
+	wg.Wait()
+	if storeErr != nil {
+		return nil, nil, storeErr
+	}
+	for token, metadata := range fetched {
+		resolved[token] = metadata
+	}
+
+	return resolved, failed, nil
+}
+
+// fetch reads a token's metadata from the chain and stores it in every tier
+// RPC failures are returned as rpcErr, storage failures as err
+// Transfers indexed before the token was known are rescaled when its decimals differ from the default
+func (s *TokenMetadataService) fetch(ctx context.Context, token string) (metadata *models.TokenMetadata, rpcErr, err error) {
+	metadata, rpcErr = s.client.GetTokenMetadata(ctx, common.HexToAddress(token))
+	if rpcErr != nil {
+		return nil, fmt.Errorf("failed to resolve metadata for token %s: %w", token, rpcErr), nil
+	}
+
+	if err := s.repo.SaveTokenMetadata(ctx, metadata); err != nil {
+		return nil, nil, err
+	}
+
+	if metadata.Decimals != ethereum.DefaultTokenDecimals {
+		rescaled, err := s.repo.RescaleTransferValues(ctx, token, metadata.Decimals)
+		if err != nil {
+			// The stored metadata is still correct; aggregates don't depend on value_decimal
+			s.logger.Warn("Failed to rescale existing transfers of token %s: %v", token, err)
+		} else if rescaled > 0 {
+			s.logger.Info("Rescaled %d existing transfers of token %s to %d decimals", rescaled, token, metadata.Decimals)
+		}
+	}
+
+	s.remember(metadata)
+	s.cacheMetadata(ctx, metadata)
+	return metadata, nil, nil
+}
+
+// lookupLocked returns a token's metadata from memory and marks it most recently used
+func (s *TokenMetadataService) lookupLocked(token string) (*models.TokenMetadata, bool) {
+	if token == s.native.Address {
+		return s.native, true
+	}
+	element, ok := s.memory[token]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(element)
+	return element.Value.(*models.TokenMetadata), true
+}
+
+// remember stores metadata in memory, evicting the least recently used token when full
+func (s *TokenMetadataService) remember(metadata *models.TokenMetadata) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.memory[metadata.Address]; ok {
+		element.Value = metadata
+		s.order.MoveToFront(element)
+		return
+	}
+	s.memory[metadata.Address] = s.order.PushFront(metadata)
+	for s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.memory, oldest.Value.(*models.TokenMetadata).Address)
+	}
+}
+
+func (s *TokenMetadataService) cacheMetadata(ctx context.Context, metadata *models.TokenMetadata) {
+	if s.cache != nil {
+		_ = s.cache.SetTokenMetadata(ctx, metadata)
+	}
+}
+
+// ApplyToTransfers scales value_decimal of ERC-20 transfers by their token's decimals
+// and fills in symbol and decimals for every transfer except ERC-1155 ones
+func (s *TokenMetadataService) ApplyToTransfers(ctx context.Context, transfers []*models.Transfer) error {
+	if len(transfers) == 0 {
+		return nil
+	}
+
+	tokens := make([]string, 0)
+	for _, transfer := range transfers {
+		if transfer.Standard != models.StandardERC1155 {
+			tokens = append(tokens, transfer.Token)
+		}
+	}
+
+	resolved, err := s.Resolve(ctx, tokens)
+	if err != nil {
+		return err
+	}
+
+	for _, transfer := range transfers {
+		metadata := resolved[transfer.Token]
+		if metadata == nil || transfer.Standard == models.StandardERC1155 {
+			continue
+		}
+		transfer.Symbol = metadata.Symbol
+		decimals := metadata.Decimals
+		transfer.Decimals = &decimals
+
+		if transfer.Standard == models.StandardNative {
+			continue
+		}
+		raw := transfer.ValueString
+		if raw == "" {
+			raw = transfer.Value.String()
+		}
+		if value, ok := new(big.Int).SetString(raw, 10); ok {
+			transfer.ValueDecimal = ethereum.ScaleValue(value, decimals)
+		}
+	}
+
+	return nil
+}
+
+// ApplyToAllowances fills in symbol and decimals of each allowance's token
+func (s *TokenMetadataService) ApplyToAllowances(ctx context.Context, allowances []*models.Allowance) error {
+	tokens := make([]string, 0, len(allowances))
+	for _, allowance := range allowances {
+		tokens = append(tokens, allowance.Token)
+	}
+
+	resolved, err := s.Resolve(ctx, tokens)
+	if err != nil {
+		return err
+	}
+
+	for _, allowance := range allowances {
+		if metadata := resolved[allowance.Token]; metadata != nil {
+			allowance.Symbol = metadata.Symbol
+			decimals := metadata.Decimals
+			allowance.Decimals = &decimals
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"pagrin/internal/ethereum"
+	"pagrin/internal/models"
+	"pagrin/pkg/logger"
+)
+
+// tokenCache is an in-memory stand-in for the Redis metadata tier
+type tokenCache struct {
+	mu     sync.Mutex
+	stored map[string]*models.TokenMetadata
+	sets   int
+}
+
+func (c *tokenCache) GetTokenMetadata(ctx context.Context, token string) (*models.TokenMetadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stored[token], nil
+}
+
+func (c *tokenCache) SetTokenMetadata(ctx context.Context, metadata *models.TokenMetadata) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stored[metadata.Address] = metadata
+	c.sets++
+	return nil
+}
+
+func testToken(i int) string {
+	return fmt.Sprintf("0x%040x", i)
+}
+
+func TestTokenMetadataEviction(t *testing.T) {
+	service := NewTokenMetadataService(nil, &metadataRepo{}, nil, logger.New("error", false, "", "text"), 2)
+	for i := 1; i <= 2; i++ {
+		service.remember(&models.TokenMetadata{Address: testToken(i), Decimals: uint8(i)})
+	}
+
+	// Touching token 1 makes token 2 the least recently used
+	service.mu.Lock()
+	_, ok := service.lookupLocked(testToken(1))
+	service.mu.Unlock()
+	if !ok {
+		t.Fatalf("token 1 not remembered")
+	}
+	service.remember(&models.TokenMetadata{Address: testToken(3)})
+	service.remember(&models.TokenMetadata{Address: testToken(1), Decimals: 9})
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if _, ok := service.lookupLocked(testToken(2)); ok {
+		t.Errorf("token 2 still cached, want it evicted")
+	}
+	if metadata, ok := service.lookupLocked(testToken(1)); !ok || metadata.Decimals != 9 {
+		t.Errorf("token 1 = %+v (%v), want the updated entry", metadata, ok)
+	}
+	if _, ok := service.lookupLocked(testToken(3)); !ok {
+		t.Errorf("token 3 not cached")
+	}
+	if _, ok := service.lookupLocked(ethereum.NativeTokenAddress); !ok {
+		t.Errorf("native token not resolved from memory")
+	}
+	if service.order.Len() != 2 || len(service.memory) != 2 {
+		t.Errorf("cache holds %d/%d entries, want 2", service.order.Len(), len(service.memory))
+	}
+}
+
+func TestTokenMetadataResolve(t *testing.T) {
+	_, client := newFakeChain(t) // eth_call is unsupported, so chain lookups fail
+	repo := &metadataRepo{stored: map[string]*models.TokenMetadata{
+		testToken(2): {Address: testToken(2), Symbol: "MONGO", Decimals: 6},
+	}}
+	cache := &tokenCache{stored: map[string]*models.TokenMetadata{
+		testToken(1): {Address: testToken(1), Symbol: "REDIS", Decimals: 8},
+	}}
+	service := NewTokenMetadataService(client, repo, cache, logger.New("error", false, "", "text"), 16)
+	ctx := context.Background()
+
+	tokens := []string{testToken(1), testToken(2), testToken(3), testToken(2), ethereum.NativeTokenAddress}
+	resolved, err := service.Resolve(ctx, tokens)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if got := resolved[testToken(1)]; got == nil || got.Symbol != "REDIS" {
+		t.Errorf("token 1 = %+v, want it from Redis", got)
+	}
+	if got := resolved[testToken(2)]; got == nil || got.Symbol != "MONGO" {
+		t.Errorf("token 2 = %+v, want it from MongoDB", got)
+	}
+	if got, ok := resolved[testToken(3)]; !ok || got != nil {
+		t.Errorf("token 3 = %+v (%v), want a nil entry after the RPC failure", got, ok)
+	}
+	if got := resolved[ethereum.NativeTokenAddress]; got == nil || got.Symbol != "ETH" {
+		t.Errorf("native token = %+v, want ETH", got)
+	}
+	if repo.lookups != 2 {
+		t.Errorf("MongoDB looked up %d tokens, want 2", repo.lookups)
+	}
+	if cache.sets != 1 || cache.stored[testToken(2)] == nil {
+		t.Errorf("Redis written %d times, want the MongoDB hit cached once", cache.sets)
+	}
+
+	// Hits are served from memory; the failed token is looked up again
+	if _, err := service.Resolve(ctx, tokens); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if repo.lookups != 3 {
+		t.Errorf("MongoDB looked up %d tokens after the second resolve, want 3", repo.lookups)
+	}
+
+	if _, err := service.Get(ctx, testToken(3)); err == nil {
+		t.Errorf("Get() of an unreachable token succeeded, want the RPC error")
+	}
+	if _, err := service.Get(ctx, "not-an-address"); err == nil {
+		t.Errorf("Get() of an invalid address succeeded")
+	}
+}
+
+func TestApplyToTransfers(t *testing.T) {
+	repo := &metadataRepo{stored: map[string]*models.TokenMetadata{
+		testUSDC: {Address: testUSDC, Symbol: "USDC", Decimals: 6},
+	}}
+	service := NewTokenMetadataService(nil, repo, nil, logger.New("error", false, "", "text"), 16)
+
+	erc20 := &models.Transfer{Standard: models.StandardERC20, Token: testUSDC, ValueString: "2500000", ValueDecimal: 2500000}
+	native := &models.Transfer{Standard: models.StandardNative, Token: ethereum.NativeTokenAddress, ValueString: "1000000000000000000", ValueDecimal: 1}
+	erc1155 := &models.Transfer{Standard: models.StandardERC1155, Token: testUSDC, ValueString: "3", ValueDecimal: 3}
+
+	if err := service.ApplyToTransfers(context.Background(), []*models.Transfer{erc20, native, erc1155}); err != nil {
+		t.Fatalf("ApplyToTransfers() error = %v", err)
+	}
+	if erc20.ValueDecimal != 2.5 || erc20.Symbol != "USDC" || erc20.Decimals == nil || *erc20.Decimals != 6 {
+		t.Errorf("erc-20 transfer = %v %s, want 2.5 USDC with 6 decimals", erc20.ValueDecimal, erc20.Symbol)
+	}
+	if native.ValueDecimal != 1 || native.Symbol != "ETH" {
+		t.Errorf("native transfer = %v %s, want 1 ETH", native.ValueDecimal, native.Symbol)
+	}
+	if erc1155.ValueDecimal != 3 || erc1155.Symbol != "" || erc1155.Decimals != nil {
+		t.Errorf("erc-1155 transfer = %v %s, want it left unscaled", erc1155.ValueDecimal, erc1155.Symbol)
+	}
+}
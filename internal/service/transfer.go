@@ -9,14 +9,18 @@ import (
 	"pagrin/pkg/logger"
 )
 
+// TransferService queries transfers across every indexed chain
+// repo must be the unscoped root repository; metadata is resolved through each transfer's chain
 type TransferService struct {
 	repo   repository.Repository
+	chains *Chains
 	logger *logger.Logger
 }
 
-func NewTransferService(repo repository.Repository, logger *logger.Logger) *TransferService {
+func NewTransferService(repo repository.Repository, chains *Chains, logger *logger.Logger) *TransferService {
 	return &TransferService{
 		repo:   repo,
+		chains: chains,
 		logger: logger,
 	}
 }
@@ -35,6 +39,9 @@ func (s *TransferService) ProcessTransfers(ctx context.Context, transfers []*mod
 }
 
 func (s *TransferService) QueryTransfers(ctx context.Context, params models.TransferQueryParams) ([]*models.Transfer, int64, error) {
+	if params.Status != "" && !models.IsTransferStatus(params.Status) {
+		return nil, 0, fmt.Errorf("%w: status %s", ErrInvalidInput, params.Status)
+	}
 	if params.Limit <= 0 {
 		params.Limit = 100
 	}
@@ -47,14 +54,55 @@ func (s *TransferService) QueryTransfers(ctx context.Context, params models.Tran
 		return nil, 0, fmt.Errorf("failed to query transfers: %w", err)
 	}
 
+	// Metadata is presentation only here - serve the transfers even if a lookup fails
+	byChain := make(map[uint64][]*models.Transfer)
+	for _, transfer := range transfers {
+		byChain[transfer.ChainID] = append(byChain[transfer.ChainID], transfer)
+	}
+	for chainID, chainTransfers := range byChain {
+		chain := s.chains.Get(chainID)
+		if chain == nil {
+			continue // Indexed by a chain that is no longer configured
+		}
+		if err := chain.Metadata.ApplyToTransfers(ctx, chainTransfers); err != nil {
+			s.logger.Warn("Failed to add token metadata to transfers on chain %d: %v", chainID, err)
+		}
+	}
+
 	return transfers, total, nil
 }
 
 func (s *TransferService) GetAggregates(ctx context.Context, params models.TransferQueryParams) (*models.AggregateResponse, error) {
+	if params.Status != "" && !models.IsTransferStatus(params.Status) {
+		return nil, fmt.Errorf("%w: status %s", ErrInvalidInput, params.Status)
+	}
 	aggregates, err := s.repo.GetAggregates(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get aggregates: %w", err)
 	}
 
+	// The same address can be a different token on each chain, so metadata needs a single chain
+	chain := s.aggregateChain(params)
+	if params.Token != "" && chain != nil {
+		metadata, err := chain.Metadata.Get(ctx, params.Token)
+		if err != nil {
+			s.logger.Warn("Failed to add token metadata to aggregates: %v", err)
+		} else if metadata != nil {
+			aggregates.Symbol = metadata.Symbol
+			aggregates.Decimals = &metadata.Decimals
+		}
+	}
+
 	return aggregates, nil
 }
+
+// aggregateChain returns the single chain an aggregate query covers, or nil if it spans several
+func (s *TransferService) aggregateChain(params models.TransferQueryParams) *Chain {
+	if params.ChainID != nil {
+		return s.chains.Get(*params.ChainID)
+	}
+	if all := s.chains.All(); len(all) == 1 {
+		return all[0]
+	}
+	return nil
+}
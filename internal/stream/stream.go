@@ -6,14 +6,31 @@ import (
 	"sync"
 	"time"
 
+	"pagrin/internal/metrics"
 	"pagrin/internal/models"
 	"pagrin/pkg/logger"
 )
 
+// Event types sent to stream clients
+const (
+	EventTypeTransfer = "transfer" // A newly ingested transfer
+	EventTypeRemoved  = "removed"  // A transfer rolled back by a chain reorganization
+)
+
+// Event is a single message delivered to a stream client
+// Data holds the JSON-encoded transfer; Type lets SSE clients dispatch on the event name
+// Status and ChainID mirror the transfer so handlers can filter without decoding Data
+type Event struct {
+	Type    string
+	Status  string
+	ChainID uint64
+	Data    []byte
+}
+
 // Stream provides real-time event streaming to connected clients
 // Supports both WebSocket and Server-Sent Events (SSE)
 type Stream struct {
-	clients    map[chan []byte]bool
+	clients    map[chan Event]bool
 	mu         sync.RWMutex
 	buffer     []*models.Transfer
 	bufferSize int
@@ -23,7 +40,7 @@ type Stream struct {
 // NewStream creates a new stream instance
 func NewStream(bufferSize int, log *logger.Logger) *Stream {
 	return &Stream{
-		clients:    make(map[chan []byte]bool),
+		clients:    make(map[chan Event]bool),
 		buffer:     make([]*models.Transfer, 0, bufferSize),
 		bufferSize: bufferSize,
 		logger:     log,
@@ -63,10 +80,58 @@ func (s *Stream) Publish(transfer interface{}) {
 		return
 	}
 
-	// Send to all connected clients (non-blocking)
+	s.broadcast(Event{Type: EventTypeTransfer, Status: t.Status, ChainID: t.ChainID, Data: data})
+}
+
+// PublishRemoved notifies connected clients that a transfer was rolled back by a reorg
+// The transfer is also dropped from the replay buffer so new clients never see it
+// Accepts interface{} to match StreamPublisher interface
+func (s *Stream) PublishRemoved(transfer interface{}) {
+	if s == nil {
+		return
+	}
+
+	t, ok := transfer.(*models.Transfer)
+	if !ok {
+		s.logger.Error("Invalid transfer type for streaming")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.buffer[:0]
+	for _, buffered := range s.buffer {
+		if buffered.ChainID == t.ChainID && buffered.TxHash == t.TxHash && buffered.LogIndex == t.LogIndex {
+			continue
+		}
+		kept = append(kept, buffered)
+	}
+	s.buffer = kept
+
+	if len(s.clients) == 0 {
+		return
+	}
+
+	// Copy so the caller's transfer is not mutated
+	removed := *t
+	removed.Removed = true
+
+	data, err := json.Marshal(&removed)
+	if err != nil {
+		s.logger.Error("Failed to marshal removed transfer for streaming: %v", err)
+		return
+	}
+
+	s.broadcast(Event{Type: EventTypeRemoved, Status: t.Status, ChainID: t.ChainID, Data: data})
+}
+
+// broadcast sends an event to all connected clients (non-blocking)
+// Caller must hold s.mu
+func (s *Stream) broadcast(event Event) {
 	for clientChan := range s.clients {
 		select {
-		case clientChan <- data:
+		case clientChan <- event:
 			// Successfully sent
 		default:
 			// Channel full - skip this client (prevents blocking)
@@ -77,11 +142,11 @@ func (s *Stream) Publish(transfer interface{}) {
 
 // Subscribe creates a new client channel for receiving events
 // Returns channel and cleanup function
-func (s *Stream) Subscribe() (chan []byte, func()) {
+func (s *Stream) Subscribe() (chan Event, func()) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	clientChan := make(chan []byte, s.bufferSize)
+	clientChan := make(chan Event, s.bufferSize)
 
 	// Send buffered events to new client
 	go func() {
@@ -96,7 +161,7 @@ func (s *Stream) Subscribe() (chan []byte, func()) {
 				continue
 			}
 			select {
-			case clientChan <- data:
+			case clientChan <- Event{Type: EventTypeTransfer, Status: transfer.Status, ChainID: transfer.ChainID, Data: data}:
 			case <-time.After(1 * time.Second):
 				// Timeout - client may have disconnected
 				return
@@ -105,12 +170,14 @@ func (s *Stream) Subscribe() (chan []byte, func()) {
 	}()
 
 	s.clients[clientChan] = true
+	metrics.StreamClients.Set(float64(len(s.clients)))
 
 	cleanup := func() {
 		s.mu.Lock()
 		defer s.mu.Unlock()
 		delete(s.clients, clientChan)
 		close(clientChan)
+		metrics.StreamClients.Set(float64(len(s.clients)))
 	}
 
 	return clientChan, cleanup
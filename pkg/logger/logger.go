@@ -32,6 +32,7 @@ type Logger struct {
 	error      *log.Logger
 	warn       *log.Logger
 	debug      *log.Logger
+	prefix     string // Prepended to every message, e.g. "[mainnet] "
 }
 
 // LogEntry represents a structured log entry for JSON output
@@ -89,6 +90,14 @@ func New(level string, logToFile bool, logFilePath string, logFormat string) *Lo
 	return logger
 }
 
+// WithPrefix returns a logger sharing this logger's output that prepends "[prefix] " to every message
+// Used to tell apart the logs of pipelines running side by side
+func (l *Logger) WithPrefix(prefix string) *Logger {
+	child := *l
+	child.prefix = l.prefix + "[" + prefix + "] "
+	return &child
+}
+
 // parseLogLevel converts string level to LogLevel enum
 func parseLogLevel(level string) LogLevel {
 	switch level {
@@ -115,6 +124,7 @@ func (l *Logger) log(level string, levelEnum LogLevel, format string, v ...inter
 	if len(v) > 0 {
 		message = fmt.Sprintf(format, v...)
 	}
+	message = l.prefix + message
 
 	if l.jsonFormat {
 		l.logJSON(level, message, nil)
@@ -189,5 +199,5 @@ func (l *Logger) WithFields(level string, message string, fields map[string]inte
 		return
 	}
 
-	l.logJSON(level, message, fields)
+	l.logJSON(level, l.prefix+message, fields)
 }